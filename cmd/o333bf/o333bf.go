@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/guojia99/cubing-pro/src/configs"
 	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
 	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
 	"github.com/guojia99/cubing-pro/src/internel/scramble"
@@ -29,13 +30,7 @@ func r1LoadDb(ctx *Context) (err error) {
 		return err
 	}
 
-	ctx.sb = scramble.NewScramble(
-		ctx.db,
-		"rust_twisty",
-		"",
-		"",
-		"",
-	)
+	ctx.sb = scramble.NewScramble(ctx.db, configs.Scramble{Type: "rust_twisty"})
 
 	return
 }
@@ -59,7 +54,10 @@ func r2ResetAll333bfWithComp(ctx *Context) (err error) {
 					if len(s) >= 7 {
 						continue
 					}
-					sc := ctx.sb.Scramble("333bf", 2)
+					sc, err := ctx.sb.Scramble("333bf", 2)
+					if err != nil {
+						return err
+					}
 
 					comp.CompJSON.Events[idx].Schedule[j].Scrambles[k] = append(
 						comp.CompJSON.Events[idx].Schedule[j].Scrambles[k],
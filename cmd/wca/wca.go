@@ -0,0 +1,96 @@
+package wca
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	svc2 "github.com/guojia99/cubing-pro/src/internel/svc"
+	"github.com/guojia99/cubing-pro/src/wca/types"
+	"github.com/spf13/cobra"
+)
+
+func exportCmd(svc **svc2.Svc) *cobra.Command {
+	var opt types.ExportOptions
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "导出WCA数据表或统计数据为CSV/Parquet",
+		Example: "  cubing-pro wca export -d results -f parquet -o results.parquet --country CN --event 333 --year 2024\n" +
+			"  cubing-pro wca export -d success_rates --columns wca_id,event_id,solved,attempted -o rates.csv",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opt.Format == "" {
+				opt.Format = types.ExportFormatCSV
+				if strings.HasSuffix(output, ".parquet") {
+					opt.Format = types.ExportFormatParquet
+				}
+			}
+
+			// 启动日志会输出到标准输出，只支持导出到文件
+			if output == "" {
+				output = opt.Dataset + "." + opt.Format
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			count, err := (*svc).Wca.Export(f, opt)
+			if err != nil {
+				_ = f.Close()
+				_ = os.Remove(output)
+				return err
+			}
+			fmt.Printf("导出 %s %d 行到 %s\n", opt.Dataset, count, output)
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.Dataset, "dataset", "d", "", "数据集, 见 wca datasets")
+	flags.StringVarP(&opt.Format, "format", "f", "", "导出格式 csv / parquet, 默认按输出文件后缀")
+	flags.StringVarP(&output, "output", "o", "", "输出文件, 默认为 数据集.格式")
+	flags.StringSliceVar(&opt.Columns, "columns", nil, "导出的列, 逗号分隔, 默认全部")
+	flags.StringVar(&opt.Country, "country", "", "国家 ID、名称或 ISO2")
+	flags.StringVar(&opt.EventID, "event", "", "项目")
+	flags.IntVar(&opt.Year, "year", 0, "年份")
+	_ = cmd.MarkFlagRequired("dataset")
+	return cmd
+}
+
+func datasetsCmd(svc **svc2.Svc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "datasets",
+		Short: "可导出的WCA数据集",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, name := range (*svc).Wca.ExportDatasets() {
+				fmt.Println(name)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func NewCmd(svc **svc2.Svc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wca",
+		Short: "WCA数据相关",
+		// 只需要本地 WCA 数据库，不启动业务库、打乱与定时任务
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			config, err := cmd.Flags().GetString("config")
+			if err != nil {
+				return err
+			}
+			*svc, err = svc2.NewWcaSvc(config)
+			return err
+		},
+	}
+
+	cmd.AddCommand(
+		exportCmd(svc),
+		datasetsCmd(svc),
+	)
+	return cmd
+}
@@ -58,6 +58,7 @@ func NewAPI(svc *svc.Svc) *API {
 	routes.StaticRouters(group, svc)
 	routes.SportsRoutes(group, svc)
 	routes.WcaRouters(group, svc)
+	routes.ScrambleRouters(group, svc)
 
 	//group.Static("/assets", svc.Cfg.APIConfig.AssetsPath)
 	return a
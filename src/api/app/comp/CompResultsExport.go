@@ -0,0 +1,51 @@
+package comp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
+	"github.com/guojia99/cubing-pro/src/internel/export"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type ResultsExportReq struct {
+	CompReq
+	app_utils.ExportReq
+}
+
+// ResultsExport 导出比赛成绩
+func ResultsExport(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req ResultsExportReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+		if err := req.Check(); err != nil {
+			exception.ErrInvalidInput.ResponseWithError(ctx, err)
+			return
+		}
+
+		var comp competition.Competition
+		if err := svc.DB.First(&comp, "id = ?", req.CompId).Error; err != nil {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, err)
+			return
+		}
+		app_utils.ResponseExport(ctx, fmt.Sprintf("%s_成绩", comp.Name), req.Format, CompResultsTable(svc, comp))
+	}
+}
+
+// CompResultsTable 比赛所有成绩的导出表, 含名次和记录
+func CompResultsTable(svc *svc.Svc, comp competition.Competition) export.Table {
+	results := export.FlattenCompResults(svc.Cov.SelectCompsResult(comp.ID))
+
+	var records []result.Record
+	svc.DB.Where("comps_id = ?", comp.ID).Find(&records)
+
+	compDates := map[uint]time.Time{comp.ID: comp.CompStartTime}
+	return export.ResultsTable("成绩", results, compDates, export.RecordsByResult(records))
+}
@@ -0,0 +1,44 @@
+package comp
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	"github.com/guojia99/cubing-pro/src/api/middleware"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type CompSealedScramblesReq struct {
+	CompReq
+	EventID  string `uri:"eventId"`
+	RoundNum int    `uri:"roundNum"`
+}
+
+// CompSealedScrambles 选手查看已公布的密封打乱, next 为 true 时逐把公布下一把
+func CompSealedScrambles(svc *svc.Svc, next bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, err := middleware.GetAuthUser(ctx)
+		if err != nil {
+			return
+		}
+
+		var req CompSealedScramblesReq
+		if err = app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+
+		var comp competition.Competition
+		if err = svc.DB.First(&comp, "id = ?", req.CompId).Error; err != nil {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, err)
+			return
+		}
+
+		out, err := svc.Cov.ViewSealedScrambles(comp, req.EventID, req.RoundNum, user, ctx.ClientIP(), next)
+		if err != nil {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, err)
+			return
+		}
+		exception.ResponseOK(ctx, out)
+	}
+}
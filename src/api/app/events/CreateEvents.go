@@ -5,6 +5,7 @@ import (
 	"github.com/guojia99/cubing-pro/src/api/exception"
 	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
 	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/scramble"
 	"github.com/guojia99/cubing-pro/src/internel/svc"
 )
 
@@ -18,6 +19,12 @@ func CreateEvents(svc *svc.Svc) gin.HandlerFunc {
 		if err := app_utils.BindAll(ctx, &req); err != nil {
 			return
 		}
+		if req.ScrambleDef != nil {
+			if err := scramble.CheckDefinition(*req.ScrambleDef); err != nil {
+				exception.ErrInvalidInput.ResponseWithError(ctx, err)
+				return
+			}
+		}
 		if err := svc.DB.Create(&req.Event).Error; err != nil {
 			exception.ErrDatabase.ResponseWithError(ctx, err)
 			return
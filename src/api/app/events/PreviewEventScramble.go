@@ -0,0 +1,57 @@
+package events
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/scramble"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+const maxPreviewScrambles = 20
+
+type PreviewEventScrambleReq struct {
+	EventID     string                    `json:"eventId"`     // 预览已有项目的打乱
+	ScrambleDef *event.ScrambleDefinition `json:"scrambleDef"` // 预览未保存的打乱规则, 优先于 eventId
+	Num         int                       `json:"num"`         // 打乱数, 默认5条
+}
+
+// PreviewEventScramble 预览项目的打乱, 用于保存自定义打乱规则前确认效果
+func PreviewEventScramble(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req PreviewEventScrambleReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+		if req.Num <= 0 {
+			req.Num = 5
+		}
+		req.Num = min(req.Num, maxPreviewScrambles)
+
+		var ev event.Event
+		switch {
+		case req.ScrambleDef != nil:
+			if err := scramble.CheckDefinition(*req.ScrambleDef); err != nil {
+				exception.ErrInvalidInput.ResponseWithError(ctx, err)
+				return
+			}
+			ev.ScrambleDef = req.ScrambleDef
+		case req.EventID != "":
+			if err := svc.DB.First(&ev, "id = ?", req.EventID).Error; err != nil {
+				exception.ErrResourceNotFound.ResponseWithError(ctx, err)
+				return
+			}
+		default:
+			exception.ErrInvalidInput.ResponseWithError(ctx, "需要项目ID或打乱规则")
+			return
+		}
+
+		scrambles, err := svc.Scramble.ScrambleWithEvent(ev, req.Num)
+		if err != nil {
+			exception.ErrInternalServer.ResponseWithError(ctx, err)
+			return
+		}
+		exception.ResponseOK(ctx, scrambles)
+	}
+}
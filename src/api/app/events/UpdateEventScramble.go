@@ -0,0 +1,43 @@
+package events
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/scramble"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type UpdateEventScrambleReq struct {
+	Id          string                    `uri:"id"`
+	ScrambleDef *event.ScrambleDefinition `json:"scrambleDef"` // 为空时清除自定义打乱规则
+}
+
+// UpdateEventScramble 修改项目的自定义打乱规则
+func UpdateEventScramble(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req UpdateEventScrambleReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+		if req.ScrambleDef != nil {
+			if err := scramble.CheckDefinition(*req.ScrambleDef); err != nil {
+				exception.ErrInvalidInput.ResponseWithError(ctx, err)
+				return
+			}
+		}
+
+		var ev event.Event
+		if err := svc.DB.First(&ev, "id = ?", req.Id).Error; err != nil {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, err)
+			return
+		}
+		ev.ScrambleDef = req.ScrambleDef
+		if err := svc.DB.Save(&ev).Error; err != nil {
+			exception.ErrDatabase.ResponseWithError(ctx, err)
+			return
+		}
+		exception.ResponseOK(ctx, ev)
+	}
+}
@@ -41,38 +41,22 @@ func ApprovalCompPlayerPreResult(svc *svc.Svc) gin.HandlerFunc {
 			exception.ErrResultCreate.ResponseWithError(ctx, "不在比赛时间")
 			return
 		}
+		if pre.CompetitionID != comp.ID {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, "该成绩不属于本场比赛")
+			return
+		}
 		if pre.Finish {
 			exception.ErrResultCreate.ResponseWithError(ctx, "成绩已被处理，请不要反复处理")
 			return
 		}
 
-		pre.Processor = user.Name
-		pre.ProcessorID = user.ID
-		pre.Detail = req.Detail
-		if req.Detail == result.DetailOk || req.Detail == result.DetailNot {
-			pre.FinishDetail = req.Detail
-			pre.Finish = true
+		if err = svc.Cov.ApprovalPreResult(comp, &pre, req.Detail, user); err != nil {
+			exception.ErrResultCreate.ResponseWithError(ctx, err)
+			return
 		}
-
-		if req.Detail == result.DetailOk {
-			// 判断选手是否已经审核完成
-			res, err := checkAndAddPlayerResult(
-				ctx, svc, AddCompResultReq{
-					CompReq:  CompReq{CompId: comp.ID},
-					Results:  pre.Result,
-					CubeID:   pre.CubeID,
-					RoundNum: pre.RoundNumber,
-					EventID:  pre.EventID,
-					Penalty:  pre.Penalty,
-				},
-			)
-			if err != nil {
-				exception.ErrResultCreate.ResponseWithError(ctx, err)
-				return
-			}
-			pre.ResultID = &res.ID
+		if pre.Finish {
+			go svc.Cov.NotifyPreResultSubmitters(comp, []result.PreResults{pre})
 		}
-		svc.DB.Save(&pre)
 		exception.ResponseOK(ctx, nil)
 	}
 }
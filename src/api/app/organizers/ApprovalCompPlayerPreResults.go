@@ -0,0 +1,52 @@
+package organizers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/app/organizers/org_mid"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	"github.com/guojia99/cubing-pro/src/api/middleware"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type ApprovalCompPlayerPreResultsReq struct {
+	CompReq
+
+	ResultIDs []uint `json:"ResultIDs"`
+	Detail    string `json:"FinishDetail"` // ok / not
+}
+
+type ApprovalCompPlayerPreResultsResp struct {
+	Num int `json:"Num"`
+}
+
+// ApprovalCompPlayerPreResults 批量审批预录入成绩, 全部成功或全部失败
+func ApprovalCompPlayerPreResults(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req ApprovalCompPlayerPreResultsReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+
+		user, err := middleware.GetAuthUser(ctx)
+		if err != nil {
+			return
+		}
+
+		comp := ctx.Value(org_mid.CompMiddlewareKey).(competition.Competition)
+		if !comp.IsRunningTime() {
+			exception.ErrResultCreate.ResponseWithError(ctx, "不在比赛时间")
+			return
+		}
+
+		pres, err := svc.Cov.ApprovalPreResults(comp, req.ResultIDs, req.Detail, user)
+		if err != nil {
+			exception.ErrResultCreate.ResponseWithError(ctx, err)
+			return
+		}
+
+		go svc.Cov.NotifyPreResultSubmitters(comp, pres)
+		exception.ResponseOK(ctx, ApprovalCompPlayerPreResultsResp{Num: len(pres)})
+	}
+}
@@ -86,8 +86,14 @@ func CreateComp(svc *svc.Svc) gin.HandlerFunc {
 			exception.ErrResultCreate.ResponseWithError(ctx, err)
 			return
 		}
-		
-		if err = svc.DB.Create(&comps).Error; err != nil {
+
+		// 密封打乱, 轮次开启后才公布; 密封与创建比赛在同一事务中
+		if comps.CompJSON.ScrambleSeal.Enable {
+			err = svc.Cov.SealCompScrambles(&comps)
+		} else {
+			err = svc.DB.Create(&comps).Error
+		}
+		if err != nil {
 			exception.ErrResultCreate.ResponseWithError(ctx, err)
 			return
 		}
@@ -0,0 +1,59 @@
+package organizers
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/app/comp"
+	"github.com/guojia99/cubing-pro/src/api/app/organizers/org_mid"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/user"
+	"github.com/guojia99/cubing-pro/src/internel/export"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type ExportCompResultsReq struct {
+	CompReq
+	app_utils.ExportReq
+}
+
+// ExportCompResults 主办导出比赛成绩, 附带报名信息
+func ExportCompResults(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req ExportCompResultsReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+		if err := req.Check(); err != nil {
+			exception.ErrInvalidInput.ResponseWithError(ctx, err)
+			return
+		}
+		cp := ctx.Value(org_mid.CompMiddlewareKey).(competition.Competition)
+
+		var regs []competition.Registration
+		if err := svc.DB.Find(&regs, "comp_id = ?", cp.ID).Error; err != nil {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, err)
+			return
+		}
+		var userIds []uint
+		for _, reg := range regs {
+			userIds = append(userIds, reg.UserID)
+		}
+		var users []user.User
+		if len(userIds) > 0 {
+			svc.DB.Where("id in ?", userIds).Find(&users)
+		}
+		var userMap = make(map[uint]user.User, len(users))
+		for _, u := range users {
+			userMap[u.ID] = u
+		}
+
+		app_utils.ResponseExport(
+			ctx, fmt.Sprintf("%s_成绩及报名", cp.Name), req.Format,
+			comp.CompResultsTable(svc, cp),
+			export.RegistrationsTable("报名", regs, userMap),
+		)
+	}
+}
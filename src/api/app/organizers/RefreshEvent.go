@@ -2,6 +2,7 @@ package organizers
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/guojia99/cubing-pro/src/api/app/organizers/org_mid"
@@ -61,11 +62,21 @@ func RefreshEvent(svc *svc.Svc) gin.HandlerFunc {
 				return
 			}
 			schedule.IsRunning = true
+			schedule.ActualStartTime = time.Now()
+			schedule.ActualEndTime = time.Time{}
 			ev.UpdateSchedule(req.RoundNumber, schedule)
+
+			if schedule.Sealed {
+				if err = svc.Cov.ReleaseSealedScrambles(comp.ID, req.EventID, req.RoundNumber); err != nil {
+					exception.ErrResultUpdate.ResponseWithError(ctx, err)
+					return
+				}
+			}
 		case !req.Open && schedule.IsRunning:
 			// 关闭
 			// 判断是否有下一轮, 有的话需要给下一轮更新晋级名单
 			schedule.IsRunning = false
+			schedule.ActualEndTime = time.Now()
 			ev.UpdateSchedule(req.RoundNumber, schedule)
 
 			last, err := ev.CurRunningSchedule(req.RoundNumber+1, nil)
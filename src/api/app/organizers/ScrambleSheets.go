@@ -0,0 +1,93 @@
+package organizers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/app/organizers/org_mid"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	"github.com/guojia99/cubing-pro/src/api/middleware"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	_interface "github.com/guojia99/cubing-pro/src/internel/convenient/interface"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/scramble"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type ScrambleSheetsReq struct {
+	CompReq
+	EventID  string `json:"EventID"`  // 为空时导出全部项目
+	RoundNum int    `json:"RoundNum"` // 为0时导出全部轮次
+	Password string `json:"Password"` // 压缩包密码, 为空时不加密
+}
+
+// ScrambleSheets 导出可打印的打乱表, 每个轮次一个 PDF, 打包为 zip, 密封的打乱会解密后导出并记录查看
+func ScrambleSheets(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req ScrambleSheetsReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+		usr, err := middleware.GetAuthUser(ctx)
+		if err != nil {
+			return
+		}
+
+		comp := ctx.Value(org_mid.CompMiddlewareKey).(competition.Competition)
+		var events []competition.CompetitionEvent
+		var logs []competition.ScrambleViewLog
+		for _, ev := range comp.CompJSON.Events {
+			if req.EventID != "" && ev.EventID != req.EventID {
+				continue
+			}
+			var schedules []competition.Schedule
+			for _, schedule := range ev.Schedule {
+				if schedule.NotScramble || (req.RoundNum != 0 && schedule.RoundNum != req.RoundNum) {
+					continue
+				}
+				scrambles, err := svc.Cov.RoundScrambles(comp, ev.EventID, schedule.RoundNum)
+				if err != nil {
+					exception.ErrResourceNotFound.ResponseWithError(ctx, fmt.Errorf("%s %s: %w", ev.EventName, schedule.Round, err))
+					return
+				}
+				if schedule.Sealed {
+					logs = append(logs, competition.ScrambleViewLog{
+						CompID:   comp.ID,
+						EventID:  ev.EventID,
+						RoundNum: schedule.RoundNum,
+						UserID:   usr.ID,
+						CubeID:   usr.CubeID,
+						UserName: usr.Name,
+						Index:    _interface.ScrambleViewSheet,
+						IP:       ctx.ClientIP(),
+					})
+				}
+				schedule.Scrambles = scrambles
+				schedules = append(schedules, schedule)
+			}
+			ev.Schedule = schedules
+			events = append(events, ev)
+		}
+
+		data, err := svc.Scramble.ScrambleSheets(comp.Name, events, scramble.SheetOptions{
+			FontPath: svc.Cfg.GlobalConfig.BaseFontTTf,
+			Password: req.Password,
+		})
+		if err != nil {
+			exception.ErrInternalServer.ResponseWithError(ctx, err)
+			return
+		}
+		if len(logs) > 0 {
+			if err = svc.DB.Create(&logs).Error; err != nil {
+				exception.ErrInternalServer.ResponseWithError(ctx, err)
+				return
+			}
+		}
+
+		fileName := fmt.Sprintf("%s-打乱表.zip", comp.Name)
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(fileName)))
+		ctx.Data(http.StatusOK, "application/zip", data)
+	}
+}
@@ -0,0 +1,34 @@
+package organizers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/app/organizers/org_mid"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type ScrambleViewLogsReq struct {
+	CompReq
+	EventID  string `form:"event_id"`
+	RoundNum int    `form:"round_num"`
+}
+
+// ScrambleViewLogs 密封打乱的查看记录, 谁在什么时间查看了哪一把
+func ScrambleViewLogs(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req ScrambleViewLogsReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+
+		comp := ctx.Value(org_mid.CompMiddlewareKey).(competition.Competition)
+		logs, err := svc.Cov.ScrambleViewLogs(comp.ID, req.EventID, req.RoundNum)
+		if err != nil {
+			exception.ErrDatabase.ResponseWithError(ctx, err)
+			return
+		}
+		exception.ResponseOK(ctx, logs)
+	}
+}
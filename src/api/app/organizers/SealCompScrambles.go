@@ -0,0 +1,34 @@
+package organizers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/app/organizers/org_mid"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type SealCompScramblesReq struct {
+	CompReq
+	competition.ScrambleSealRule
+}
+
+// SealCompScrambles 密封比赛中尚未密封的打乱, 轮次开启后才对选手公布
+func SealCompScrambles(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req SealCompScramblesReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+
+		comp := ctx.Value(org_mid.CompMiddlewareKey).(competition.Competition)
+		comp.CompJSON.ScrambleSeal = req.ScrambleSealRule
+		comp.CompJSON.ScrambleSeal.Enable = true
+		if err := svc.Cov.SealCompScrambles(&comp); err != nil {
+			exception.ErrResultUpdate.ResponseWithError(ctx, err)
+			return
+		}
+		exception.ResponseOK(ctx, comp.CompJSON.ScrambleSeal)
+	}
+}
@@ -34,7 +34,7 @@ func UpdateComp(svc *svc.Svc) gin.HandlerFunc {
 		comp.Country = req.Country
 		comp.City = req.City
 		comp.RuleMD = req.RuleMD
-		comp.CompJSON = req.CompJSON
+		comp.CompJSON = mergeCompJSON(comp.CompJSON, req.CompJSON)
 		comp.AutomaticReview = req.AutomaticReview
 		comp.WCAUrl = req.WCAUrl
 
@@ -50,7 +50,14 @@ func UpdateComp(svc *svc.Svc) gin.HandlerFunc {
 		comp.RegistrationCancelDeadlineTime = req.RegistrationCancelDeadlineTime
 		comp.RegistrationRestartTime = req.RegistrationRestartTime
 
-		if err := svc.DB.Save(&comp).Error; err != nil {
+		// 新提交的打乱需要重新密封, 密封与保存在同一事务中
+		var err error
+		if comp.CompJSON.ScrambleSeal.Enable {
+			err = svc.Cov.SealCompScrambles(&comp)
+		} else {
+			err = svc.DB.Save(&comp).Error
+		}
+		if err != nil {
 			exception.ErrResultUpdate.ResponseWithError(ctx, err)
 			return
 		}
@@ -59,6 +66,33 @@ func UpdateComp(svc *svc.Svc) gin.HandlerFunc {
 	}
 }
 
+// mergeCompJSON 使用提交的比赛配置, 但保留只能由服务端修改的密封打乱与预录入审核配置;
+// 已密封且未重新提交打乱的轮次保持密封状态
+func mergeCompJSON(old, in competition.CompetitionJson) competition.CompetitionJson {
+	in.ScrambleSeal = old.ScrambleSeal
+	in.PreResultRule = old.PreResultRule
+
+	sealed := make(map[string]map[int]bool)
+	for _, ev := range old.Events {
+		for _, sc := range ev.Schedule {
+			if sc.Sealed {
+				if sealed[ev.EventID] == nil {
+					sealed[ev.EventID] = make(map[int]bool)
+				}
+				sealed[ev.EventID][sc.RoundNum] = true
+			}
+		}
+	}
+	for i := range in.Events {
+		ev := &in.Events[i]
+		for j := range ev.Schedule {
+			sc := &ev.Schedule[j]
+			sc.Sealed = sealed[ev.EventID][sc.RoundNum] && len(sc.Scrambles) == 0
+		}
+	}
+	return in
+}
+
 type ApprovalCompReq struct {
 	CompReq
 	Ok bool `json:"Ok"`
@@ -0,0 +1,49 @@
+package organizers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/app/organizers/org_mid"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type UpdatePreResultRuleReq struct {
+	CompReq
+	competition.PreResultRule
+}
+
+func (r UpdatePreResultRuleReq) check() error {
+	if r.WithinPBPercent < 0 || r.WithinPBPercent >= 100 {
+		return errors.New("个人最佳百分比需在0~100之间")
+	}
+	if r.ExpireAfterMinutes < 0 {
+		return errors.New("过期时间不可小于0")
+	}
+	return nil
+}
+
+// UpdatePreResultRule 设置比赛的预录入成绩自动审核规则
+func UpdatePreResultRule(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req UpdatePreResultRuleReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+		if err := req.check(); err != nil {
+			exception.ErrInvalidInput.ResponseWithError(ctx, err)
+			return
+		}
+
+		comp := ctx.Value(org_mid.CompMiddlewareKey).(competition.Competition)
+		comp.CompJSON.PreResultRule = req.PreResultRule
+		if err := svc.DB.Save(&comp).Error; err != nil {
+			exception.ErrResultUpdate.ResponseWithError(ctx, err)
+			return
+		}
+		exception.ResponseOK(ctx, comp.CompJSON.PreResultRule)
+	}
+}
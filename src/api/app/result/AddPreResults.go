@@ -1,8 +1,10 @@
 package result
 
 import (
+	"errors"
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/guojia99/cubing-pro/src/api/exception"
@@ -11,6 +13,7 @@ import (
 	basemodel "github.com/guojia99/cubing-pro/src/internel/database/model/base"
 	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
 	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
+	"github.com/guojia99/cubing-pro/src/internel/scramble/puzzle"
 	"github.com/guojia99/cubing-pro/src/internel/svc"
 )
 
@@ -20,6 +23,8 @@ type AddPreResultsReq struct {
 	Results []float64      `json:"Results"`
 	Penalty result.Penalty `json:"Penalty"`
 	EventID string         `json:"EventID"`
+
+	Solutions []string `json:"Solutions,omitempty"` // 最少步的解法, 按顺序对应每一把成绩, 提交后成绩由解法步数决定
 }
 
 func AddPreResults(svc *svc.Svc) gin.HandlerFunc {
@@ -86,6 +91,19 @@ func AddPreResults(svc *svc.Svc) gin.HandlerFunc {
 			return
 		}
 
+		// 最少步提交解法时, 按解法校验结果记录每一把的步数, 不合法的记为DNF
+		var fmSolutions []result.FmSolution
+		if len(req.Solutions) > 0 {
+			if !ev.EventRoute.RouteMap().Integer {
+				exception.ErrResultCreate.ResponseWithError(ctx, "该项目不支持提交解法")
+				return
+			}
+			if req.Results, fmSolutions, err = checkFmSolutions(svc, comp, ev, schedule.RoundNum, req.Solutions, req.Results); err != nil {
+				exception.ErrResultCreate.ResponseWithError(ctx, err)
+				return
+			}
+		}
+
 		// 查看是否有旧的数据存在，如果有则覆盖
 		var pre result.PreResults
 		if err = svc.DB.First(
@@ -106,6 +124,8 @@ func AddPreResults(svc *svc.Svc) gin.HandlerFunc {
 				CompetitionID:   comp.ID,
 				CompetitionName: comp.Name,
 				Round:           schedule.Round,
+				RoundNumber:     schedule.RoundNum,
+				CubeID:          user.CubeID,
 				PersonName:      user.Name,
 				UserID:          user.ID,
 				Result:          req.Results,
@@ -113,6 +133,7 @@ func AddPreResults(svc *svc.Svc) gin.HandlerFunc {
 				EventID:         ev.EventID,
 				EventName:       ev.EventName,
 				EventRoute:      ev.EventRoute,
+				FmSolutions:     fmSolutions,
 			},
 			CompsName: comp.Name,
 			RoundName: schedule.Round,
@@ -123,10 +144,61 @@ func AddPreResults(svc *svc.Svc) gin.HandlerFunc {
 			exception.ErrResultCreate.ResponseWithError(ctx, err)
 			return
 		}
-		if err = svc.DB.Save(&req).Error; err != nil {
+		if err = svc.DB.Save(&pre).Error; err != nil {
 			exception.ErrResultCreate.ResponseWithError(ctx, err)
 			return
 		}
+
+		// 按比赛规则自动审核, 失败时保留待人工审核
+		_, _ = svc.Cov.AutoApprovalPreResult(comp, &pre)
 		exception.ResponseOK(ctx, nil)
 	}
 }
+
+// checkFmSolutions 校验每一把的解法并按步数计算成绩, 不合法的解法记为DNF;
+// 除DNF/DNS外的每一把都需要提交解法, 没有成绩的一把记为DNS
+func checkFmSolutions(svc *svc.Svc, comp competition.Competition, ev competition.CompetitionEvent, roundNum int, solutions []string, results []float64) ([]float64, []result.FmSolution, error) {
+	rounds := ev.EventRoute.RouteMap().Rounds
+	if len(solutions) > rounds {
+		return nil, nil, fmt.Errorf("最多提交%d把解法", rounds)
+	}
+
+	scrambles, err := svc.Cov.RoundScrambles(comp, ev.EventID, roundNum)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(scrambles) == 0 || len(scrambles[0]) < len(solutions) {
+		return nil, nil, errors.New("该轮次的打乱数量不足, 无法校验解法")
+	}
+
+	out := make([]float64, rounds)
+	for idx := range out {
+		out[idx] = result.DNS
+		if idx < len(results) && results[idx] != 0 {
+			out[idx] = results[idx]
+		}
+	}
+
+	fms := make([]result.FmSolution, len(solutions))
+	for idx := range out {
+		solution := ""
+		if idx < len(solutions) {
+			solution = strings.TrimSpace(solutions[idx])
+		}
+		if solution == "" {
+			if out[idx] != result.DNF && out[idx] != result.DNS {
+				return nil, nil, fmt.Errorf("第%d把缺少解法", idx+1)
+			}
+			continue
+		}
+		moves, err := puzzle.CheckFMCSolution(scrambles[0][idx], solution)
+		fms[idx] = result.FmSolution{Solution: solution, Moves: moves}
+		if err != nil {
+			fms[idx].Reason = err.Error()
+			out[idx] = result.DNF
+			continue
+		}
+		out[idx] = float64(moves)
+	}
+	return out, fms, nil
+}
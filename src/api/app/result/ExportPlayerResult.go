@@ -1,2 +1,105 @@
 package result
 
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/user"
+	"github.com/guojia99/cubing-pro/src/internel/export"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type ExportPlayerResultReq struct {
+	CubeId string `uri:"cubeId"`
+	app_utils.ExportReq
+}
+
+// ExportPlayerResult 导出玩家的全部成绩
+func ExportPlayerResult(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req ExportPlayerResultReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+		if err := req.Check(); err != nil {
+			exception.ErrInvalidInput.ResponseWithError(ctx, err)
+			return
+		}
+
+		var usr user.User
+		if err := svc.DB.First(&usr, "cube_id = ?", req.CubeId).Error; err != nil {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, err)
+			return
+		}
+
+		var rs []result.Results
+		if err := svc.DB.Find(&rs, "cube_id = ? and ban = ?", req.CubeId, false).Error; err != nil {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, err)
+			return
+		}
+
+		var compIds, resultIds []uint
+		var eventIds []string
+		for _, r := range rs {
+			compIds = append(compIds, r.CompetitionID)
+			resultIds = append(resultIds, r.ID)
+			eventIds = append(eventIds, r.EventID)
+		}
+		var comps []competition.Competition
+		var records []result.Record
+		var compResults []result.Results
+		if len(rs) > 0 {
+			svc.DB.Where("id in ?", compIds).Find(&comps)
+			svc.DB.Where("result_id in ?", resultIds).Find(&records)
+			// 名次需要从整场比赛的排名中获取, 一次查出相关比赛与项目的全部成绩
+			svc.DB.Where("comp_id in ? and event_id in ? and ban = ?", compIds, eventIds, false).Find(&compResults)
+		}
+
+		var compDates = make(map[uint]time.Time, len(comps))
+		for _, comp := range comps {
+			compDates[comp.ID] = comp.CompStartTime
+		}
+
+		type roundKey struct {
+			compId  uint
+			eventId string
+			round   int
+		}
+		var rounds = make(map[roundKey][]result.Results)
+		for _, r := range compResults {
+			key := roundKey{compId: r.CompetitionID, eventId: r.EventID, round: r.RoundNumber}
+			rounds[key] = append(rounds[key], r)
+		}
+		var ranks = make(map[uint]int)
+		for _, list := range rounds {
+			result.SortResult(list)
+			for _, r := range list {
+				if r.CubeID == usr.CubeID {
+					ranks[r.ID] = r.Rank
+				}
+			}
+		}
+		for i := range rs {
+			rs[i].Rank = ranks[rs[i].ID]
+		}
+		sort.SliceStable(rs, func(i, j int) bool {
+			di, dj := compDates[rs[i].CompetitionID], compDates[rs[j].CompetitionID]
+			if !di.Equal(dj) {
+				return di.Before(dj)
+			}
+			if rs[i].EventID != rs[j].EventID {
+				return rs[i].EventID < rs[j].EventID
+			}
+			return rs[i].RoundNumber < rs[j].RoundNumber
+		})
+
+		table := export.ResultsTable("成绩", rs, compDates, export.RecordsByResult(records))
+		app_utils.ResponseExport(ctx, fmt.Sprintf("%s_%s", usr.Name, usr.CubeID), req.Format, table)
+	}
+}
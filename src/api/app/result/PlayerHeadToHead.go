@@ -0,0 +1,31 @@
+package result
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type PlayerHeadToHeadReq struct {
+	CubeId      string   `uri:"cubeId"`
+	OtherCubeId string   `uri:"otherCubeId"`
+	Events      []string `form:"events" json:"Events"` // 为空则对比全部项目
+}
+
+// PlayerHeadToHead 两位玩家的成绩对比
+func PlayerHeadToHead(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req PlayerHeadToHeadReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+
+		h2h, err := svc.Cov.SelectHeadToHead(req.CubeId, req.OtherCubeId, req.Events)
+		if err != nil {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, err)
+			return
+		}
+		exception.ResponseOK(ctx, h2h)
+	}
+}
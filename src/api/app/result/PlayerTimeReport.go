@@ -2,12 +2,29 @@ package result
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
 	"github.com/guojia99/cubing-pro/src/internel/svc"
 )
 
+type PlayerTimeReportsReq struct {
+	CubeId string   `uri:"cubeId"`
+	Events []string `form:"events" json:"Events"` // 为空则统计全部项目
+}
+
 // PlayerTimeReports 按时间出报表
 func PlayerTimeReports(svc *svc.Svc) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		var req PlayerTimeReportsReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
 
+		report, err := svc.Cov.SelectPlayerTimeReport(req.CubeId, req.Events)
+		if err != nil {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, err)
+			return
+		}
+		exception.ResponseOK(ctx, report)
 	}
 }
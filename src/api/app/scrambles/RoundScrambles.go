@@ -0,0 +1,50 @@
+package scrambles
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+const maxRoundGroups = 10
+
+type RoundScramblesReq struct {
+	EventID string `uri:"eventId"`
+	Groups  int    `form:"groups"` // 分组数, 默认1组
+	Image   bool   `form:"image"`  // 是否附带打乱图
+}
+
+type RoundScramblesResp struct {
+	EventID string           `json:"eventId"`
+	Groups  [][]ScrambleItem `json:"groups"` // 每组按项目赛制生成, 包含备打
+}
+
+// RoundScrambles 按项目的赛制生成一整轮的打乱, 与比赛生成打乱的规则一致
+func RoundScrambles(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req RoundScramblesReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+		if req.Groups <= 0 {
+			req.Groups = 1
+		}
+		if req.Groups > maxRoundGroups {
+			exception.ErrInvalidInput.ResponseWithError(ctx, "单次最多生成10组打乱")
+			return
+		}
+
+		ev := findEvent(svc, req.EventID)
+		resp := RoundScramblesResp{EventID: req.EventID}
+		for i := 0; i < req.Groups; i++ {
+			data, err := svc.Scramble.ScrambleWithComp(ev)
+			if err != nil {
+				exception.ErrInternalServer.ResponseWithError(ctx, err)
+				return
+			}
+			resp.Groups = append(resp.Groups, scrambleItems(svc, ev, data, req.Image))
+		}
+		exception.ResponseOK(ctx, resp)
+	}
+}
@@ -0,0 +1,52 @@
+package scrambles
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	app_utils "github.com/guojia99/cubing-pro/src/api/utils"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+const maxScrambles = 50
+
+type ScramblesReq struct {
+	EventID string `uri:"eventId"`
+	Num     int    `form:"num"`   // 打乱数, 默认5条
+	Image   bool   `form:"image"` // 是否附带打乱图
+}
+
+// Scrambles 生成任意支持项目的N条打乱
+func Scrambles(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req ScramblesReq
+		if err := app_utils.BindAll(ctx, &req); err != nil {
+			return
+		}
+		if req.Num <= 0 {
+			req.Num = 5
+		}
+		if req.Num > maxScrambles {
+			exception.ErrInvalidInput.ResponseWithError(ctx, "单次最多生成50条打乱")
+			return
+		}
+
+		ev := findEvent(svc, req.EventID)
+
+		// 组合打乱的项目每次按轮次生成, 不够时继续生成
+		var out []string
+		for len(out) < req.Num {
+			data, err := svc.Scramble.ScrambleWithEvent(ev, req.Num-len(out))
+			if err != nil {
+				exception.ErrInternalServer.ResponseWithError(ctx, err)
+				return
+			}
+			if len(data) == 0 {
+				break
+			}
+			out = append(out, data...)
+		}
+		out = out[:min(len(out), req.Num)]
+
+		exception.ResponseOK(ctx, scrambleItems(svc, ev, out, req.Image))
+	}
+}
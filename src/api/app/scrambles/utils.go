@@ -0,0 +1,62 @@
+package scrambles
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type ScrambleItem struct {
+	Scramble string `json:"scramble"`
+	Image    string `json:"image,omitempty"` // data URI, 绘制失败时为空
+}
+
+// findEvent 数据库中的项目, 不存在时按打乱后端的项目ID处理
+func findEvent(svc *svc.Svc, eventId string) event.Event {
+	var ev event.Event
+	if err := svc.DB.First(&ev, "id = ?", eventId).Error; err == nil {
+		return ev
+	}
+	ev = event.Event{IsWCA: true, BaseRouteType: event.RouteType5RoundsAvgHT}
+	ev.ID = eventId
+	return ev
+}
+
+// puzzleEvent 绘制打乱图使用的项目
+func puzzleEvent(ev event.Event) string {
+	switch {
+	case ev.PuzzleID != "":
+		return ev.PuzzleID
+	case !ev.IsWCA && ev.AutoScrambleKey == "FTO":
+		return "fto"
+	case !ev.IsWCA && ev.ScrambleValue != "" && !strings.Contains(ev.ScrambleValue, ","):
+		return ev.ScrambleValue
+	}
+	return ev.ID
+}
+
+func scrambleItems(svc *svc.Svc, ev event.Event, scrambles []string, withImage bool) []ScrambleItem {
+	out := make([]ScrambleItem, 0, len(scrambles))
+	for _, sc := range scrambles {
+		item := ScrambleItem{Scramble: sc}
+		if withImage {
+			if img, err := svc.Scramble.Image(sc, puzzleEvent(ev)); err == nil && img != "" {
+				item.Image = imageDataURI(img)
+			}
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// imageDataURI Image 返回的是 svg 文本或 png 等图片数据
+func imageDataURI(img string) string {
+	contentType := "image/svg+xml"
+	if !strings.HasPrefix(strings.TrimSpace(img), "<") {
+		contentType = http.DetectContentType([]byte(img))
+	}
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString([]byte(img))
+}
@@ -0,0 +1,39 @@
+package wca
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+// ComparePersons 两位选手的 PB、排名与同场对决对比，?format=png 时返回总结图
+func ComparePersons(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		a, b := ctx.Param("a"), ctx.Param("b")
+
+		if ctx.Query("format") == "png" {
+			img, err := svc.Wca.GetPersonCompareImage(a, b)
+			if err != nil {
+				ctx.JSON(http.StatusNotFound, gin.H{})
+				return
+			}
+			var buf bytes.Buffer
+			if err = png.Encode(&buf, img); err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{})
+				return
+			}
+			ctx.Data(http.StatusOK, "image/png", buf.Bytes())
+			return
+		}
+
+		out, err := svc.Wca.ComparePersons(a, b)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{})
+			return
+		}
+		ctx.JSON(http.StatusOK, out)
+	}
+}
@@ -0,0 +1,22 @@
+package wca
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+// CompetitionResults 比赛各轮次成绩、领奖台与纪录
+func CompetitionResults(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		compId := ctx.Param("compId")
+
+		out, err := svc.Wca.GetCompetitionResults(compId)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{})
+			return
+		}
+		ctx.JSON(http.StatusOK, out)
+	}
+}
@@ -33,7 +33,6 @@ func throttleBeforeCubingOutbound() {
 // HTTP 200 时 body 为 exception.ResponseOK 包装；仅参数非法时 HTTP 400。
 func CubingChinaPerson(svc *svc.Svc) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		_ = svc
 		wcaID := ctx.Param("wcaID")
 		if !cubing.ValidateWcaIDFormat(strings.TrimSpace(strings.ToUpper(wcaID))) {
 			exception.ErrInvalidInput.ResponseWithError(ctx, "WCA ID 格式无效（应为 10 位：4 数字 + 4 大写字母 + 2 数字）")
@@ -55,3 +54,37 @@ func CubingChinaPerson(svc *svc.Svc) gin.HandlerFunc {
 		exception.ResponseOK(ctx, res)
 	}
 }
+
+// SyncCubingPersonProvince 管理员使用粗饼选手主页的地区覆盖该选手的省份归属，公开查询接口不写入
+func SyncCubingPersonProvince(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		wcaID := strings.TrimSpace(strings.ToUpper(ctx.Param("wcaID")))
+		if !cubing.ValidateWcaIDFormat(wcaID) {
+			exception.ErrInvalidInput.ResponseWithError(ctx, "WCA ID 格式无效（应为 10 位：4 数字 + 4 大写字母 + 2 数字）")
+			return
+		}
+
+		cubingPersonOutboundMu.Lock()
+		defer cubingPersonOutboundMu.Unlock()
+		throttleBeforeCubingOutbound()
+
+		cctx, cancel := context.WithTimeout(ctx.Request.Context(), 8*time.Second)
+		defer cancel()
+
+		res := cubing.FetchPersonPage(cctx, wcaID)
+		if res.Code != cubing.PersonCodeOK || res.Person == nil {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, res.Message)
+			return
+		}
+		region := res.Person.Details[cubing.PersonDetailRegion]
+		if region == "" {
+			exception.ErrResourceNotFound.ResponseWithError(ctx, "粗饼主页没有地区信息")
+			return
+		}
+		if err := svc.Wca.SetPersonProvince(res.Person.WcaID, region); err != nil {
+			exception.ErrInvalidInput.ResponseWithError(ctx, err)
+			return
+		}
+		exception.ResponseOK(ctx, region)
+	}
+}
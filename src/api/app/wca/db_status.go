@@ -0,0 +1,16 @@
+package wca
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+// DBStatus 当前使用的 WCA 数据快照日期与同步状态
+func DBStatus(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		out := svc.Wca.Status()
+		ctx.JSON(http.StatusOK, out)
+	}
+}
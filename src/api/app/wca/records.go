@@ -0,0 +1,59 @@
+package wca
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+type RecordHistoryRequest struct {
+	EventID string `form:"eventId"`
+	Country string `form:"country"` // 纪录保持者国家
+	Scope   string `form:"scope"`   // WR / CR / NR / PR
+	Region  string `form:"region"`  // 大洲 ID / 国家 / 省份
+}
+
+// RecordHistory 纪录历史
+func RecordHistory(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req RecordHistoryRequest
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{})
+			return
+		}
+
+		out, err := svc.Wca.GetRecordHistory(req.EventID, req.Country, req.Scope, req.Region)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, out)
+	}
+}
+
+// CurrentRecords 国家现有纪录，中国包含各省现有纪录
+func CurrentRecords(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		country := ctx.Param("country")
+
+		out, err := svc.Wca.GetCurrentRecords(country)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{})
+			return
+		}
+		ctx.JSON(http.StatusOK, out)
+	}
+}
+
+// ProvinceRecords 中国各省现有纪录
+func ProvinceRecords(svc *svc.Svc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		out, err := svc.Wca.GetProvinceRecords(ctx.Query("province"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, out)
+	}
+}
@@ -38,6 +38,8 @@ type BaseStaticsRequest struct {
 	MinAttempted int      `json:"min_attempted"`
 
 	LackNum int `json:"lackNum"`
+
+	Province string `json:"province"` // 中国省份，拼音或中文
 }
 
 type BaseStaticsResponse struct {
@@ -57,7 +59,7 @@ func BaseStaticsWithEventAndCacheKey(svc *svc.Svc, funcKey string) gin.HandlerFu
 			ctx.JSON(http.StatusNotFound, gin.H{})
 			return
 		}
-		key := fmt.Sprintf("%s_%s_%+v_%d_%d_%d_%d_%d", req.EventID, req.Country, req.IsAvg, req.Page, req.Size, req.Year, req.MinAttempted, req.LackNum)
+		key := fmt.Sprintf("%s_%s_%+v_%d_%d_%d_%d_%d_%s", req.EventID, req.Country, req.IsAvg, req.Page, req.Size, req.Year, req.MinAttempted, req.LackNum, req.Province)
 		getData, ok := cacheData.Get(key)
 		if ok {
 			ctx.JSON(http.StatusOK, getData)
@@ -81,6 +83,12 @@ func BaseStaticsWithEventAndCacheKey(svc *svc.Svc, funcKey string) gin.HandlerFu
 			out, count, err = svc.Wca.GetAllEventsAchievement(req.LackNum, req.Country, req.Size, req.Page)
 		case "GetRankWithEvents":
 			out, count, err = svc.Wca.GetRankWithEvents(req.Events, req.Country, req.IsAvg, req.Page, req.Size)
+		case "GetProvinceRank":
+			out, count, err = svc.Wca.GetProvinceRank(req.EventID, req.Province, req.IsAvg, req.Page, req.Size)
+		case "GetProvinceSor":
+			out, count, err = svc.Wca.GetProvinceSor(req.Province, req.IsAvg, req.Page, req.Size)
+		case "GetProvinceKinch":
+			out, count, err = svc.Wca.GetProvinceKinch(req.Province, req.Page, req.Size)
 		}
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{})
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/guojia99/cubing-pro/src/api/exception"
+	user2 "github.com/guojia99/cubing-pro/src/internel/database/model/user"
 )
 
 type RateLimitMiddlewareRequestInfo struct {
@@ -43,3 +45,59 @@ func RateLimitMiddleware(limit int, duration time.Duration) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+type userRateLimitInfo struct {
+	mu           sync.Mutex
+	windowStart  time.Time
+	requestCount int
+}
+
+// UserRateLimitMiddleware 按登录用户限流, 需要放在 CheckAuthMiddlewareFunc 之后, 未登录时按IP
+func UserRateLimitMiddleware(limit int, duration time.Duration) gin.HandlerFunc {
+	var clients sync.Map
+	var sweepMu sync.Mutex
+	var lastSweep = time.Now()
+
+	return func(ctx *gin.Context) {
+		key := "ip:" + ctx.ClientIP()
+		if val, ok := ctx.Get(authUserKey); ok {
+			key = fmt.Sprintf("user:%d", val.(user2.User).ID)
+		}
+		now := time.Now()
+
+		// 每个窗口清理一次已过期的记录, 避免长期运行后占用内存
+		sweepMu.Lock()
+		if now.Sub(lastSweep) > duration {
+			lastSweep = now
+			clients.Range(func(k, v any) bool {
+				info := v.(*userRateLimitInfo)
+				info.mu.Lock()
+				expired := now.Sub(info.windowStart) > duration
+				info.mu.Unlock()
+				if expired {
+					clients.Delete(k)
+				}
+				return true
+			})
+		}
+		sweepMu.Unlock()
+
+		val, _ := clients.LoadOrStore(key, &userRateLimitInfo{windowStart: now})
+		info := val.(*userRateLimitInfo)
+
+		info.mu.Lock()
+		if now.Sub(info.windowStart) > duration {
+			info.windowStart = now
+			info.requestCount = 0
+		}
+		info.requestCount++
+		exceeded := info.requestCount > limit
+		info.mu.Unlock()
+
+		if exceeded {
+			exception.ErrRateLimitExceeded.ResponseWithError(ctx, "请求过快")
+			return
+		}
+		ctx.Next()
+	}
+}
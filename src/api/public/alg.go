@@ -60,26 +60,17 @@ func AlgorithmGroupsWithCube(svc *svc.Svc) gin.HandlerFunc {
 			ctx.JSON(http.StatusOK, data)
 			return
 		}
-		resp := AlgorithmGroupsWithCubeResponse{}
-
-		cube, ok := algs.GetAlgorithms()[cubeID]
-		if !ok {
+		if _, ok := algs.GetAlgorithms()[cubeID]; !ok {
 			exception.ErrResourceNotFound.ResponseWithError(ctx, fmt.Errorf("not this cube"))
 			return
 		}
 
-		for _, v := range cube.ClassList {
-			if v.Name == classID {
-				resp = AlgorithmGroupsWithCubeResponse{
-					AlgorithmClass: v,
-				}
-				break
-			}
-		}
-		if resp.Name == "" {
+		class, ok := algs.GetVerifiedAlgorithmClass(cubeID, classID)
+		if !ok {
 			exception.ErrResourceNotFound.ResponseWithError(ctx, fmt.Errorf("not this class"))
 			return
 		}
+		resp := AlgorithmGroupsWithCubeResponse{AlgorithmClass: class}
 
 		cacheData.Set(key, resp, 120*time.Minute)
 		ctx.JSON(http.StatusOK, resp)
@@ -12,6 +12,7 @@ import (
 	posts "github.com/guojia99/cubing-pro/src/api/app/post"
 	systemResults "github.com/guojia99/cubing-pro/src/api/app/systemResult"
 	"github.com/guojia99/cubing-pro/src/api/app/users"
+	"github.com/guojia99/cubing-pro/src/api/app/wca"
 	"github.com/guojia99/cubing-pro/src/api/middleware"
 	user2 "github.com/guojia99/cubing-pro/src/internel/database/model/user"
 	"github.com/guojia99/cubing-pro/src/internel/svc"
@@ -51,9 +52,11 @@ func AdminRouters(router *gin.RouterGroup, svc *svc.Svc) {
 	// 项目管理
 	event := superAdmin.Group("/events")
 	{
-		event.GET("/", events2.Events(svc))         // 项目列表
-		event.POST("/", events2.CreateEvents(svc))  // 新增项目
-		event.DELETE("/", events2.DeleteEvent(svc)) // 移除项目
+		event.GET("/", events2.Events(svc))                                // 项目列表
+		event.POST("/", events2.CreateEvents(svc))                         // 新增项目
+		event.DELETE("/", events2.DeleteEvent(svc))                        // 移除项目
+		event.PUT("/:id/scramble", events2.UpdateEventScramble(svc))       // 修改项目的自定义打乱规则
+		event.POST("/scramble/preview", events2.PreviewEventScramble(svc)) // 预览项目打乱
 	}
 
 	// 通知管理
@@ -84,6 +87,13 @@ func AdminRouters(router *gin.RouterGroup, svc *svc.Svc) {
 		systemResult.GET("/otherLinks", other_link.GetOtherLinks(svc)) // 外部链接
 	}
 
+	// WCA 数据
+	wcaDB := superAdmin.Group("/wca")
+	{
+		wcaDB.GET("/status", wca.DBStatus(svc))                                 // 当前快照日期与同步状态
+		wcaDB.PUT("/person_province/:wcaID", wca.SyncCubingPersonProvince(svc)) // 使用粗饼主页地区更新选手省份
+	}
+
 	// 帖子管理
 	post := superAdmin.Group("/post")
 	{
@@ -71,12 +71,19 @@ func CompWithOrgRouters(router *gin.RouterGroup, svc *svc.Svc) {
 			//compId.DELETE("/players", organizers2.DeleteCompPlayer(svc))                  // 移除比赛选手
 
 			compId.GET("/result", organizers2.GetCompResult(svc))
+			compId.GET("/result/export", organizers2.ExportCompResults(svc))                              // 导出比赛成绩及报名信息
 			compId.POST("/result", organizers2.AddCompResult(svc))                                        // 录入比赛成绩
 			compId.DELETE("/result/:result_id", organizers2.DeleteCompResult(svc))                        // 删除比赛成绩
 			compId.GET("/pre_results", organizers2.GetCompPlayerPreResult(svc))                           // 获取预录入成绩
 			compId.POST("/pre_results/:result_id/approval", organizers2.ApprovalCompPlayerPreResult(svc)) // 审批预录入成绩
+			compId.POST("/pre_results/approvals", organizers2.ApprovalCompPlayerPreResults(svc))          // 批量审批预录入成绩
+			compId.PUT("/pre_results/rule", organizers2.UpdatePreResultRule(svc))                         // 设置预录入成绩审核规则
 
 			compId.PUT("/:reg_id/:compId/refresh_event", organizers2.RefreshEvent(svc)) // 刷新项目的轮次信息
+
+			compId.POST("/scrambles/seal", organizers2.SealCompScrambles(svc)) // 密封比赛打乱
+			compId.GET("/scrambles/logs", organizers2.ScrambleViewLogs(svc))   // 密封打乱查看记录
+			compId.POST("/scrambles/sheets", organizers2.ScrambleSheets(svc))  // 导出打乱表PDF
 		}
 
 	}
@@ -95,6 +102,12 @@ func CompWithUserRouters(router *gin.RouterGroup, svc *svc.Svc) {
 		results.DELETE("/pre/delete/:pre_id", result.DeletePreResult(svc)) // 删除预录入成绩
 	}
 
+	scrambles := userComp.Group("/scrambles")
+	{
+		scrambles.GET("/:compId/:eventId/:roundNum", comp.CompSealedScrambles(svc, false))      // 查看已公布的密封打乱
+		scrambles.POST("/:compId/:eventId/:roundNum/next", comp.CompSealedScrambles(svc, true)) // 逐把公布下一把打乱
+	}
+
 	router.GET("/player_comp/register/comps/:compId/callback/:registerId", comp.RegisterCompCallback(svc)) // 报名比赛支付回调
 	registers := userComp.Group(
 		"/register",
@@ -40,23 +40,26 @@ func PublicRouters(router *gin.RouterGroup, svc *svc.Svc) {
 	player := public.Group("/player") //middleware.CacheMiddleware(time.Minute),
 
 	{
-		player.Any("/", users.Users(svc, 100))            // 玩家列表
-		player.GET("/:cubeId", users.UserBaseResult(svc)) // 玩家基础信息
-		//player.GET("/player/:playerId/report/", result.PlayerTimeReports(svc)) // 报表
-		player.GET("/:cubeId/results", result.PlayerResults(svc)) // 玩家成绩汇总
-		player.GET("/:cubeId/nemesis", result.PlayerNemesis(svc)) // 宿敌列表
-		player.GET("/:cubeId/records", result.PlayerRecords(svc)) // 玩家记录
-		player.GET("/:cubeId/sor", result.PlayerSor(svc))         // 玩家统计成绩
-		player.GET("/:cubeId/comps", result.PlayerComps(svc))     // 玩家参加过的比赛列表
+		player.Any("/", users.Users(svc, 100))                                // 玩家列表
+		player.GET("/:cubeId", users.UserBaseResult(svc))                     // 玩家基础信息
+		player.GET("/:cubeId/results", result.PlayerResults(svc))             // 玩家成绩汇总
+		player.GET("/:cubeId/nemesis", result.PlayerNemesis(svc))             // 宿敌列表
+		player.GET("/:cubeId/records", result.PlayerRecords(svc))             // 玩家记录
+		player.GET("/:cubeId/sor", result.PlayerSor(svc))                     // 玩家统计成绩
+		player.GET("/:cubeId/comps", result.PlayerComps(svc))                 // 玩家参加过的比赛列表
+		player.GET("/:cubeId/report", result.PlayerTimeReports(svc))          // 报表
+		player.GET("/:cubeId/export", result.ExportPlayerResult(svc))         // 导出玩家成绩
+		player.GET("/:cubeId/h2h/:otherCubeId", result.PlayerHeadToHead(svc)) // 两位玩家对比
 	}
 
 	comps := public.Group("/comps")
 	{
-		comps.Any("/", comp.List(svc))                       // 比赛列表 查询
-		comps.GET("/:compId", comp.Comp(svc))                // 比赛详情
-		comps.GET("/:compId/registers", comp.Registers(svc)) // 比赛报名列表
-		comps.GET("/:compId/result", comp.Results(svc))      // 比赛成绩列表
-		comps.GET("/:compId/record", comp.Record(svc))       // 比赛产生的记录
+		comps.Any("/", comp.List(svc))                               // 比赛列表 查询
+		comps.GET("/:compId", comp.Comp(svc))                        // 比赛详情
+		comps.GET("/:compId/registers", comp.Registers(svc))         // 比赛报名列表
+		comps.GET("/:compId/result", comp.Results(svc))              // 比赛成绩列表
+		comps.GET("/:compId/result/export", comp.ResultsExport(svc)) // 导出比赛成绩
+		comps.GET("/:compId/record", comp.Record(svc))               // 比赛产生的记录
 	}
 
 	sta := public.Group("/statistics")
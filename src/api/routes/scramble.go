@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/app/scrambles"
+	"github.com/guojia99/cubing-pro/src/api/middleware"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/user"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+)
+
+// ScrambleRouters 打乱生成服务, 供计时器等第三方使用
+func ScrambleRouters(router *gin.RouterGroup, svc *svc.Svc) {
+	scramble := router.Group("/scramble",
+		middleware.JWT().MiddlewareFunc(),
+		middleware.CheckAuthMiddlewareFunc(user.AuthPlayer),
+		middleware.UserRateLimitMiddleware(30, time.Minute),
+	)
+	{
+		scramble.GET("/:eventId", scrambles.Scrambles(svc))            // 生成N条打乱
+		scramble.GET("/:eventId/round", scrambles.RoundScrambles(svc)) // 生成一整轮的打乱
+	}
+}
@@ -21,6 +21,9 @@ func WcaRouters(router *gin.RouterGroup, svc *svc.Svc) {
 		w.GET("/player/:wcaID/rank_timers", wca.GetPersonRankTimer(svc))
 		w.GET("/player/:wcaID/best_ranks", wca.BaseGetPlayerWithKey(svc, "GetPersonBestRanks"))
 
+		w.GET("/competition/:compId/results", wca.CompetitionResults(svc)) // 比赛成绩、领奖台与纪录
+		w.GET("/compare/:a/:b", wca.ComparePersons(svc))                   // 选手对比，?format=png 返回总结图
+
 		w.GET("/country", wca.Country(svc))
 		w.POST("/ranks/historical/full/:eventID", wca.BaseStaticsWithEventAndCacheKey(svc, "GetEventRankWithTimer")) // 截止某年
 		w.POST("/ranks/full/:eventID", wca.BaseStaticsWithEventAndCacheKey(svc, "GetEventRankWithFullNow"))
@@ -31,6 +34,14 @@ func WcaRouters(router *gin.RouterGroup, svc *svc.Svc) {
 
 		w.GET("/grand-slam", wca.GetGrandSlam(svc))
 
+		w.GET("/records/history", wca.RecordHistory(svc))           // 纪录历史 ?eventId=&country=&scope=&region=
+		w.GET("/records/current/:country", wca.CurrentRecords(svc)) // 现有纪录（中国含各省）
+
+		w.POST("/province/ranks/:eventID", wca.BaseStaticsWithEventAndCacheKey(svc, "GetProvinceRank")) // 中国省内排名，province 为空时为全部中国选手
+		w.POST("/province/sor", wca.BaseStaticsWithEventAndCacheKey(svc, "GetProvinceSor"))
+		w.POST("/province/kinch", wca.BaseStaticsWithEventAndCacheKey(svc, "GetProvinceKinch"))
+		w.GET("/province/records", wca.ProvinceRecords(svc)) // 省纪录 ?province=
+
 		// 粗饼选手主页代理（服务端抓取 HTML）；每 IP 限流；出站串行+节流在 Handler 内（见 cubing_china_person）
 		w.GET("/cubing-china/person/:wcaID", middleware.RateLimitMiddleware(80, time.Minute), wca.CubingChinaPerson(svc))
 	}
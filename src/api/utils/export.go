@@ -0,0 +1,36 @@
+package app_utils
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guojia99/cubing-pro/src/api/exception"
+	"github.com/guojia99/cubing-pro/src/internel/export"
+)
+
+type ExportReq struct {
+	Format export.Format `form:"format" json:"format" query:"format"` // csv / xlsx / json, 默认csv
+}
+
+func (r *ExportReq) Check() error {
+	if r.Format == "" {
+		r.Format = export.FormatCSV
+	}
+	return export.CheckFormat(r.Format)
+}
+
+// ResponseExport 以附件形式返回导出文件
+func ResponseExport(ctx *gin.Context, name string, format export.Format, tables ...export.Table) {
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, tables...); err != nil {
+		exception.ErrInternalServer.ResponseWithError(ctx, err)
+		return
+	}
+
+	fileName := export.FileName(name, format)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(fileName)))
+	ctx.Data(http.StatusOK, export.ContentType(format), buf.Bytes())
+}
@@ -34,6 +34,7 @@ type WcaAuth2 struct {
 
 type WcaDB struct {
 	//SyncUrl string `yaml:"syncUrl"`
+	Driver   string `yaml:"driver"` // mysql(默认), sqlite; sqlite 时数据库文件保存在 dbPath 下, 同步使用 TSV 导出
 	MysqlUrl string `yaml:"mysqlUrl"`
 	DbPath   string `yaml:"dbPath"`
 	SyncPath string `yaml:"syncPath"`
@@ -42,8 +43,14 @@ type WcaDB struct {
 type Scramble struct {
 	Type     string `yaml:"type"` // lang, tnoodle
 	EndPoint string `yaml:"endpoint"`
+	FilePath string `yaml:"filePath"` // tnoodle 打乱压缩包与pdf的保存目录
+	SealKey  string `yaml:"sealKey"`  // 密封打乱的加密密钥
 
-	ScrambleDrawType string `yaml:"scrambleDrawType"` // 2mf8
+	Backends      []string            `yaml:"backends"`      // 打乱后端尝试顺序: rust, tnoodle, go; 为空时按 type 决定
+	EventBackends map[string][]string `yaml:"eventBackends"` // 指定项目的后端顺序, 如 333fm: [tnoodle]
+	PoolSize      int                 `yaml:"poolSize"`      // 每个项目预生成的打乱数, 0 不预生成
+
+	ScrambleDrawType string `yaml:"scrambleDrawType"` // 2mf8, native(png), native_svg
 	ScrambleUrl      string `yaml:"scramble"`
 }
 
@@ -6,6 +6,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/guojia99/cubing-pro/src/internel/scramble/puzzle"
 	"github.com/guojia99/cubing-pro/src/internel/utils"
 )
 
@@ -153,7 +154,7 @@ func (c *Cube222) selectEg(selectInput []string, config interface{}) (output str
 		return fmt.Sprintf("找不到该case: %s", key), "", nil
 	}
 
-	out, img := alg.Data(c.eg.Image)
+	out, img := alg.Verified("222", puzzle.MaskAll).Data(c.eg.Image)
 	return out, img, nil
 }
 
@@ -184,6 +185,6 @@ func (c *Cube222) selectTCll(selectInput []string, config interface{}) (output s
 		return fmt.Sprintf("找不到该case: %s", key), "", nil
 	}
 
-	out, img := alg.Data(c.tCll.Image)
+	out, img := alg.Verified("222", puzzle.MaskAll).Data(c.tCll.Image)
 	return out, img, nil
 }
@@ -5,6 +5,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/guojia99/cubing-pro/src/internel/scramble/puzzle"
 	"github.com/guojia99/cubing-pro/src/internel/utils"
 )
 
@@ -97,17 +98,19 @@ func (c *Cube333) selectPll(selectInput []string, config interface{}) (output st
 	if pll.Name == "" || ohPll.Name == "" {
 		return caseHelp, "", nil
 	}
+	pll = c.pll.Alg[pll.key()].Verified("333", puzzle.MaskAll)
+	ohPll = c.ohPll.Alg[ohPll.key()].Verified("333", puzzle.MaskAll)
 
 	out := fmt.Sprintf("PLL case %s\n", pll.Name)
 	out += fmt.Sprintf("打乱:%s\n", pll.Scramble)
 
 	out += "双手公式\n"
 	for idx, v := range pll.Alg {
-		out += fmt.Sprintf("%d. %s\n", idx+1, v)
+		out += fmt.Sprintf("%d. %s\n", idx+1, pll.algText(v))
 	}
 	out += "单手公式\n"
 	for idx, v := range ohPll.Alg {
-		out += fmt.Sprintf("%d. %s\n", idx+1, v)
+		out += fmt.Sprintf("%d. %s\n", idx+1, ohPll.algText(v))
 	}
 
 	_, img := pll.Data(c.pll.Image)
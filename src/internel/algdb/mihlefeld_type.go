@@ -3,9 +3,11 @@ package algdb
 import (
 	"fmt"
 	"path"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/guojia99/cubing-pro/src/internel/scramble/puzzle"
 	"github.com/guojia99/cubing-pro/src/internel/utils"
 )
 
@@ -21,6 +23,8 @@ type (
 		Group    string   `json:"group"`
 		Set      string   `json:"algset"`
 		Scramble string   `json:"s"`
+
+		Invalid []string `json:"-"` // 校验时无法还原打乱的公式
 	}
 
 	Cube struct {
@@ -49,7 +53,7 @@ func (c Cube) ToCubeAlgDb() CubeAlgDb {
 	}
 
 	for k, v := range c.AlgInfos {
-		key := fmt.Sprintf("%s_%s_%s", strings.ToLower(v.Set), strings.ToLower(v.Group), strings.ToLower(v.Name)) // CLL S3
+		key := v.key()
 		//fmt.Println(key)
 		out.Alg[key] = v
 		out.Image[key] = c.Images[k]
@@ -74,15 +78,66 @@ func (c Cube) CaseList() string {
 	return out
 }
 
+// Verify 校验每个情况的公式能否还原其打乱, 未通过的公式记录到 Invalid
+func (c CubeAlgDb) Verify(ev string, mask puzzle.CaseMask) {
+	for key, alg := range c.Alg {
+		c.Alg[key] = alg.Verified(ev, mask)
+	}
+}
+
+// Verified 查询时只校验单个情况, 避免启动时校验全部公式
+func (a CubeAlg) Verified(ev string, mask puzzle.CaseMask) CubeAlg {
+	a.Invalid = a.invalidAlgs(ev, mask)
+	return a
+}
+
+// invalidAlgs 数据中的打乱有的是情况的打乱, 有的是还原公式, 两个方向任意一个能还原即通过
+func (a CubeAlg) invalidAlgs(ev string, mask puzzle.CaseMask) []string {
+	if a.Scramble == "" {
+		return nil
+	}
+	setups := []string{a.Scramble}
+	if inverse, err := puzzle.Invert(ev, a.Scramble); err == nil {
+		setups = append(setups, inverse)
+	}
+
+	var out []string
+	for _, alg := range a.Alg {
+		solved := false
+		for _, setup := range setups {
+			if ok, err := puzzle.SolvesCase(ev, setup, alg, mask); err == nil && ok {
+				solved = true
+				break
+			}
+		}
+		if !solved {
+			out = append(out, alg)
+		}
+	}
+	return out
+}
+
+// key Set_Group_Name 例如 cll_s_s3
+func (a CubeAlg) key() string {
+	return fmt.Sprintf("%s_%s_%s", strings.ToLower(a.Set), strings.ToLower(a.Group), strings.ToLower(a.Name))
+}
+
+// algText 未通过校验的公式加上提示
+func (a CubeAlg) algText(alg string) string {
+	if slices.Contains(a.Invalid, alg) {
+		return alg + " (校验未通过)"
+	}
+	return alg
+}
+
 func (a CubeAlg) Data(image map[string]string) (string, string) {
 	out := fmt.Sprintf("公式: %s - %s\n", a.Set, a.Name)
 	out += fmt.Sprintf("打乱: %s\n", a.Scramble)
 	out += "---------------\n"
 	for idx, val := range a.Alg {
-		out += fmt.Sprintf("%d. %s\n", idx+1, val)
+		out += fmt.Sprintf("%d. %s\n", idx+1, a.algText(val))
 	}
-	key := fmt.Sprintf("%s_%s_%s", strings.ToLower(a.Set), strings.ToLower(a.Group), strings.ToLower(a.Name)) // CLL S3
-	svgImg, ok := image[key]
+	svgImg, ok := image[a.key()]
 	if !ok {
 		return out, ""
 	}
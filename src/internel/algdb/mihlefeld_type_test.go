@@ -0,0 +1,38 @@
+package algdb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/guojia99/cubing-pro/src/internel/scramble/puzzle"
+)
+
+func TestCubeAlgDb_Verify(t *testing.T) {
+	db := Cube{
+		AlgInfos: map[string]CubeAlg{
+			"1": {
+				Name: "T", Set: "PLL", Group: "T",
+				Scramble: "R U R' U' R' F R2 U' R' U' R U R' F'",
+				Alg:      []string{"R U R' U' R' F R2 U' R' U' R U R' F'", "R U R' U R U2 R'"},
+			},
+			// 打乱为还原公式的写法
+			"2": {
+				Name: "Ja", Set: "PLL", Group: "J",
+				Scramble: "x R2 F R F' R U2 r' U r U2",
+				Alg:      []string{"(x) R2 F R F' R U2 r' U r U2"},
+			},
+		},
+	}.ToCubeAlgDb()
+	db.Verify("333", puzzle.MaskAll)
+
+	tAlg := db.Alg["pll_t_t"]
+	if len(tAlg.Invalid) != 1 || tAlg.Invalid[0] != "R U R' U R U2 R'" {
+		t.Errorf("T Invalid = %v", tAlg.Invalid)
+	}
+	if out, _ := tAlg.Data(nil); !strings.Contains(out, "R U R' U R U2 R' (校验未通过)") {
+		t.Errorf("Data() = %s", out)
+	}
+	if ja := db.Alg["pll_j_ja"]; len(ja.Invalid) != 0 {
+		t.Errorf("Ja Invalid = %v", ja.Invalid)
+	}
+}
@@ -5,14 +5,27 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"sync"
 )
 
 var baseAlgs = make(map[string]CubeAlgorithms)
 
+// trainerData 训练器原始数据, 公式校验耗时较长, 查询时才执行
+type trainerData struct {
+	fileKey string
+	cfg     *AlgorithmConfigWithTrainer
+
+	once  sync.Once
+	class AlgorithmClass
+}
+
+var trainers = make(map[string]*trainerData) // key: cube/class
+
 func Init(basePath string) error {
 	baseAlgs = make(map[string]CubeAlgorithms)
+	trainers = make(map[string]*trainerData)
 	for key, subKeys := range algsDataKey {
-		cube := builderCubeAlgorithms(basePath, subKeys)
+		cube := builderCubeAlgorithms(basePath, key, subKeys)
 		cube.Cube = key
 		baseAlgs[key] = cube
 	}
@@ -23,7 +36,20 @@ func GetAlgorithms() map[string]CubeAlgorithms {
 	return baseAlgs
 }
 
-func builderCubeAlgorithms(basePath string, keys []string) CubeAlgorithms {
+// GetVerifiedAlgorithmClass 带校验结果的公式集合, 每个集合首次查询时校验
+func GetVerifiedAlgorithmClass(cube, class string) (AlgorithmClass, bool) {
+	t, ok := trainers[cube+"/"+class]
+	if !ok {
+		return AlgorithmClass{}, false
+	}
+	t.once.Do(func() {
+		invalid, _ := VerifyTrainer(t.fileKey, t.cfg)
+		t.class = *fileAlgToAlgorithmClass(t.fileKey, t.cfg, invalid)
+	})
+	return t.class, true
+}
+
+func builderCubeAlgorithms(basePath string, cube string, keys []string) CubeAlgorithms {
 
 	out := CubeAlgorithms{
 		ClassKeys: make([]string, 0),
@@ -37,8 +63,9 @@ func builderCubeAlgorithms(basePath string, keys []string) CubeAlgorithms {
 			continue
 		}
 
-		out.ClassList = append(out.ClassList, *fileAlgToAlgorithmClass(key, fileAlgs))
+		out.ClassList = append(out.ClassList, *fileAlgToAlgorithmClass(key, fileAlgs, nil))
 		out.ClassKeys = append(out.ClassKeys, algsNameMap[key])
+		trainers[cube+"/"+algsNameMap[key]] = &trainerData{fileKey: key, cfg: fileAlgs}
 	}
 	return out
 }
@@ -50,8 +77,8 @@ func bestScrambles(in []string) []string {
 	return in
 }
 
-// 例如EG为一个class
-func fileAlgToAlgorithmClass(fileKey string, fileAlg *AlgorithmConfigWithTrainer) *AlgorithmClass {
+// 例如EG为一个class, invalid 为校验未通过的公式
+func fileAlgToAlgorithmClass(fileKey string, fileAlg *AlgorithmConfigWithTrainer, invalid map[string][]string) *AlgorithmClass {
 	out := &AlgorithmClass{
 		Name:    algsNameMap[fileKey],
 		Sets:    make([]AlgorithmSet, 0),
@@ -93,6 +120,8 @@ func fileAlgToAlgorithmClass(fileKey string, fileAlg *AlgorithmConfigWithTrainer
 					Algs:      alg.Algs,
 					Image:     fileAlg.Images[algKey],
 					Scrambles: bestScrambles(fileAlg.Scrambles[algKey]),
+
+					InvalidAlgs: invalid[algKey],
 				}
 				group.Algorithms = append(group.Algorithms, algorithm)
 			}
@@ -6,6 +6,8 @@ type Algorithm struct {
 	Algs      []string `json:"algs"`
 	Image     string   `json:"image"` // svg
 	Scrambles []string `json:"scrambles"`
+
+	InvalidAlgs []string `json:"invalidAlgs,omitempty"` // 校验时无法还原打乱的公式
 }
 
 // AlgorithmGroup 一个大类里面的分组, 如EG1-H
@@ -0,0 +1,111 @@
+package algs
+
+import (
+	"sort"
+
+	"github.com/guojia99/cubing-pro/src/internel/scramble/puzzle"
+)
+
+type trainerVerifyRule struct {
+	ev   string
+	mask puzzle.CaseMask
+}
+
+// trainerVerifyRules 可以校验的训练器, 以及公式执行后需要还原的部分
+var trainerVerifyRules = map[string]trainerVerifyRule{
+	"2x2-OLL-Trainer":  {ev: "222", mask: puzzle.MaskUD},
+	"2x2-PBL-Trainer":  {ev: "222", mask: puzzle.MaskAll},
+	"2x2-EG-Trainer":   {ev: "222", mask: puzzle.MaskAll},
+	"2x2-TCLL-Trainer": {ev: "222", mask: puzzle.MaskAll},
+	"2x2-TEG-Trainer":  {ev: "222", mask: puzzle.MaskAll},
+
+	"3x3-F2L-Trainer":  {ev: "333", mask: puzzle.MaskF2L},
+	"3x3-ZBLS-Trainer": {ev: "333", mask: puzzle.MaskF2L},
+	"3x3-OLL-Trainer":  {ev: "333", mask: puzzle.MaskOLL},
+	"3x3-PLL-Trainer":  {ev: "333", mask: puzzle.MaskAll},
+	"3x3-ZBLL-Trainer": {ev: "333", mask: puzzle.MaskAll},
+	"3x3-COLL-Trainer": {ev: "333", mask: puzzle.MaskLLCorners},
+	"3x3-CMLL-Trainer": {ev: "333", mask: puzzle.MaskCMLL},
+
+	"3x3-OH-CMLL-Trainer": {ev: "333", mask: puzzle.MaskCMLL},
+	"3x3-OH-OLL-Trainer":  {ev: "333", mask: puzzle.MaskOLL},
+	"3x3-OH-PLL-Trainer":  {ev: "333", mask: puzzle.MaskAll},
+	"3x3-OH-ZBLL-Trainer": {ev: "333", mask: puzzle.MaskAll},
+
+	"4x4-PLLP-Trainer": {ev: "444", mask: puzzle.MaskAll},
+}
+
+// verifySampleNum 判断打乱方向时抽样的公式数量
+const verifySampleNum = 20
+
+// VerifyTrainer 校验训练器中的公式能否还原其打乱对应的情况, 返回每个公式编号下未通过的公式
+// 训练器的打乱有的是情况的打乱, 有的直接给出还原公式, 抽样两种方向, 取通过数量多的一种
+// 不支持校验的训练器返回 false
+func VerifyTrainer(trainerKey string, cfg *AlgorithmConfigWithTrainer) (map[string][]string, bool) {
+	rule, ok := trainerVerifyRules[trainerKey]
+	if !ok {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(cfg.AlgsInfo))
+	for key := range cfg.AlgsInfo {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var direct, inverse int
+	for i := 0; i < len(keys) && i < verifySampleNum; i++ {
+		alg := cfg.AlgsInfo[keys[i]]
+		if len(alg.Algs) == 0 {
+			continue
+		}
+		setups := trainerSetups(cfg, keys[i])
+		if rule.solves(setups, alg.Algs[0], false) {
+			direct++
+		}
+		if rule.solves(setups, alg.Algs[0], true) {
+			inverse++
+		}
+	}
+	useInverse := inverse > direct
+
+	out := make(map[string][]string)
+	for _, key := range keys {
+		setups := trainerSetups(cfg, key)
+		if len(setups) == 0 {
+			continue
+		}
+		for _, alg := range cfg.AlgsInfo[key].Algs {
+			if !rule.solves(setups, alg, useInverse) {
+				out[key] = append(out[key], alg)
+			}
+		}
+	}
+	return out, true
+}
+
+func trainerSetups(cfg *AlgorithmConfigWithTrainer, key string) []string {
+	var out []string
+	for _, s := range append(cfg.Scrambles[key], cfg.AlgsInfo[key].Scramble) {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// solves 任意一个打乱下公式能够还原即通过
+func (r trainerVerifyRule) solves(setups []string, alg string, inverse bool) bool {
+	for _, setup := range setups {
+		if inverse {
+			var err error
+			if setup, err = puzzle.Invert(r.ev, setup); err != nil {
+				continue
+			}
+		}
+		if ok, err := puzzle.SolvesCase(r.ev, setup, alg, r.mask); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
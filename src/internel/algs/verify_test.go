@@ -0,0 +1,71 @@
+package algs
+
+import (
+	"testing"
+)
+
+func TestVerifyTrainer(t *testing.T) {
+	tests := []struct {
+		trainer string
+	}{
+		{trainer: "2x2-PBL-Trainer"},
+		{trainer: "3x3-COLL-Trainer"},
+		{trainer: "3x3-F2L-Trainer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.trainer, func(t *testing.T) {
+			cfg, err := ReadTrainerFiles("speeddb/" + tt.trainer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := VerifyTrainer(tt.trainer, cfg)
+			if !ok {
+				t.Fatal("应支持校验")
+			}
+			for key, algs := range got {
+				t.Errorf("%s %s 未通过: %v", tt.trainer, key, algs)
+			}
+		})
+	}
+
+	t.Run("损坏的公式", func(t *testing.T) {
+		cfg := &AlgorithmConfigWithTrainer{
+			AlgsInfo: map[string]TrainerAlgorithm{
+				"1": {Algs: []string{"R U R' U R U2 R'", "R U R' U' R' F R F'"}, Scramble: "R U2 R' U' R U' R'"},
+				"2": {Algs: []string{"R U R' U' R' F R2 U' R' U' R U R' F'", "R U R' U' R' F R2 U' R' U' R U R' Q'"}, Scramble: "R U R' U' R' F R2 U' R' U' R U R' F'"},
+			},
+		}
+		got, _ := VerifyTrainer("3x3-OLL-Trainer", cfg)
+		if len(got["1"]) != 1 || got["1"][0] != "R U R' U' R' F R F'" {
+			t.Errorf("1 = %v", got["1"])
+		}
+		if len(got["2"]) != 1 {
+			t.Errorf("2 = %v", got["2"])
+		}
+	})
+
+	if _, ok := VerifyTrainer("SQ1-CS-Trainer", &AlgorithmConfigWithTrainer{}); ok {
+		t.Error("SQ1 不支持校验")
+	}
+}
+
+func TestGetVerifiedAlgorithmClass(t *testing.T) {
+	if err := Init("speeddb"); err != nil {
+		t.Fatal(err)
+	}
+	for _, class := range GetAlgorithms()["222"].ClassList {
+		for _, alg := range class.Sets[0].AlgorithmGroups[0].Algorithms {
+			if alg.InvalidAlgs != nil {
+				t.Fatalf("Init 不应校验公式, %s %v", class.Name, alg.InvalidAlgs)
+			}
+		}
+	}
+
+	class, ok := GetVerifiedAlgorithmClass("222", "PBL")
+	if !ok || len(class.Sets) == 0 {
+		t.Fatalf("PBL = %v, %v", class.Name, ok)
+	}
+	if _, ok = GetVerifiedAlgorithmClass("222", "not-exist"); ok {
+		t.Error("不存在的集合")
+	}
+}
@@ -37,12 +37,7 @@ func Models() []interface{} {
 }
 
 func NewConvenient(db *gorm.DB, runJob bool, config configs.Config) ConvenientI {
-	wcaClient := wca.NewWCA(
-		config.GlobalConfig.WcaDB.MysqlUrl,
-		config.GlobalConfig.WcaDB.DbPath,
-		config.GlobalConfig.WcaDB.SyncPath,
-		false,
-	)
+	wcaClient := wca.NewWCAWithConfig(config.GlobalConfig.WcaDB, false)
 
 	_ = db.AutoMigrate()
 	_ = db.AutoMigrate(&user.User{})       // 用户表
@@ -70,6 +65,8 @@ func NewConvenient(db *gorm.DB, runJob bool, config configs.Config) ConvenientI
 	_ = db.AutoMigrate(&competition.Registration{})                // 比赛注册表
 	_ = db.AutoMigrate(&competition.AssCompetitionSponsorsUsers{}) // 比赛相关主办代表关联表
 	_ = db.AutoMigrate(&competition.CompetitionGroup{})            // 比赛群组表
+	_ = db.AutoMigrate(&competition.SealedScramble{})              // 密封打乱表
+	_ = db.AutoMigrate(&competition.ScrambleViewLog{})             // 打乱查看记录表
 
 	// 爬虫表
 	_ = db.AutoMigrate(&crawler.SendEmail{})
@@ -93,6 +90,7 @@ func NewConvenient(db *gorm.DB, runJob bool, config configs.Config) ConvenientI
 		{JobI: &job.RecordUpdateJob{DB: db}, Time: time.Minute * 30},
 		{JobI: &job.UpdateDiyRankings{DB: db, Wca: wcaClient}, Time: time.Minute * 15},
 		{JobI: &job.UpdateCubingChinaComps{DB: db}, Time: time.Hour * 24},
+		{JobI: &job.PreResultExpireJob{DB: db, Cfg: config.GlobalConfig}, Time: time.Minute * 5},
 	}
 
 	var runJobs []job.Job
@@ -138,7 +136,12 @@ func NewConvenient(db *gorm.DB, runJob bool, config configs.Config) ConvenientI
 		CompetitionIter: _interface.CompetitionIter{DB: db},
 		UserIter:        _interface.UserIter{DB: db},
 		ResultIter:      _interface.ResultIter{DB: db, Cache: cache},
-		Jobs:            runJobs,
+		PreResultIter:   _interface.PreResultIter{DB: db, Cfg: config.GlobalConfig},
+		ScrambleSealIter: _interface.ScrambleSealIter{
+			DB:  db,
+			Key: config.GlobalConfig.Scramble.SealKey,
+		},
+		Jobs: runJobs,
 	}
 	if runJob {
 		out.Jobs.RunLoop(context.Background())
@@ -153,6 +156,8 @@ type convenient struct {
 	_interface.CompetitionIter
 	_interface.UserIter
 	_interface.ResultIter
+	_interface.PreResultIter
+	_interface.ScrambleSealIter
 
 	job.Jobs
 }
@@ -168,5 +173,7 @@ type ConvenientI interface {
 	_interface.CompetitionI
 	_interface.UserI
 	_interface.ResultI
+	_interface.PreResultI
+	_interface.ScrambleSealI
 	_interface.WCAResultI
 }
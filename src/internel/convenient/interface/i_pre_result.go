@@ -0,0 +1,443 @@
+package _interface
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/guojia99/cubing-pro/src/configs"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/user"
+	"github.com/guojia99/cubing-pro/src/internel/email"
+	"github.com/guojia99/cubing-pro/src/internel/utils"
+)
+
+const (
+	PreResultAutoProcessor   = "auto"   // 自动审核处理人
+	PreResultSystemProcessor = "system" // 系统过期处理人
+)
+
+const preResultExpireBatch = 500 // 过期检查每批读取的未处理成绩数
+
+type PreResultI interface {
+	ApprovalPreResult(comp competition.Competition, pre *result.PreResults, detail string, processor user.User) error             // 审核单个预录入成绩
+	ApprovalPreResults(comp competition.Competition, ids []uint, detail string, processor user.User) ([]result.PreResults, error) // 批量审核, 同一事务内完成
+	AutoApprovalPreResult(comp competition.Competition, pre *result.PreResults) (bool, error)                                     // 按比赛规则自动审核
+	ExpirePreResults() (int, error)                                                                                               // 轮次关闭后过期未处理成绩
+	NotifyPreResultSubmitters(comp competition.Competition, pres []result.PreResults)                                             // 邮件通知提交人审核结果
+}
+
+type PreResultIter struct {
+	DB  *gorm.DB
+	Cfg configs.GlobalConfig // 邮件通知使用
+}
+
+type CompResultInput struct {
+	Results  []float64
+	CubeID   string
+	RoundNum int
+	EventID  string
+	Penalty  result.Penalty
+
+	FmSolutions []result.FmSolution // 最少步的解法, 可为空
+}
+
+// SaveCompResult 校验并写入选手的比赛成绩, tx 可以是事务
+//  1. 确认比赛是否存在和是否符合比赛要求和项目是否存在
+//  2. 线上赛的情况下， 自动注册, 并添加对应的项目
+//  3. 其他赛的情况下， 不注册
+func SaveCompResult(tx *gorm.DB, comp competition.Competition, in CompResultInput) (res result.Results, err error) {
+	// 1. 校验轮次信息
+	if !comp.IsRunningTime() {
+		err = errors.New("不在比赛时间")
+		return
+	}
+	events := comp.EventMap()
+	if _, ok := events[in.EventID]; !ok {
+		return result.Results{}, fmt.Errorf("比赛项目不存在")
+	}
+	ev := events[in.EventID]
+	schedule, err := ev.CurRunningSchedule(in.RoundNum, nil)
+	if err != nil {
+		return result.Results{}, err
+	}
+
+	// 2. 获取注册信息
+	var usr user.User
+	if err = tx.First(&usr, "cube_id = ?", in.CubeID).Error; err != nil {
+		return
+	}
+	var reg competition.Registration
+	err = tx.First(&reg, "comp_id = ? and user_id = ?", comp.ID, usr.ID).Error
+
+	switch comp.Genre {
+	case competition.OnlineInformal:
+		if err != nil {
+			reg = competition.Registration{
+				CompID:           comp.ID,
+				CompName:         comp.Name,
+				UserID:           usr.ID,
+				UserName:         usr.Name,
+				Status:           competition.RegisterStatusPass,
+				RegistrationTime: time.Now(),
+				AcceptationTime:  utils.PtrTime(time.Now()),
+				RetireTime:       nil,
+			}
+		}
+		reg.SetEvent(in.EventID)
+		if err = tx.Save(&reg).Error; err != nil {
+			return
+		}
+	default:
+		if err != nil {
+			return
+		}
+		if !slices.Contains(reg.EventsList(), in.EventID) {
+			err = fmt.Errorf("该选手未报名该项目%s", in.EventID)
+			return
+		}
+		if reg.Status != competition.RegisterStatusPass {
+			err = errors.New("该选手比赛资格未审核")
+			return
+		}
+		if !schedule.FirstRound && !slices.Contains(schedule.AdvancedToThisRound, usr.ID) {
+			err = errors.New("不在晋级名单中")
+			return
+		}
+	}
+
+	// 检查上一把是否有成绩
+	var lastRes result.Results
+	if schedule.RoundNum != 1 {
+		err = tx.Where("user_id = ?", usr.ID).Where("comp_id = ?", comp.ID).
+			Where("event_id = ?", ev.EventID).Where("round_number = ?", schedule.RoundNum-1).First(&lastRes).Error
+		if err != nil || lastRes.ID == 0 {
+			err = errors.New("上轮无成绩无法录入")
+			return
+		}
+	}
+
+	in.Results = result.UpdateOrgResult(in.Results, ev.EventRoute, schedule.Cutoff, schedule.CutoffNumber, schedule.TimeLimit)
+	err = tx.Where("user_id = ?", usr.ID).
+		Where("comp_id = ?", comp.ID).
+		Where("event_id = ?", ev.EventID).
+		Where("round_number = ?", schedule.RoundNum).
+		First(&res).Error
+
+	if err != nil || res.ID == 0 {
+		res = result.Results{
+			CompetitionID:   comp.ID,
+			CompetitionName: comp.Name,
+			Round:           schedule.Round,
+			RoundNumber:     schedule.RoundNum,
+			PersonName:      usr.Name,
+			UserID:          usr.ID,
+			CubeID:          usr.CubeID,
+			EventID:         ev.EventID,
+			EventName:       ev.EventName,
+			EventRoute:      ev.EventRoute,
+			Ban:             false,
+			Rank:            0,
+		}
+	}
+	res.Result = in.Results
+	res.Penalty = in.Penalty
+	res.FmSolutions = in.FmSolutions
+	if err = res.Update(); err != nil {
+		return
+	}
+
+	err = tx.Save(&res).Error
+	return
+}
+
+// PreResultSchedule 获取预录入成绩所在的轮次, 网页录入的只有轮次名, 机器人录入的有轮次号
+func PreResultSchedule(comp competition.Competition, pre result.PreResults) (competition.Schedule, bool) {
+	ev, ok := comp.EventMap()[pre.EventID]
+	if !ok {
+		return competition.Schedule{}, false
+	}
+	for _, schedule := range ev.Schedule {
+		if pre.RoundNumber != 0 && schedule.RoundNum == pre.RoundNumber {
+			return schedule, true
+		}
+		if pre.RoundNumber == 0 && (schedule.Round == pre.Round || schedule.Round == pre.RoundName) {
+			return schedule, true
+		}
+	}
+	return competition.Schedule{}, false
+}
+
+// CheckPreResultRule 按比赛规则判断预录入成绩能否自动通过, 返回判断的原因
+// - 信任选手直接通过
+// - 单次与平均都未快过个人最佳的 N% 时通过, 无个人最佳时需人工审核
+func CheckPreResultRule(rule competition.PreResultRule, pre result.Results, best PlayerBestResult) (bool, string) {
+	if !rule.AutoApproval {
+		return false, "未开启自动审核"
+	}
+	if rule.IsTrusted(pre.CubeID) {
+		return true, "信任选手"
+	}
+	if rule.WithinPBPercent <= 0 {
+		return false, "无匹配的自动审核规则"
+	}
+	if pre.EventRoute.RouteMap().Repeatedly {
+		return false, "多次尝试项目需人工审核"
+	}
+
+	limit := 1 - rule.WithinPBPercent/100
+	if !pre.DBest() {
+		pb, ok := best.Single[pre.EventID]
+		if !ok {
+			return false, "无历史单次成绩"
+		}
+		if pre.Best < pb.Best*limit {
+			return false, fmt.Sprintf("单次快于个人最佳%v%%以上", rule.WithinPBPercent)
+		}
+	}
+	if !pre.DAvg() && pre.Average > 0 {
+		pb, ok := best.Avgs[pre.EventID]
+		if !ok {
+			return false, "无历史平均成绩"
+		}
+		if pre.Average < pb.Average*limit {
+			return false, fmt.Sprintf("平均快于个人最佳%v%%以上", rule.WithinPBPercent)
+		}
+	}
+	return true, fmt.Sprintf("成绩在个人最佳%v%%以内", rule.WithinPBPercent)
+}
+
+func (c *PreResultIter) approvalPreResult(tx *gorm.DB, comp competition.Competition, pre *result.PreResults, detail string, processor string, processorID uint) error {
+	if pre.Finish {
+		return fmt.Errorf("成绩%d已被处理，请不要反复处理", pre.ID)
+	}
+
+	pre.Processor = processor
+	pre.ProcessorID = processorID
+	pre.Detail = detail
+	if detail == result.DetailOk || detail == result.DetailNot {
+		pre.FinishDetail = detail
+		pre.Finish = true
+	}
+
+	if detail == result.DetailOk {
+		schedule, ok := PreResultSchedule(comp, *pre)
+		if !ok {
+			return fmt.Errorf("成绩%d的轮次不存在", pre.ID)
+		}
+		res, err := SaveCompResult(
+			tx, comp, CompResultInput{
+				Results:  pre.Result,
+				CubeID:   pre.CubeID,
+				RoundNum: schedule.RoundNum,
+				EventID:  pre.EventID,
+				Penalty:  pre.Penalty,
+
+				FmSolutions: pre.FmSolutions,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		pre.ResultID = &res.ID
+	}
+	return tx.Save(pre).Error
+}
+
+func (c *PreResultIter) ApprovalPreResult(comp competition.Competition, pre *result.PreResults, detail string, processor user.User) error {
+	return c.DB.Transaction(func(tx *gorm.DB) error {
+		return c.approvalPreResult(tx, comp, pre, detail, processor.Name, processor.ID)
+	})
+}
+
+func (c *PreResultIter) ApprovalPreResults(comp competition.Competition, ids []uint, detail string, processor user.User) ([]result.PreResults, error) {
+	if detail != result.DetailOk && detail != result.DetailNot {
+		return nil, fmt.Errorf("批量审核仅支持 `%s` 或 `%s`", result.DetailOk, result.DetailNot)
+	}
+	ids = utils.RemoveRepeatedElement(ids)
+	if len(ids) == 0 {
+		return nil, errors.New("未选择需要审核的成绩")
+	}
+
+	var pres []result.PreResults
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("comp_id = ?", comp.ID).Where("id in ?", ids).Order("id").Find(&pres).Error; err != nil {
+			return err
+		}
+		if len(pres) != len(ids) {
+			return errors.New("部分预录入成绩不存在或不属于本场比赛")
+		}
+		for i := range pres {
+			if err := c.approvalPreResult(tx, comp, &pres[i], detail, processor.Name, processor.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pres, nil
+}
+
+func (c *PreResultIter) AutoApprovalPreResult(comp competition.Competition, pre *result.PreResults) (bool, error) {
+	rule := comp.CompJSON.PreResultRule
+	if !rule.AutoApproval || pre.Finish {
+		return false, nil
+	}
+
+	ri := ResultIter{DB: c.DB}
+	best, err := ri.PlayerBestResult(pre.UserID, []string{pre.EventID}, nil)
+	if err != nil {
+		return false, err
+	}
+	ok, reason := CheckPreResultRule(rule, pre.Results, best)
+	if !ok {
+		return false, nil
+	}
+
+	// 审核失败时不改动原有的预录入数据
+	approved := *pre
+	processor := fmt.Sprintf("%s(%s)", PreResultAutoProcessor, reason)
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		return c.approvalPreResult(tx, comp, &approved, result.DetailOk, processor, 0)
+	})
+	if err != nil {
+		return false, err
+	}
+	*pre = approved
+	go c.NotifyPreResultSubmitters(comp, []result.PreResults{approved})
+	return true, nil
+}
+
+func (c *PreResultIter) ExpirePreResults() (int, error) {
+	// 自动过期的开关在比赛规则中, 先找出有未处理成绩且开启了自动过期的比赛
+	var compIds []uint
+	if err := c.DB.Model(&result.PreResults{}).Where("finish = ?", false).Distinct("comp_id").Pluck("comp_id", &compIds).Error; err != nil {
+		return 0, err
+	}
+	if len(compIds) == 0 {
+		return 0, nil
+	}
+	var comps []competition.Competition
+	if err := c.DB.Where("id in ?", compIds).Find(&comps).Error; err != nil {
+		return 0, err
+	}
+	var compMap = make(map[uint]competition.Competition)
+	var autoCompIds []uint
+	for _, comp := range comps {
+		if !comp.CompJSON.PreResultRule.AutoExpire {
+			continue
+		}
+		compMap[comp.ID] = comp
+		autoCompIds = append(autoCompIds, comp.ID)
+	}
+	if len(autoCompIds) == 0 {
+		return 0, nil
+	}
+
+	num := 0
+	now := time.Now()
+	expired := make(map[uint][]result.PreResults)
+	defer func() {
+		for compId, list := range expired {
+			go c.NotifyPreResultSubmitters(compMap[compId], list)
+		}
+	}()
+
+	// 按 id 分批遍历, 轮次未关闭的成绩不会挡住后面的成绩
+	var lastId uint
+	for {
+		var pres []result.PreResults
+		err := c.DB.Where("finish = ?", false).Where("comp_id in ?", autoCompIds).
+			Where("id > ?", lastId).Order("id").Limit(preResultExpireBatch).Find(&pres).Error
+		if err != nil {
+			return num, err
+		}
+		if len(pres) == 0 {
+			return num, nil
+		}
+		lastId = pres[len(pres)-1].ID
+
+		for _, pre := range pres {
+			comp := compMap[pre.CompetitionID]
+			if !preResultExpired(comp, pre, now) {
+				continue
+			}
+
+			pre.Processor = PreResultSystemProcessor
+			pre.Detail = result.DetailTimeout
+			pre.FinishDetail = result.DetailTimeout
+			pre.Finish = true
+			if err = c.DB.Save(&pre).Error; err != nil {
+				return num, err
+			}
+			expired[comp.ID] = append(expired[comp.ID], pre)
+			num++
+		}
+	}
+}
+
+// preResultExpired 比赛结束, 或成绩所在轮次关闭超过 ExpireAfterMinutes 分钟
+func preResultExpired(comp competition.Competition, pre result.PreResults, now time.Time) bool {
+	if comp.IsDone {
+		return true
+	}
+	schedule, ok := PreResultSchedule(comp, pre)
+	if !ok || schedule.IsRunning || schedule.ActualEndTime.IsZero() {
+		return false
+	}
+	after := time.Duration(comp.CompJSON.PreResultRule.ExpireAfterMinutes) * time.Minute
+	return !now.Before(schedule.ActualEndTime.Add(after))
+}
+
+const preResultNotifyMsgT = "你在比赛《%s》提交的成绩已审核完成:\n%s"
+
+// NotifyPreResultSubmitters 邮件通知提交人预录入成绩的审核结果, 未处理完成的成绩不通知
+func (c *PreResultIter) NotifyPreResultSubmitters(comp competition.Competition, pres []result.PreResults) {
+	var msgs = make(map[uint]string)
+	var userIds []uint
+	for _, pre := range pres {
+		if !pre.Finish {
+			continue
+		}
+		if _, ok := msgs[pre.UserID]; !ok {
+			userIds = append(userIds, pre.UserID)
+		}
+		status := "未通过"
+		switch pre.FinishDetail {
+		case result.DetailOk:
+			status = "通过"
+		case result.DetailTimeout:
+			status = "已过期"
+		}
+		msgs[pre.UserID] += fmt.Sprintf("%s %s (%s / %s) %s\n", pre.EventName, pre.RoundName, pre.BestString(), pre.BestAvgString(), status)
+	}
+	if len(userIds) == 0 {
+		return
+	}
+
+	var users []user.User
+	c.DB.Where("id in ?", userIds).Find(&users)
+
+	notifyUrl, _ := url.JoinPath(c.Cfg.BaseHost, "/competition", fmt.Sprint(comp.ID))
+	for _, usr := range users {
+		if usr.Email == "" {
+			continue
+		}
+		data := email.CodeTempData{
+			Subject:   "预录入成绩审核结果",
+			UserName:  usr.Name,
+			BaseUrl:   c.Cfg.BaseHost,
+			Notify:    "查看比赛",
+			NotifyMsg: fmt.Sprintf(preResultNotifyMsgT, comp.Name, msgs[usr.ID]),
+			NotifyUrl: notifyUrl,
+		}
+		_ = email.SendEmailWithTemp(c.Cfg.EmailConfig, data.Subject, []string{usr.Email}, email.CodeTemp, data)
+	}
+}
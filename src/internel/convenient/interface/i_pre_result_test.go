@@ -0,0 +1,150 @@
+package _interface
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	basemodel "github.com/guojia99/cubing-pro/src/internel/database/model/base"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/user"
+)
+
+func TestCheckPreResultRule(t *testing.T) {
+	best := PlayerBestResult{
+		Single: map[EventID]result.Results{"333": {EventID: "333", Best: 10}},
+		Avgs:   map[EventID]result.Results{"333": {EventID: "333", Average: 12}},
+	}
+	rule := competition.PreResultRule{
+		AutoApproval:    true,
+		TrustedPlayers:  []string{"2020TEST01"},
+		WithinPBPercent: 10,
+	}
+
+	tests := []struct {
+		name string
+		rule competition.PreResultRule
+		pre  result.Results
+		want bool
+	}{
+		{
+			name: "未开启",
+			rule: competition.PreResultRule{TrustedPlayers: []string{"2020TEST01"}},
+			pre:  result.Results{CubeID: "2020TEST01", EventID: "333", EventRoute: event.RouteType5RoundsAvgHT, Best: 11, Average: 13},
+			want: false,
+		},
+		{
+			name: "信任选手",
+			rule: rule,
+			pre:  result.Results{CubeID: "2020TEST01", EventID: "333", EventRoute: event.RouteType5RoundsAvgHT, Best: 1, Average: 2},
+			want: true,
+		},
+		{
+			name: "个人最佳以内",
+			rule: rule,
+			pre:  result.Results{CubeID: "2021TEST01", EventID: "333", EventRoute: event.RouteType5RoundsAvgHT, Best: 9.5, Average: 11},
+			want: true,
+		},
+		{
+			name: "单次过快",
+			rule: rule,
+			pre:  result.Results{CubeID: "2021TEST01", EventID: "333", EventRoute: event.RouteType5RoundsAvgHT, Best: 8, Average: 11},
+			want: false,
+		},
+		{
+			name: "平均过快",
+			rule: rule,
+			pre:  result.Results{CubeID: "2021TEST01", EventID: "333", EventRoute: event.RouteType5RoundsAvgHT, Best: 10, Average: 10},
+			want: false,
+		},
+		{
+			name: "DNF不参与比较",
+			rule: rule,
+			pre:  result.Results{CubeID: "2021TEST01", EventID: "333", EventRoute: event.RouteType5RoundsAvgHT, Best: 11, Average: result.DNF},
+			want: true,
+		},
+		{
+			name: "无历史成绩",
+			rule: rule,
+			pre:  result.Results{CubeID: "2021TEST01", EventID: "444", EventRoute: event.RouteType5RoundsAvgHT, Best: 40, Average: 45},
+			want: false,
+		},
+		{
+			name: "多次尝试项目",
+			rule: rule,
+			pre:  result.Results{CubeID: "2021TEST01", EventID: "333mbf", EventRoute: event.RouteTypeRepeatedly, Best: 5},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := CheckPreResultRule(tt.rule, tt.pre, best)
+			if got != tt.want {
+				t.Errorf("CheckPreResultRule() = %v (%s), want %v", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreResultIter_ExpirePreResults(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&competition.Competition{}, &result.PreResults{}, &user.User{}); err != nil {
+		t.Fatal(err)
+	}
+
+	comp := competition.Competition{
+		Name: "过期测试",
+		CompJSON: competition.CompetitionJson{
+			Events: []competition.CompetitionEvent{{
+				EventID: "333",
+				Schedule: []competition.Schedule{
+					{RoundNum: 1, IsRunning: true},
+					{RoundNum: 2, ActualEndTime: time.Now().Add(-time.Hour)},
+				},
+			}},
+			PreResultRule: competition.PreResultRule{AutoExpire: true, ExpireAfterMinutes: 30},
+		},
+	}
+	if err = db.Create(&comp).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 轮次未关闭的成绩超过一批, 排在可以过期的成绩前面
+	var pres []result.PreResults
+	for i := 0; i < preResultExpireBatch+20; i++ {
+		pres = append(pres, result.PreResults{Results: result.Results{CompetitionID: comp.ID, EventID: "333", RoundNumber: 1}})
+	}
+	// 提交时间早于30天的成绩也需要过期
+	old := time.Now().Add(-time.Hour * 24 * 40)
+	pres = append(pres, result.PreResults{Results: result.Results{Model: basemodel.Model{CreatedAt: old}, CompetitionID: comp.ID, EventID: "333", RoundNumber: 2}})
+	if err = db.CreateInBatches(pres, 100).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	c := &PreResultIter{DB: db}
+	num, err := c.ExpirePreResults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if num != 1 {
+		t.Fatalf("want 1 expired, got %d", num)
+	}
+	var got result.PreResults
+	db.Where("round_number = ?", 2).First(&got)
+	if !got.Finish || got.FinishDetail != result.DetailTimeout || got.Processor != PreResultSystemProcessor {
+		t.Fatalf("unexpected pre result %+v", got)
+	}
+	var pending int64
+	db.Model(&result.PreResults{}).Where("finish = ?", false).Count(&pending)
+	if pending != int64(preResultExpireBatch+20) {
+		t.Fatalf("want %d pending, got %d", preResultExpireBatch+20, pending)
+	}
+}
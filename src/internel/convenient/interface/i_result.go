@@ -32,6 +32,8 @@ type ResultI interface {
 	SelectUserResultDetail(cubeId string, year *int) UserResultDetail                                      // 获取用户详细成绩信息
 	SelectCompsResult(id uint) map[EventID]map[int][]result.Results                                        // 比赛成绩 map[项目] map[轮次] 成绩列表
 	SelectPlayerEndYears(id uint, year int) (PlayerEndYears, error)                                        // 某一年的年终总结
+	SelectPlayerTimeReport(cubeId string, events []string) (PlayerTimeReport, error)                       // 玩家按时间的成绩报表
+	SelectHeadToHead(cubeIdA, cubeIdB string, events []string) (HeadToHead, error)                         // 两位玩家对比
 
 	SelectAllPlayerBestResultWithGroup(groupId uint) (best PlayerBestResult, all []PlayerBestResult) // 获取一个群组的比赛
 }
@@ -318,7 +320,7 @@ func (c *ResultIter) KinChSor(best *PlayerBestResult, events []event.Event, play
 				bestResult := best.Single[e.ID].Best + ((3600 - best.Single[e.ID].BestRepeatedlyTime) / 3600)
 				playerResult := s.Best + ((3600 - s.BestRepeatedlyTime) / 3600)
 				kr.Result = (playerResult / bestResult) * 100
-				kr.ResultString = fmt.Sprintf("%d/%d %s", int(s.BestRepeatedlyReduction), int(s.BestRepeatedlyTry), result.TimeParserF2S(s.BestRepeatedlyTime))
+				kr.ResultString = s.BestString()
 			} else {
 				kr.ResultString = fmt.Sprintf("%s", result.TimeParserF2S(s.Best))
 				if a, ok2 := player.Avgs[e.ID]; ok2 {
@@ -0,0 +1,213 @@
+package _interface
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/user"
+)
+
+func (c *ResultIter) SelectHeadToHead(cubeIdA, cubeIdB string, events []string) (HeadToHead, error) {
+	cubeIdA, cubeIdB = strings.ToUpper(cubeIdA), strings.ToUpper(cubeIdB)
+	if cubeIdA == cubeIdB {
+		return HeadToHead{}, errors.New("不能和自己对比")
+	}
+	key := fmt.Sprintf("SelectHeadToHead_%s_%s_%v", cubeIdA, cubeIdB, events)
+	if value, ok := c.Cache.Get(key); ok {
+		return value.(HeadToHead), nil
+	}
+
+	var a, b user.User
+	if err := c.DB.First(&a, "cube_id = ?", cubeIdA).Error; err != nil {
+		return HeadToHead{}, fmt.Errorf("玩家%s不存在", cubeIdA)
+	}
+	if err := c.DB.First(&b, "cube_id = ?", cubeIdB).Error; err != nil {
+		return HeadToHead{}, fmt.Errorf("玩家%s不存在", cubeIdB)
+	}
+
+	var results []result.Results
+	db := c.DB.Where("user_id in ?", []uint{a.ID, b.ID}).Where("ban = ?", false)
+	if len(events) > 0 {
+		db = db.Where("event_id in ?", events)
+	}
+	if err := db.Find(&results).Error; err != nil {
+		return HeadToHead{}, err
+	}
+
+	var evs []event.Event
+	edb := c.DB.Order("idx")
+	if len(events) > 0 {
+		edb = edb.Where("id in ?", events)
+	}
+	edb.Find(&evs)
+
+	out := c.HeadToHead(a, b, results, evs)
+	c.Cache.Set(key, out, time.Minute*15)
+	return out, nil
+}
+
+// HeadToHead 计算两位玩家的对比, results 为两人的全部成绩, events 决定项目顺序
+func (c *ResultIter) HeadToHead(a, b user.User, results []result.Results, events []event.Event) HeadToHead {
+	_, all := c.AllPlayerBestResult(results, []user.User{a, b})
+	var pbA, pbB PlayerBestResult
+	for _, p := range all {
+		switch p.PlayerId {
+		case a.ID:
+			pbA = p
+		case b.ID:
+			pbB = p
+		}
+	}
+
+	out := HeadToHead{
+		A:      pbA.Player,
+		B:      pbB.Player,
+		Events: make([]HeadToHeadEvent, 0),
+		Rounds: make([]HeadToHeadRound, 0),
+	}
+
+	// 1. 同场同轮
+	type roundKey struct {
+		comp  uint
+		ev    string
+		round int
+	}
+	var roundA, roundB = make(map[roundKey]result.Results), make(map[roundKey]result.Results)
+	for _, r := range results {
+		key := roundKey{comp: r.CompetitionID, ev: r.EventID, round: r.RoundNumber}
+		switch r.UserID {
+		case a.ID:
+			roundA[key] = r
+		case b.ID:
+			roundB[key] = r
+		}
+	}
+	var roundWins = make(map[EventID][2]int)
+	for key, ra := range roundA {
+		rb, ok := roundB[key]
+		if !ok {
+			continue
+		}
+		round := HeadToHeadRound{
+			CompetitionID:   ra.CompetitionID,
+			CompetitionName: ra.CompetitionName,
+			EventID:         ra.EventID,
+			EventName:       ra.EventName,
+			Round:           ra.Round,
+			RoundNumber:     ra.RoundNumber,
+			A:               ra,
+			B:               rb,
+			Winner:          roundWinner(ra, rb),
+		}
+		wins := roundWins[ra.EventID]
+		switch round.Winner {
+		case HeadToHeadWinnerA:
+			out.ARoundWins++
+			wins[0]++
+		case HeadToHeadWinnerB:
+			out.BRoundWins++
+			wins[1]++
+		default:
+			out.RoundDraws++
+		}
+		roundWins[ra.EventID] = wins
+		out.Rounds = append(out.Rounds, round)
+	}
+	sort.Slice(out.Rounds, func(i, j int) bool {
+		if out.Rounds[i].CompetitionID != out.Rounds[j].CompetitionID {
+			return out.Rounds[i].CompetitionID < out.Rounds[j].CompetitionID
+		}
+		if out.Rounds[i].EventID != out.Rounds[j].EventID {
+			return out.Rounds[i].EventID < out.Rounds[j].EventID
+		}
+		return out.Rounds[i].RoundNumber < out.Rounds[j].RoundNumber
+	})
+
+	// 2. 各项目PB
+	for _, ev := range events {
+		sa, okSa := pbA.Single[ev.ID]
+		sb, okSb := pbB.Single[ev.ID]
+		if !okSa && !okSb {
+			continue
+		}
+		he := HeadToHeadEvent{
+			EventID:    ev.ID,
+			EventName:  ev.Cn,
+			ARoundWins: roundWins[ev.ID][0],
+			BRoundWins: roundWins[ev.ID][1],
+		}
+		if okSa {
+			he.ASingle = sa.BestString()
+		}
+		if okSb {
+			he.BSingle = sb.BestString()
+		}
+		he.SingleWinner = pbWinner(sa, okSa, sb, okSb, func(x, y result.Results) bool { return x.IsBest(y) })
+
+		aa, okAa := pbA.Avgs[ev.ID]
+		ab, okAb := pbB.Avgs[ev.ID]
+		if okAa {
+			he.AAvg = aa.BestAvgString()
+		}
+		if okAb {
+			he.BAvg = ab.BestAvgString()
+		}
+		if okAa || okAb {
+			he.AvgWinner = pbWinner(aa, okAa, ab, okAb, func(x, y result.Results) bool { return x.Average <= y.Average })
+		}
+
+		for _, w := range []string{he.SingleWinner, he.AvgWinner} {
+			switch w {
+			case HeadToHeadWinnerA:
+				out.APBWins++
+			case HeadToHeadWinnerB:
+				out.BPBWins++
+			}
+		}
+		out.Events = append(out.Events, he)
+	}
+
+	// 3. 总分
+	out.AScore = out.APBWins + out.ARoundWins
+	out.BScore = out.BPBWins + out.BRoundWins
+	switch {
+	case out.AScore > out.BScore:
+		out.WinnerTotal = HeadToHeadWinnerA
+	case out.BScore > out.AScore:
+		out.WinnerTotal = HeadToHeadWinnerB
+	}
+	return out
+}
+
+// pbWinner 有成绩者胜, 都有时按 better 比较, 相等为平局
+func pbWinner(a result.Results, okA bool, b result.Results, okB bool, better func(x, y result.Results) bool) string {
+	switch {
+	case okA && !okB:
+		return HeadToHeadWinnerA
+	case !okA && okB:
+		return HeadToHeadWinnerB
+	case !okA && !okB:
+		return ""
+	}
+	ab, ba := better(a, b), better(b, a)
+	switch {
+	case ab && !ba:
+		return HeadToHeadWinnerA
+	case ba && !ab:
+		return HeadToHeadWinnerB
+	}
+	return ""
+}
+
+// roundWinner 同一轮次按该轮的排名规则比较
+func roundWinner(a, b result.Results) string {
+	if a.EventRoute.RouteMap().WithBest {
+		return pbWinner(a, true, b, true, func(x, y result.Results) bool { return x.IsBest(y) })
+	}
+	return pbWinner(a, true, b, true, func(x, y result.Results) bool { return x.IsBestAvg(y) })
+}
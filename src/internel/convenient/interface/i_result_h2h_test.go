@@ -0,0 +1,82 @@
+package _interface
+
+import (
+	"testing"
+
+	basemodel "github.com/guojia99/cubing-pro/src/internel/database/model/base"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/user"
+)
+
+func h2hTestResult(userId uint, compId uint, ev string, route event.RouteType, in ...float64) result.Results {
+	r := result.Results{
+		CompetitionID: compId,
+		RoundNumber:   1,
+		UserID:        userId,
+		EventID:       ev,
+		EventRoute:    route,
+		Result:        in,
+	}
+	_ = r.Update()
+	return r
+}
+
+func TestResultIter_HeadToHead(t *testing.T) {
+	a := user.User{Model: basemodel.Model{ID: 1}, Name: "A", CubeID: "2020AAAA01"}
+	b := user.User{Model: basemodel.Model{ID: 2}, Name: "B", CubeID: "2020BBBB01"}
+	events := []event.Event{
+		{StringIDModel: basemodel.StringIDModel{ID: "333"}, Cn: "三阶"},
+		{StringIDModel: basemodel.StringIDModel{ID: "222"}, Cn: "二阶"},
+		{StringIDModel: basemodel.StringIDModel{ID: "444"}, Cn: "四阶"},
+		{StringIDModel: basemodel.StringIDModel{ID: "555"}, Cn: "五阶"},
+	}
+	ht := event.RouteType5RoundsAvgHT
+
+	results := []result.Results{
+		// 比赛1 三阶: A 单次更快, B 平均更快 -> 同轮按平均 B 胜
+		h2hTestResult(1, 1, "333", ht, 8, 12, 12, 12, 13),
+		h2hTestResult(2, 1, "333", ht, 10, 11, 11, 11, 11),
+		// 比赛2 三阶: A 胜
+		h2hTestResult(1, 2, "333", ht, 9, 10, 10, 10, 11),
+		h2hTestResult(2, 2, "333", ht, 12, 12, 12, 12, 12),
+		// 比赛2 二阶: 完全相同, 平局
+		h2hTestResult(1, 2, "222", ht, 3, 3, 3, 3, 3),
+		h2hTestResult(2, 2, "222", ht, 3, 3, 3, 3, 3),
+		// 四阶只有 A 有成绩
+		h2hTestResult(1, 3, "444", ht, 40, 41, 42, 43, 44),
+	}
+
+	got := (&ResultIter{}).HeadToHead(a, b, results, events)
+
+	if len(got.Events) != 3 {
+		t.Fatalf("Events = %+v", got.Events)
+	}
+	e333, e222, e444 := got.Events[0], got.Events[1], got.Events[2]
+	if e333.SingleWinner != HeadToHeadWinnerA || e333.AvgWinner != HeadToHeadWinnerA {
+		t.Errorf("333 = %+v", e333)
+	}
+	if e333.ARoundWins != 1 || e333.BRoundWins != 1 {
+		t.Errorf("333 round = %+v", e333)
+	}
+	if e222.SingleWinner != "" || e222.AvgWinner != "" {
+		t.Errorf("222 = %+v", e222)
+	}
+	if e444.SingleWinner != HeadToHeadWinnerA || e444.BSingle != "" {
+		t.Errorf("444 = %+v", e444)
+	}
+
+	if len(got.Rounds) != 3 || got.Rounds[0].Winner != HeadToHeadWinnerB || got.Rounds[1].EventID != "222" {
+		t.Errorf("Rounds = %+v", got.Rounds)
+	}
+	if got.ARoundWins != 1 || got.BRoundWins != 1 || got.RoundDraws != 1 {
+		t.Errorf("round wins = %d:%d (%d)", got.ARoundWins, got.BRoundWins, got.RoundDraws)
+	}
+	// PB: 333 单次+平均, 444 单次+平均
+	if got.APBWins != 4 || got.BPBWins != 0 {
+		t.Errorf("pb wins = %d:%d", got.APBWins, got.BPBWins)
+	}
+	if got.AScore != 5 || got.BScore != 1 || got.WinnerTotal != HeadToHeadWinnerA {
+		t.Errorf("score = %d:%d %s", got.AScore, got.BScore, got.WinnerTotal)
+	}
+}
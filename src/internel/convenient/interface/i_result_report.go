@@ -0,0 +1,335 @@
+package _interface
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/user"
+)
+
+func (c *ResultIter) SelectPlayerTimeReport(cubeId string, events []string) (PlayerTimeReport, error) {
+	key := fmt.Sprintf("SelectPlayerTimeReport_%s_%v", cubeId, events)
+	if value, ok := c.Cache.Get(key); ok {
+		return value.(PlayerTimeReport), nil
+	}
+
+	var usr user.User
+	if err := c.DB.First(&usr, "cube_id = ?", cubeId).Error; err != nil {
+		return PlayerTimeReport{}, errors.New("玩家不存在")
+	}
+
+	var results []result.Results
+	db := c.DB.Where("cube_id = ?", cubeId).Where("ban = ?", false)
+	if len(events) > 0 {
+		db = db.Where("event_id in ?", events)
+	}
+	if err := db.Find(&results).Error; err != nil {
+		return PlayerTimeReport{}, err
+	}
+
+	var compIds []uint
+	for _, r := range results {
+		compIds = append(compIds, r.CompetitionID)
+	}
+	var comps []competition.Competition
+	if len(compIds) > 0 {
+		c.DB.Where("id in ?", compIds).Find(&comps)
+	}
+	compDates := make(map[uint]time.Time, len(comps))
+	for _, comp := range comps {
+		compDates[comp.ID] = comp.CompStartTime
+	}
+
+	out := PlayerTimeReport{
+		Player: Player{
+			PlayerId:   usr.ID,
+			CubeId:     usr.CubeID,
+			PlayerName: usr.Name,
+			WcaID:      usr.WcaID,
+		},
+		Events: buildPlayerTimeReport(results, compDates),
+	}
+	c.Cache.Set(key, out, time.Minute*15)
+	return out, nil
+}
+
+type datedResult struct {
+	result.Results
+	Date time.Time
+}
+
+// buildPlayerTimeReport 按项目统计单个玩家的成绩, compDates 为比赛开始时间, 缺失时使用成绩录入时间
+func buildPlayerTimeReport(results []result.Results, compDates map[uint]time.Time) map[EventID]PlayerEventTimeReport {
+	var byEvent = make(map[EventID][]datedResult)
+	for _, r := range results {
+		date, ok := compDates[r.CompetitionID]
+		if !ok || date.IsZero() {
+			date = r.CreatedAt
+		}
+		byEvent[r.EventID] = append(byEvent[r.EventID], datedResult{Results: r, Date: date})
+	}
+
+	var out = make(map[EventID]PlayerEventTimeReport, len(byEvent))
+	for ev, list := range byEvent {
+		sort.SliceStable(list, func(i, j int) bool {
+			if !list[i].Date.Equal(list[j].Date) {
+				return list[i].Date.Before(list[j].Date)
+			}
+			if list[i].CompetitionID != list[j].CompetitionID {
+				return list[i].CompetitionID < list[j].CompetitionID
+			}
+			return list[i].RoundNumber < list[j].RoundNumber
+		})
+		out[ev] = buildEventTimeReport(list)
+	}
+	return out
+}
+
+func buildEventTimeReport(list []datedResult) PlayerEventTimeReport {
+	out := PlayerEventTimeReport{
+		EventID:        list[0].EventID,
+		EventName:      list[0].EventName,
+		SingleTimeline: make([]PBNode, 0),
+		AvgTimeline:    make([]PBNode, 0),
+		Monthly:        make([]PeriodStat, 0),
+		Yearly:         make([]PeriodStat, 0),
+		CompDeltas:     make([]CompDelta, 0),
+	}
+	rm := list[0].EventRoute.RouteMap()
+
+	// 1. PB时间线
+	var pbSingle, pbAvg *datedResult
+	for i := range list {
+		r := list[i]
+		if !r.DBest() && (pbSingle == nil || betterSingle(rm.Repeatedly, r.Results, pbSingle.Results)) {
+			node := newPBNode(r, r.Best, r.BestString())
+			if pbSingle != nil {
+				node.Improve = resultImprove(rm.Repeatedly, pbSingle.Best, r.Best)
+			}
+			out.SingleTimeline = append(out.SingleTimeline, node)
+			pbSingle = &list[i]
+		}
+		if rm.Repeatedly || r.DAvg() || r.Average == 0 {
+			continue
+		}
+		if pbAvg == nil || r.Average < pbAvg.Average {
+			node := newPBNode(r, r.Average, r.BestAvgString())
+			if pbAvg != nil {
+				node.Improve = resultImprove(false, pbAvg.Average, r.Average)
+			}
+			out.AvgTimeline = append(out.AvgTimeline, node)
+			pbAvg = &list[i]
+		}
+	}
+
+	// 2. 按月/按年统计
+	out.Monthly = periodStats(list, "2006-01")
+	out.Yearly = periodStats(list, "2006")
+
+	// 3. 成功率与稳定性
+	var stdDevs []float64
+	for _, r := range list {
+		if rm.Repeatedly {
+			continue
+		}
+		restores, successes := countAttempts(r.Result)
+		out.RestoresNum += restores
+		out.SuccessesNum += successes
+		if sd, ok := countingStdDev(r.Results); ok {
+			stdDevs = append(stdDevs, sd)
+		}
+	}
+	if out.RestoresNum > 0 {
+		out.SuccessRate = round2(float64(out.SuccessesNum) / float64(out.RestoresNum))
+	}
+	if len(stdDevs) > 0 {
+		out.StdDev = round2(mean(stdDevs))
+	}
+
+	// 4. 场次间变化
+	out.CompDeltas = compDeltas(list, rm.Repeatedly)
+	return out
+}
+
+func newPBNode(r datedResult, value float64, str string) PBNode {
+	return PBNode{
+		CompetitionID:   r.CompetitionID,
+		CompetitionName: r.CompetitionName,
+		Date:            r.Date,
+		Result:          value,
+		ResultString:    str,
+	}
+}
+
+// betterSingle 单次是否严格优于之前的PB
+func betterSingle(repeatedly bool, cur, prev result.Results) bool {
+	if repeatedly {
+		return cur.IsBest(prev) && !prev.IsBest(cur)
+	}
+	return cur.Best < prev.Best
+}
+
+// resultImprove 提升量, 计时项目为减少的时间, 多次尝试项目为增加的分数
+func resultImprove(repeatedly bool, prev, cur float64) float64 {
+	if repeatedly {
+		return round2(cur - prev)
+	}
+	return round2(prev - cur)
+}
+
+// countAttempts 统计尝试与成功次数, 未开始与未晋级不计入, 超时与未知算作尝试失败
+func countAttempts(in []float64) (restores, successes int) {
+	for _, r := range in {
+		if r == result.DNS || r == result.DNP {
+			continue
+		}
+		restores += 1
+		if r > result.DNF {
+			successes += 1
+		}
+	}
+	return
+}
+
+// countingStdDev 计入平均的成绩的标准差, 去头尾项目去掉最快和最慢的成绩
+func countingStdDev(r result.Results) (float64, bool) {
+	rm := r.EventRoute.RouteMap()
+	if r.DAvg() || r.Average == 0 || len(r.Result) < 2 {
+		return 0, false
+	}
+	var counting []float64
+	for _, v := range r.Result {
+		if v > result.DNF {
+			counting = append(counting, v)
+		}
+	}
+	if len(counting) < len(r.Result)-rm.HeadToTailNum {
+		return 0, false
+	}
+	slices.Sort(counting)
+	if rm.HeadToTailNum > 0 && len(counting) == len(r.Result) {
+		counting = counting[rm.HeadToTailNum : len(counting)-rm.HeadToTailNum]
+	} else if rm.HeadToTailNum > 0 {
+		// 有一把DNF被去尾, 只需去头
+		counting = counting[rm.HeadToTailNum:]
+	}
+	if len(counting) < 2 {
+		return 0, false
+	}
+	m := mean(counting)
+	var sum float64
+	for _, v := range counting {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(counting))), true
+}
+
+func periodStats(list []datedResult, layout string) []PeriodStat {
+	var out []PeriodStat
+	var idx = make(map[string]int)
+	var singles, avgs = make(map[string][]float64), make(map[string][]float64)
+	var comps = make(map[string]map[uint]struct{})
+
+	for _, r := range list {
+		period := r.Date.Format(layout)
+		i, ok := idx[period]
+		if !ok {
+			i = len(out)
+			idx[period] = i
+			out = append(out, PeriodStat{Period: period, Best: result.DNF})
+			comps[period] = make(map[uint]struct{})
+		}
+		comps[period][r.CompetitionID] = struct{}{}
+
+		st := &out[i]
+		if r.EventRoute.RouteMap().Repeatedly {
+			// 多次尝试项目的分数只统计正分, 没有正分时为0
+			if st.Best <= result.DNF {
+				st.Best = 0
+			}
+			if !r.DBest() && r.Best > st.Best {
+				st.Best = r.Best
+			}
+			continue
+		}
+
+		restores, successes := countAttempts(r.Result)
+		st.RestoresNum += restores
+		st.SuccessesNum += successes
+		for _, v := range r.Result {
+			if v > result.DNF {
+				singles[period] = append(singles[period], v)
+			}
+		}
+		if !r.DBest() && (st.Best <= result.DNF || r.Best < st.Best) {
+			st.Best = r.Best
+		}
+		if !r.DAvg() && r.Average > 0 {
+			avgs[period] = append(avgs[period], r.Average)
+		}
+	}
+
+	for i := range out {
+		p := out[i].Period
+		out[i].CompNum = len(comps[p])
+		out[i].SingleMean = round2(mean(singles[p]))
+		out[i].AverageMean = round2(mean(avgs[p]))
+	}
+	return out
+}
+
+func compDeltas(list []datedResult, repeatedly bool) []CompDelta {
+	var out []CompDelta
+	var idx = make(map[uint]int)
+	for _, r := range list {
+		i, ok := idx[r.CompetitionID]
+		if !ok {
+			i = len(out)
+			idx[r.CompetitionID] = i
+			out = append(out, CompDelta{
+				CompetitionID:   r.CompetitionID,
+				CompetitionName: r.CompetitionName,
+				Date:            r.Date,
+				Best:            result.DNF,
+				Average:         result.DNF,
+			})
+		}
+		d := &out[i]
+		if !r.DBest() && (d.Best <= result.DNF || (repeatedly && r.Best > d.Best) || (!repeatedly && r.Best < d.Best)) {
+			d.Best = r.Best
+		}
+		if !repeatedly && !r.DAvg() && r.Average > 0 && (d.Average <= result.DNF || r.Average < d.Average) {
+			d.Average = r.Average
+		}
+	}
+
+	for i := 1; i < len(out); i++ {
+		prev, cur := out[i-1], &out[i]
+		if prev.Best > result.DNF && cur.Best > result.DNF {
+			cur.BestDelta = round2(cur.Best - prev.Best)
+		}
+		if prev.Average > result.DNF && cur.Average > result.DNF {
+			cur.AverageDelta = round2(cur.Average - prev.Average)
+		}
+	}
+	return out
+}
+
+func mean(in []float64) float64 {
+	if len(in) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range in {
+		sum += v
+	}
+	return sum / float64(len(in))
+}
+
+func round2(in float64) float64 { return math.Round(in*100) / 100 }
@@ -0,0 +1,140 @@
+package _interface
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
+)
+
+func reportTestResult(compId uint, round int, in ...float64) result.Results {
+	r := result.Results{
+		CompetitionID: compId,
+		RoundNumber:   round,
+		EventID:       "333",
+		EventRoute:    event.RouteType5RoundsAvgHT,
+		Result:        in,
+	}
+	_ = r.Update()
+	return r
+}
+
+func Test_buildPlayerTimeReport(t *testing.T) {
+	compDates := map[uint]time.Time{
+		1: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		2: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
+		3: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		4: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	results := []result.Results{
+		reportTestResult(3, 1, 9, 10, 11, 12, 13),                  // 平均 11
+		reportTestResult(1, 1, 10, 12, 12, 12, 14),                 // 平均 12
+		reportTestResult(2, 1, 11, 13, 13, 13, result.DNF),         // 平均 13
+		reportTestResult(1, 2, 11, 11, 11, 11, 11),                 // 平均 11
+		reportTestResult(4, 1, result.DNF, result.DNF, 8, 9, 10),   // 平均 DNF
+		reportTestResult(4, 2, 12, result.DNS, result.DNS, 12, 12), // 平均 DNF
+	}
+
+	report := buildPlayerTimeReport(results, compDates)["333"]
+
+	// 单次: 10 -> 9 -> 8
+	wantSingle := []float64{10, 9, 8}
+	if len(report.SingleTimeline) != len(wantSingle) {
+		t.Fatalf("SingleTimeline = %+v", report.SingleTimeline)
+	}
+	for i, want := range wantSingle {
+		if report.SingleTimeline[i].Result != want {
+			t.Errorf("SingleTimeline[%d] = %v, want %v", i, report.SingleTimeline[i].Result, want)
+		}
+	}
+	if report.SingleTimeline[2].Improve != 1 || report.SingleTimeline[2].CompetitionID != 4 {
+		t.Errorf("SingleTimeline[2] = %+v", report.SingleTimeline[2])
+	}
+
+	// 平均: 12 -> 11 (同场第二轮)
+	if len(report.AvgTimeline) != 2 || report.AvgTimeline[1].Result != 11 || report.AvgTimeline[1].CompetitionID != 1 {
+		t.Errorf("AvgTimeline = %+v", report.AvgTimeline)
+	}
+
+	// 按月 2024-01, 2024-03, 2025-02; 按年 2024, 2025
+	if len(report.Monthly) != 3 || report.Monthly[0].Period != "2024-01" || report.Monthly[0].CompNum != 2 {
+		t.Errorf("Monthly = %+v", report.Monthly)
+	}
+	if report.Monthly[0].AverageMean != 12 {
+		t.Errorf("Monthly[0].AverageMean = %v, want 12", report.Monthly[0].AverageMean)
+	}
+	if len(report.Yearly) != 2 || report.Yearly[1].Period != "2025" || report.Yearly[1].Best != 8 {
+		t.Errorf("Yearly = %+v", report.Yearly)
+	}
+
+	// 尝试 28 次 (两次DNS不计), 成功 25 次
+	if report.RestoresNum != 28 || report.SuccessesNum != 25 {
+		t.Errorf("RestoresNum = %v, SuccessesNum = %v", report.RestoresNum, report.SuccessesNum)
+	}
+	if report.SuccessRate != 0.89 {
+		t.Errorf("SuccessRate = %v, want 0.89", report.SuccessRate)
+	}
+
+	// 比赛间变化, 2 相对 1: 单次 +1, 平均 +2
+	if len(report.CompDeltas) != 4 {
+		t.Fatalf("CompDeltas = %+v", report.CompDeltas)
+	}
+	if report.CompDeltas[1].BestDelta != 1 || report.CompDeltas[1].AverageDelta != 2 {
+		t.Errorf("CompDeltas[1] = %+v", report.CompDeltas[1])
+	}
+	if report.CompDeltas[3].AverageDelta != 0 {
+		t.Errorf("CompDeltas[3] 平均DNF不应计算差值 = %+v", report.CompDeltas[3])
+	}
+}
+
+func Test_countingStdDev(t *testing.T) {
+	tests := []struct {
+		name   string
+		r      result.Results
+		want   float64
+		wantOk bool
+	}{
+		{name: "去头尾", r: reportTestResult(1, 1, 1, 10, 12, 14, 100), want: 1.63, wantOk: true},
+		{name: "一个DNF", r: reportTestResult(1, 1, result.DNF, 10, 12, 14, 1), want: 1.63, wantOk: true},
+		{name: "全部一致", r: reportTestResult(1, 1, 11, 11, 11, 11, 11), want: 0, wantOk: true},
+		{name: "平均DNF", r: reportTestResult(1, 1, result.DNF, result.DNF, 12, 14, 1), wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := countingStdDev(tt.r)
+			if ok != tt.wantOk {
+				t.Fatalf("countingStdDev() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if round2(got) != tt.want {
+				t.Errorf("countingStdDev() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_countAttempts(t *testing.T) {
+	restores, successes := countAttempts([]float64{10, result.DNF, result.DNS, result.DNP, result.DNT, result.UNT})
+	if restores != 4 || successes != 1 {
+		t.Errorf("countAttempts() = %v, %v, want 4, 1", restores, successes)
+	}
+}
+
+func Test_periodStatsWithRepeatedly(t *testing.T) {
+	list := []datedResult{
+		{Results: result.Results{EventID: "333mbf", EventRoute: event.RouteTypeRepeatedly, Result: []float64{1, 2, 600}}},
+		{Results: result.Results{EventID: "333mbf", EventRoute: event.RouteTypeRepeatedly, Result: []float64{3, 4, 1800}}},
+	}
+	for i := range list {
+		_ = list[i].Update()
+		list[i].Date = time.Date(2024, 1, 1+i, 0, 0, 0, 0, time.UTC)
+	}
+	stats := periodStats(list[:1], "2006")
+	if len(stats) != 1 || stats[0].Best != 0 {
+		t.Errorf("全部DNF时 Best 应为0, got %+v", stats)
+	}
+	stats = periodStats(list, "2006")
+	if len(stats) != 1 || stats[0].Best != 2 {
+		t.Errorf("Best 应为2, got %+v", stats)
+	}
+}
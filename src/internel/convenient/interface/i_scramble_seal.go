@@ -0,0 +1,266 @@
+package _interface
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"gorm.io/gorm"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/user"
+	"github.com/guojia99/cubing-pro/src/internel/utils"
+)
+
+const (
+	ScrambleViewAll   = -1 // 查看整轮打乱
+	ScrambleViewSheet = -2 // 主办导出解密后的打乱表
+)
+
+type ScrambleSealI interface {
+	SealCompScrambles(comp *competition.Competition) error                                                                                           // 将比赛中未密封的打乱加密保存, 并从 comp_json 移除, 与比赛的创建或保存在同一事务中
+	ReleaseSealedScrambles(compId uint, eventId string, roundNum int) error                                                                          // 轮次开启时公布打乱
+	ViewSealedScrambles(comp competition.Competition, eventId string, roundNum int, usr user.User, ip string, next bool) (SealedScrambleView, error) // 选手查看打乱, next 为逐把公布时查看下一把
+	ScrambleViewLogs(compId uint, eventId string, roundNum int) ([]competition.ScrambleViewLog, error)                                               // 打乱查看记录
+	RoundScrambles(comp competition.Competition, eventId string, roundNum int) ([][]string, error)                                                   // 轮次的打乱, 密封的打乱会解密, 用于校验成绩
+}
+
+type ScrambleSealIter struct {
+	DB  *gorm.DB
+	Key string // 打乱加密密钥
+}
+
+type RevealedScramble struct {
+	Index      int       `json:"Index"`
+	Scramble   string    `json:"Scramble"`
+	RevealedAt time.Time `json:"RevealedAt"`
+}
+
+type SealedScrambleView struct {
+	EventID   string             `json:"EventID"`
+	RoundNum  int                `json:"RoundNum"`
+	Staggered bool               `json:"Staggered"`
+	Total     int                `json:"Total"`               // 逐把公布时的打乱总数
+	Scrambles [][]string         `json:"Scrambles,omitempty"` // 整轮打乱
+	Revealed  []RevealedScramble `json:"Revealed,omitempty"`  // 逐把公布时已查看的打乱
+}
+
+func (c *ScrambleSealIter) SealCompScrambles(comp *competition.Competition) error {
+	if c.Key == "" {
+		return errors.New("未配置打乱加密密钥")
+	}
+	rule := comp.CompJSON.ScrambleSeal
+
+	return c.DB.Transaction(func(tx *gorm.DB) error {
+		// 新比赛需要先创建才有ID
+		if comp.ID == 0 {
+			if err := tx.Create(comp).Error; err != nil {
+				return err
+			}
+		}
+		for i := range comp.CompJSON.Events {
+			ev := &comp.CompJSON.Events[i]
+			for j := range ev.Schedule {
+				sc := &ev.Schedule[j]
+				if sc.Sealed || len(sc.Scrambles) == 0 {
+					continue
+				}
+				data, err := sealScrambles(c.Key, sc.Scrambles)
+				if err != nil {
+					return err
+				}
+				sealed := competition.SealedScramble{
+					CompID:    comp.ID,
+					EventID:   ev.EventID,
+					RoundNum:  sc.RoundNum,
+					Cipher:    data,
+					Staggered: rule.Staggered,
+				}
+				// 已开启的轮次直接公布
+				if sc.IsRunning {
+					sealed.Released = true
+					sealed.ReleasedAt = utils.PtrTime(time.Now())
+				}
+				if err = tx.Where("comp_id = ? and event_id = ? and round_num = ?", comp.ID, ev.EventID, sc.RoundNum).
+					Delete(&competition.SealedScramble{}).Error; err != nil {
+					return err
+				}
+				if err = tx.Create(&sealed).Error; err != nil {
+					return err
+				}
+				sc.Scrambles = nil
+				sc.Sealed = true
+			}
+		}
+		return tx.Save(comp).Error
+	})
+}
+
+func (c *ScrambleSealIter) ReleaseSealedScrambles(compId uint, eventId string, roundNum int) error {
+	return c.DB.Model(&competition.SealedScramble{}).
+		Where("comp_id = ? and event_id = ? and round_num = ?", compId, eventId, roundNum).
+		Where("released = ?", false).
+		Updates(map[string]interface{}{"released": true, "released_at": time.Now()}).Error
+}
+
+func (c *ScrambleSealIter) ViewSealedScrambles(comp competition.Competition, eventId string, roundNum int, usr user.User, ip string, next bool) (SealedScrambleView, error) {
+	var sealed competition.SealedScramble
+	if err := c.DB.First(&sealed, "comp_id = ? and event_id = ? and round_num = ?", comp.ID, eventId, roundNum).Error; err != nil {
+		return SealedScrambleView{}, errors.New("该轮次没有密封打乱")
+	}
+	if !sealed.Released {
+		return SealedScrambleView{}, errors.New("轮次未开启, 打乱尚未公布")
+	}
+
+	// 线上非正式赛提交成绩时自动报名, 其他比赛需要已通过报名
+	if comp.Genre != competition.OnlineInformal {
+		var reg competition.Registration
+		if err := c.DB.First(&reg, "comp_id = ? and user_id = ? and status = ?", comp.ID, usr.ID, competition.RegisterStatusPass).Error; err != nil {
+			return SealedScrambleView{}, errors.New("未报名该比赛")
+		}
+	}
+
+	scrambles, err := openScrambles(c.Key, sealed.Cipher)
+	if err != nil {
+		return SealedScrambleView{}, err
+	}
+	out := SealedScrambleView{EventID: eventId, RoundNum: roundNum, Staggered: sealed.Staggered}
+	log := competition.ScrambleViewLog{
+		CompID:   comp.ID,
+		EventID:  eventId,
+		RoundNum: roundNum,
+		UserID:   usr.ID,
+		CubeID:   usr.CubeID,
+		UserName: usr.Name,
+		Index:    ScrambleViewAll,
+		IP:       ip,
+	}
+
+	if !sealed.Staggered {
+		out.Scrambles = scrambles
+		return out, c.DB.Create(&log).Error
+	}
+
+	// 逐把公布: 只使用第一组打乱, 按查看记录依次公布
+	var group []string
+	if len(scrambles) > 0 {
+		group = scrambles[0]
+	}
+	out.Total = len(group)
+
+	var logs []competition.ScrambleViewLog
+	c.DB.Where("comp_id = ? and event_id = ? and round_num = ? and user_id = ?", comp.ID, eventId, roundNum, usr.ID).
+		Where("idx >= ?", 0).Order("idx").Find(&logs)
+	revealed := revealedScrambles(group, logs)
+
+	if next {
+		if len(revealed) >= len(group) {
+			return out, errors.New("打乱已全部公布")
+		}
+		log.Index = len(revealed)
+		if err = c.DB.Create(&log).Error; err != nil {
+			return out, err
+		}
+		revealed = append(revealed, RevealedScramble{Index: log.Index, Scramble: group[log.Index], RevealedAt: log.CreatedAt})
+	}
+	out.Revealed = revealed
+	return out, nil
+}
+
+func (c *ScrambleSealIter) ScrambleViewLogs(compId uint, eventId string, roundNum int) ([]competition.ScrambleViewLog, error) {
+	var out []competition.ScrambleViewLog
+	db := c.DB.Where("comp_id = ?", compId)
+	if eventId != "" {
+		db = db.Where("event_id = ?", eventId)
+	}
+	if roundNum > 0 {
+		db = db.Where("round_num = ?", roundNum)
+	}
+	err := db.Order("created_at").Find(&out).Error
+	return out, err
+}
+
+func (c *ScrambleSealIter) RoundScrambles(comp competition.Competition, eventId string, roundNum int) ([][]string, error) {
+	ev, ok := comp.EventMap()[eventId]
+	if !ok {
+		return nil, errors.New("不存在该项目")
+	}
+	schedule, err := ev.CurRunningSchedule(roundNum, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !schedule.Sealed {
+		return schedule.Scrambles, nil
+	}
+
+	var sealed competition.SealedScramble
+	if err = c.DB.First(&sealed, "comp_id = ? and event_id = ? and round_num = ?", comp.ID, eventId, roundNum).Error; err != nil {
+		return nil, errors.New("该轮次没有密封打乱")
+	}
+	return openScrambles(c.Key, sealed.Cipher)
+}
+
+// revealedScrambles 按查看记录得到已公布的打乱, 只计算从第一把开始连续的记录
+func revealedScrambles(group []string, logs []competition.ScrambleViewLog) []RevealedScramble {
+	var out []RevealedScramble
+	for _, log := range logs {
+		if log.Index != len(out) || log.Index >= len(group) {
+			continue
+		}
+		out = append(out, RevealedScramble{Index: log.Index, Scramble: group[log.Index], RevealedAt: log.CreatedAt})
+	}
+	return out
+}
+
+func scrambleSealGCM(key string) (cipher.AEAD, error) {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealScrambles 使用 AES-GCM 加密打乱, 输出 base64(nonce + 密文)
+func sealScrambles(key string, scrambles [][]string) (string, error) {
+	plain, err := jsoniter.Marshal(scrambles)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := scrambleSealGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plain, nil)), nil
+}
+
+func openScrambles(key string, data string) ([][]string, error) {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := scrambleSealGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return nil, errors.New("密封打乱数据损坏")
+	}
+	plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("密封打乱解密失败")
+	}
+	var out [][]string
+	err = jsoniter.Unmarshal(plain, &out)
+	return out, err
+}
@@ -0,0 +1,53 @@
+package _interface
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	basemodel "github.com/guojia99/cubing-pro/src/internel/database/model/base"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+)
+
+func Test_sealScrambles(t *testing.T) {
+	in := [][]string{{"R U R' U'", "F2 D"}, {"L2"}}
+	data, err := sealScrambles("key", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data == "" {
+		t.Fatalf("data = %s", data)
+	}
+
+	got, err := openScrambles("key", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, in) {
+		t.Errorf("openScrambles() = %v, want %v", got, in)
+	}
+	if _, err = openScrambles("other", data); err == nil {
+		t.Error("错误的密钥应解密失败")
+	}
+
+	// 每次加密的 nonce 不同
+	if again, _ := sealScrambles("key", in); again == data {
+		t.Error("相同内容两次加密结果不应相同")
+	}
+}
+
+func Test_revealedScrambles(t *testing.T) {
+	now := time.Now()
+	log := func(idx int) competition.ScrambleViewLog {
+		return competition.ScrambleViewLog{Model: basemodel.Model{CreatedAt: now.Add(time.Duration(idx) * time.Minute)}, Index: idx}
+	}
+	group := []string{"a", "b", "c"}
+
+	got := revealedScrambles(group, []competition.ScrambleViewLog{log(0), log(1), log(1), log(3)})
+	if len(got) != 2 || got[1].Scramble != "b" || !got[1].RevealedAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("revealedScrambles() = %+v", got)
+	}
+	if got = revealedScrambles(group, []competition.ScrambleViewLog{log(1)}); len(got) != 0 {
+		t.Errorf("未从第一把开始的记录不应计入, got %+v", got)
+	}
+}
@@ -1,6 +1,8 @@
 package _interface
 
 import (
+	"time"
+
 	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
 )
 
@@ -88,3 +90,109 @@ type PlayerEndYears struct {
 	Ao50         map[EventID]AoResults  `json:"Ao50"`         // 今年项目最佳ao50
 	Ao100        map[EventID]AoResults  `json:"Ao100"`        // 今年项目最佳ao100
 }
+
+// PlayerTimeReport 玩家按时间的成绩报表
+type PlayerTimeReport struct {
+	Player
+	Events map[EventID]PlayerEventTimeReport `json:"Events"`
+}
+
+type PlayerEventTimeReport struct {
+	EventID   EventID `json:"EventID"`
+	EventName string  `json:"EventName"`
+
+	SingleTimeline []PBNode `json:"SingleTimeline"` // 单次PB进步时间线
+	AvgTimeline    []PBNode `json:"AvgTimeline"`    // 平均PB进步时间线
+
+	Monthly []PeriodStat `json:"Monthly"` // 按月统计
+	Yearly  []PeriodStat `json:"Yearly"`  // 按年统计
+
+	RestoresNum  int     `json:"RestoresNum"`  // 尝试次数
+	SuccessesNum int     `json:"SuccessesNum"` // 成功还原次数
+	SuccessRate  float64 `json:"SuccessRate"`  // 成功率 0~1
+	StdDev       float64 `json:"StdDev"`       // 稳定性, 各平均计入成绩的标准差的均值
+
+	CompDeltas []CompDelta `json:"CompDeltas"` // 每场比赛相对上一场的变化
+}
+
+// PBNode PB刷新节点
+type PBNode struct {
+	CompetitionID   uint      `json:"CompetitionID"`
+	CompetitionName string    `json:"CompetitionName"`
+	Date            time.Time `json:"Date"`
+	Result          float64   `json:"Result"`
+	ResultString    string    `json:"ResultString"`
+	Improve         float64   `json:"Improve"` // 相对上一个PB的提升, 第一个为0
+}
+
+// PeriodStat 一段时间内的统计, Period 为 2024 或 2024-05
+type PeriodStat struct {
+	Period       string  `json:"Period"`
+	CompNum      int     `json:"CompNum"`
+	RestoresNum  int     `json:"RestoresNum"`
+	SuccessesNum int     `json:"SuccessesNum"`
+	Best         float64 `json:"Best"`        // 期间最佳单次
+	SingleMean   float64 `json:"SingleMean"`  // 成功单次的均值
+	AverageMean  float64 `json:"AverageMean"` // 有效平均的均值
+}
+
+// CompDelta 单场比赛该项目最佳成绩, 及与上一场的差值(负数为进步)
+type CompDelta struct {
+	CompetitionID   uint      `json:"CompetitionID"`
+	CompetitionName string    `json:"CompetitionName"`
+	Date            time.Time `json:"Date"`
+	Best            float64   `json:"Best"`
+	Average         float64   `json:"Average"`
+	BestDelta       float64   `json:"BestDelta"`
+	AverageDelta    float64   `json:"AverageDelta"`
+}
+
+const (
+	HeadToHeadWinnerA = "A"
+	HeadToHeadWinnerB = "B"
+)
+
+// HeadToHead 两位玩家的对比
+type HeadToHead struct {
+	A Player `json:"A"`
+	B Player `json:"B"`
+
+	Events []HeadToHeadEvent `json:"Events"` // 各项目PB对比
+	Rounds []HeadToHeadRound `json:"Rounds"` // 同场同轮的对比
+
+	APBWins     int    `json:"APBWins"`     // PB胜场(单次和平均分别计)
+	BPBWins     int    `json:"BPBWins"`     //
+	ARoundWins  int    `json:"ARoundWins"`  // 同轮胜场
+	BRoundWins  int    `json:"BRoundWins"`  //
+	RoundDraws  int    `json:"RoundDraws"`  // 同轮平局
+	AScore      int    `json:"AScore"`      // 总分 = PB胜场 + 同轮胜场
+	BScore      int    `json:"BScore"`      //
+	WinnerTotal string `json:"WinnerTotal"` // 总分胜者 A / B, 平分为空
+}
+
+type HeadToHeadEvent struct {
+	EventID   EventID `json:"EventID"`
+	EventName string  `json:"EventName"`
+
+	ASingle      string `json:"ASingle"`
+	BSingle      string `json:"BSingle"`
+	SingleWinner string `json:"SingleWinner"` // A / B, 平局或无法比较为空
+	AAvg         string `json:"AAvg"`
+	BAvg         string `json:"BAvg"`
+	AvgWinner    string `json:"AvgWinner"`
+
+	ARoundWins int `json:"ARoundWins"` // 该项目同轮胜场
+	BRoundWins int `json:"BRoundWins"`
+}
+
+type HeadToHeadRound struct {
+	CompetitionID   uint           `json:"CompetitionID"`
+	CompetitionName string         `json:"CompetitionName"`
+	EventID         EventID        `json:"EventID"`
+	EventName       string         `json:"EventName"`
+	Round           string         `json:"Round"`
+	RoundNumber     int            `json:"RoundNumber"`
+	A               result.Results `json:"A"`
+	B               result.Results `json:"B"`
+	Winner          string         `json:"Winner"`
+}
@@ -0,0 +1,27 @@
+package job
+
+import (
+	"github.com/guojia99/cubing-pro/src/configs"
+	"github.com/guojia99/cubing-pro/src/internel/convenient/interface"
+	"github.com/guojia99/cubing-pro/src/robot/qq_bot/Better-Bot-Go/log"
+	"gorm.io/gorm"
+)
+
+// PreResultExpireJob 按比赛的预录入规则, 将轮次关闭后仍未处理的预录入成绩置为过期
+type PreResultExpireJob struct {
+	DB  *gorm.DB
+	Cfg configs.GlobalConfig
+}
+
+func (c *PreResultExpireJob) Name() string {
+	return "PreResultExpireJob"
+}
+
+func (c *PreResultExpireJob) Run() error {
+	iter := _interface.PreResultIter{DB: c.DB, Cfg: c.Cfg}
+	num, err := iter.ExpirePreResults()
+	if num > 0 {
+		log.Infof("[PreResultExpireJob] expire %d pre results", num)
+	}
+	return err
+}
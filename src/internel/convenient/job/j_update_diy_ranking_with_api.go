@@ -3,105 +3,31 @@ package job
 import (
 	"sort"
 	"strings"
-	"time"
 
 	wca_model "github.com/guojia99/cubing-pro/src/internel/database/model/wca"
 	"github.com/guojia99/cubing-pro/src/internel/database/model/wca/utils"
 	utils2 "github.com/guojia99/cubing-pro/src/internel/utils"
-	"github.com/guojia99/cubing-pro/src/wca/types"
+	"github.com/guojia99/cubing-pro/src/internel/wca_api"
 )
 
-func PersonBeWcaDBToCubingProDB(in types.PersonInfo) wca_model.PersonBestResults {
-	out := wca_model.PersonBestResults{
-		DBVersion:        time.Now().Format(time.DateTime),
-		PersonName:       in.PersonName,
-		WCAID:            in.WcaID,
-		Best:             make(map[string]wca_model.Results),
-		Avg:              make(map[string]wca_model.Results),
-		CompetitionCount: in.CompetitionCount,
-		MedalCount: wca_model.MedalCount{
-			Gold:   in.MedalCount.Gold,
-			Silver: in.MedalCount.Silver,
-			Bronze: in.MedalCount.Bronze,
-			Total:  in.MedalCount.Total,
-		},
-		RecordCount: wca_model.RecordCount{
-			National:    in.RecordCount.National,
-			Continental: in.RecordCount.Continental,
-			World:       in.RecordCount.World,
-			Total:       in.RecordCount.Total,
-		},
-	}
-
-	for ev, rcs := range in.PersonalRecords {
-		if rcs.Best != nil {
-			out.Best[ev] = wca_model.Results{
-				EventId:       ev,
-				Best:          rcs.Best.Best,
-				BestStr:       rcs.Best.BestStr,
-				PersonName:    rcs.Best.PersonName,
-				PersonId:      rcs.Best.PersonId,
-				WorldRank:     rcs.Best.WorldRank,
-				ContinentRank: rcs.Best.ContinentRank,
-				CountryRank:   rcs.Best.CountryRank,
-				Rank:          rcs.Best.Rank,
-			}
-		}
-		if rcs.Avg != nil {
-			out.Avg[ev] = wca_model.Results{
-				EventId:       ev,
-				Average:       rcs.Avg.Best,
-				AverageStr:    rcs.Avg.BestStr,
-				PersonName:    rcs.Best.PersonName,
-				PersonId:      rcs.Best.PersonId,
-				WorldRank:     rcs.Best.WorldRank,
-				ContinentRank: rcs.Best.ContinentRank,
-				CountryRank:   rcs.Best.CountryRank,
-				Rank:          rcs.Best.Rank,
-			}
-		}
-	}
-
-	return out
-}
-
-func (u *UpdateDiyRankings) apiGetAllResult(WcaIDs []string) map[string]types.PersonInfo {
-	var out = make(map[string]types.PersonInfo)
+// apiGetAllResult 选手 PB 优先来自本地 WCA 数据库，快照之后才出现的选手才会请求 WCA 官网
+func (u *UpdateDiyRankings) apiGetAllResult(WcaIDs []string) map[string]wca_model.PersonBestResults {
+	var out = make(map[string]wca_model.PersonBestResults)
 
 	WcaIDs = utils2.RemoveRepeatedElement(WcaIDs)
-
-	var resultsCh []types.PersonInfo
+	resolver := wca_api.NewProfileResolver(u.DB, u.Wca)
 
 	for _, wcaId := range WcaIDs {
 		wcaId = strings.ToUpper(wcaId)
 		if len(wcaId) != 10 {
 			continue
 		}
-		//log.Printf("[apiGetAllResult] %+v\n", wcaId)
-		res, err := u.Wca.GetPersonInfo(wcaId)
-		//res, err := wca_api.GetWcaResultWithDbAndAPI(u.DB, wcaId)
+		res, err := resolver.Resolve(wcaId)
 		if err != nil {
 			//log.Printf("[apiGetAllResult] get wca %s error %+v\n", wcaId, err)
 			continue
 		}
-
-		// 缓存到数据库
-		var dbResult wca_model.WCAResult
-		if err = u.DB.Where("wca_id = ?", wcaId).First(&dbResult).Error; err == nil {
-			dbResult.PersonBestResults = PersonBeWcaDBToCubingProDB(res)
-		} else {
-			dbResult = wca_model.WCAResult{
-				WcaID:             wcaId,
-				PersonBestResults: PersonBeWcaDBToCubingProDB(res),
-			}
-		}
-		u.DB.Save(&dbResult)
-
-		resultsCh = append(resultsCh, res)
-	}
-
-	for _, res := range resultsCh {
-		out[res.PersonName] = res
+		out[res.PersonName] = *res
 	}
 	return out
 }
@@ -131,19 +57,15 @@ func (u *UpdateDiyRankings) apiGetSortResult(WcaIDs []string) map[string][]WcaRe
 	data := u.apiGetAllResult(WcaIDs)
 
 	for _, eid := range wcaEventsList {
-		var bests []types.PersonResult
-		var avgs []types.PersonResult
+		var bests []wca_model.Results
+		var avgs []wca_model.Results
 
 		for _, r := range data {
-			if _, ok := r.PersonalRecords[eid]; !ok {
-				continue
-			}
-
-			if r.PersonalRecords[eid].Best != nil {
-				bests = append(bests, *r.PersonalRecords[eid].Best)
+			if b, ok := r.Best[eid]; ok {
+				bests = append(bests, b)
 			}
-			if r.PersonalRecords[eid].Avg != nil {
-				avgs = append(avgs, *r.PersonalRecords[eid].Avg)
+			if a, ok := r.Avg[eid]; ok {
+				avgs = append(avgs, a)
 			}
 		}
 
@@ -152,7 +74,7 @@ func (u *UpdateDiyRankings) apiGetSortResult(WcaIDs []string) map[string][]WcaRe
 		})
 
 		sort.Slice(avgs, func(i, j int) bool {
-			return utils.IsBestResult(avgs[i].EventId, avgs[i].Best, avgs[j].Best)
+			return utils.IsBestResult(avgs[i].EventId, avgs[i].Average, avgs[j].Average)
 		})
 		var wrs []WcaResult
 		for idx, b := range bests {
@@ -172,11 +94,11 @@ func (u *UpdateDiyRankings) apiGetSortResult(WcaIDs []string) map[string][]WcaRe
 		for idx, a := range avgs {
 			var index = idx + 1
 
-			if idx >= 1 && wrs[idx-1].AvgStr == a.BestStr {
+			if idx >= 1 && wrs[idx-1].AvgStr == a.AverageStr {
 				index = wrs[idx-1].AvgRank
 			}
 			wrs[idx].AvgRank = index
-			wrs[idx].AvgStr = a.BestStr
+			wrs[idx].AvgStr = a.AverageStr
 			wrs[idx].AvgPersonName = a.PersonName
 			wrs[idx].AvgPersonWCAID = a.PersonId
 		}
@@ -50,6 +50,9 @@ type PersonPageResult struct {
 	Person         *PersonFields `json:"person,omitempty"`
 }
 
+// PersonDetailRegion 选手详情中的地区，如 "广东 深圳"
+const PersonDetailRegion = "地区"
+
 // PersonFields 从粗饼页面解析出的选手信息
 type PersonFields struct {
 	WcaID     string            `json:"wca_id"`
@@ -14,6 +14,9 @@ type CompetitionJson struct {
 
 	TNoodlePath    string `json:"TNoodlePath,omitempty"` // 保存TNoodle打乱内容的地方
 	TNoodlePDFPath string `json:"TNoodlePDFPath"`        // pdf
+
+	PreResultRule PreResultRule    `json:"PreResultRule,omitempty"` // 预录入成绩审核规则
+	ScrambleSeal  ScrambleSealRule `json:"ScrambleSeal,omitempty"`  // 密封打乱配置
 }
 
 type Cost struct {
@@ -123,4 +126,5 @@ type Schedule struct {
 	NotScramble  bool       `json:"NotScramble,omitempty"`  // 不需要打乱
 	Scrambles    [][]string `json:"Scrambles,omitempty"`    // 打乱
 	ScrambleNums int        `json:"ScrambleNums,omitempty"` // 打乱数
+	Sealed       bool       `json:"Sealed,omitempty"`       // 打乱已密封, 见 SealedScramble
 }
@@ -0,0 +1,17 @@
+package competition
+
+import "slices"
+
+// PreResultRule 预录入成绩的自动审核规则, 按比赛配置
+type PreResultRule struct {
+	AutoApproval    bool     `json:"AutoApproval,omitempty"`    // 开启自动审核
+	TrustedPlayers  []string `json:"TrustedPlayers,omitempty"`  // 信任选手CubeID, 提交即通过
+	WithinPBPercent float64  `json:"WithinPBPercent,omitempty"` // 成绩未快过个人最佳N%时自动通过, 0为不启用
+
+	AutoExpire         bool `json:"AutoExpire,omitempty"`         // 轮次关闭后自动过期未处理成绩
+	ExpireAfterMinutes int  `json:"ExpireAfterMinutes,omitempty"` // 轮次关闭多少分钟后过期
+}
+
+func (r PreResultRule) IsTrusted(cubeId string) bool {
+	return cubeId != "" && slices.Contains(r.TrustedPlayers, cubeId)
+}
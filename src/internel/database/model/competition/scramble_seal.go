@@ -0,0 +1,40 @@
+package competition
+
+import (
+	"time"
+
+	basemodel "github.com/guojia99/cubing-pro/src/internel/database/model/base"
+)
+
+// ScrambleSealRule 密封打乱配置, 用于线上比赛防止打乱提前泄露
+type ScrambleSealRule struct {
+	Enable    bool `json:"Enable,omitempty"`    // 打乱加密保存, 轮次开启后才公布
+	Staggered bool `json:"Staggered,omitempty"` // 逐把公布, 选手每次只能查看下一把打乱
+}
+
+// SealedScramble 密封的轮次打乱, Cipher 为加密后的 [][]string
+type SealedScramble struct {
+	basemodel.Model
+
+	CompID     uint       `gorm:"column:comp_id;index:idx_sealed_scramble,priority:1" json:"CompID"`
+	EventID    string     `gorm:"column:event_id;type:varchar(64);index:idx_sealed_scramble,priority:2" json:"EventID"`
+	RoundNum   int        `gorm:"column:round_num;index:idx_sealed_scramble,priority:3" json:"RoundNum"`
+	Cipher     string     `gorm:"column:cipher;type:text" json:"-"`
+	Staggered  bool       `gorm:"column:staggered" json:"Staggered"`    // 逐把公布
+	Released   bool       `gorm:"column:released" json:"Released"`      // 已公布
+	ReleasedAt *time.Time `gorm:"column:released_at" json:"ReleasedAt"` // 公布时间
+}
+
+// ScrambleViewLog 打乱查看记录, Index 为 -1 时表示查看整轮打乱
+type ScrambleViewLog struct {
+	basemodel.Model
+
+	CompID   uint   `gorm:"column:comp_id;index:idx_scramble_view_log,priority:1" json:"CompID"`
+	EventID  string `gorm:"column:event_id;type:varchar(64);index:idx_scramble_view_log,priority:2" json:"EventID"`
+	RoundNum int    `gorm:"column:round_num;index:idx_scramble_view_log,priority:3" json:"RoundNum"`
+	UserID   uint   `gorm:"column:user_id;index:idx_scramble_view_log,priority:4" json:"UserID"`
+	CubeID   string `gorm:"column:cube_id" json:"CubeID"`
+	UserName string `gorm:"column:user_name" json:"UserName"`
+	Index    int    `gorm:"column:idx" json:"Index"`
+	IP       string `gorm:"column:ip" json:"IP"`
+}
@@ -24,6 +24,9 @@ type Event struct {
 	ScrambleValue   string `gorm:"column:scramble_value" json:"scrambleValue,omitempty" table:"-"` // 打乱ID []string
 	AutoScrambleKey string `gorm:"column:auto_scramble_key" json:"autoScrambleKey,omitempty" table:"-"`
 	PuzzleID        string `gorm:"column:puzzle_id" json:"puzzleId,omitempty" table:"-"`
+
+	ScrambleDefJSON string              `gorm:"column:scramble_def" json:"-" table:"-"`   // 自定义打乱规则JSON
+	ScrambleDef     *ScrambleDefinition `gorm:"-" json:"scrambleDef,omitempty" table:"-"` // 自定义打乱规则, 优先于 ScrambleValue
 }
 
 func (e *Event) ScrambleValues() []string {
@@ -0,0 +1,37 @@
+package event
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"gorm.io/gorm"
+)
+
+// ScrambleDefinition 自定义项目的打乱规则, 新增趣味项目时由管理员配置, 不需要改代码
+// 随机转动(Moves)与组合(Compose)二选一
+type ScrambleDefinition struct {
+	// 随机转动
+	Moves     []string `json:"moves,omitempty"`     // 转动的面, 如 U D R L F B
+	Axis      []int    `json:"axis,omitempty"`      // 每个转动所在的轴, 同轴的连续转动只按 Moves 的顺序各出现一次; 为空时每个转动自成一轴, 只避免连续转同一面
+	Suffixes  []string `json:"suffixes,omitempty"`  // 转动方向, 为空时为 "" 和 "'"
+	MinLength int      `json:"minLength,omitempty"` // 最少步数
+	MaxLength int      `json:"maxLength,omitempty"` // 最多步数, 为空时与最少步数相同
+
+	// 组合已有的打乱, 如 ["333", "222"] 为三阶+二阶接力, 可以是WCA项目或其他定义了打乱的项目
+	Compose []string `json:"compose,omitempty"`
+}
+
+func (e *Event) updateSave() {
+	e.ScrambleDefJSON = ""
+	if e.ScrambleDef != nil {
+		e.ScrambleDefJSON, _ = jsoniter.MarshalToString(e.ScrambleDef)
+	}
+}
+
+func (e *Event) updateFind() {
+	if e.ScrambleDefJSON != "" {
+		e.ScrambleDef = &ScrambleDefinition{}
+		_ = jsoniter.UnmarshalFromString(e.ScrambleDefJSON, e.ScrambleDef)
+	}
+}
+
+func (e *Event) BeforeSave(*gorm.DB) error { e.updateSave(); return nil }
+func (e *Event) AfterFind(*gorm.DB) error  { e.updateFind(); return nil }
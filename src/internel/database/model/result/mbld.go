@@ -0,0 +1,108 @@
+package result
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	MBLDEventID          = "333mbf"
+	MBLDTimeLimitPerCube = 10 * 60 // 多盲每个魔方10分钟
+	MBLDMaxTimeLimit     = 60 * 60 // 多盲时限上限60分钟
+	MBLDMinAttempted     = 2       // 多盲最少尝试数
+)
+
+// MBLD WCA多盲成绩
+// 分数 = 还原数 - 未还原数, 同分时用时少者优, 再同则未还原数少者优
+// 还原数少于2个或分数为负时记为DNF
+type MBLD struct {
+	Solved    int     // 还原数
+	Attempted int     // 尝试数
+	Time      float64 // 用时(秒)
+}
+
+func NewMBLD(solved, attempted float64, time float64) MBLD {
+	return MBLD{Solved: int(solved), Attempted: int(attempted), Time: time}
+}
+
+// mbldPattern 多盲成绩 "x/y time", 兼容WCA的 "x/y in time"
+var mbldPattern = regexp.MustCompile(`^(\d+)\s*/\s*(\d+)\s+(?:in\s+)?(\S+)$`)
+
+// ParseMBLD 解析 "5/6 45:12" 或 "5/6 in 45:12" 格式的多盲成绩, 超过时限的成绩合法但为DNF
+func ParseMBLD(in string) (MBLD, error) {
+	in = strings.TrimSpace(in)
+	match := mbldPattern.FindStringSubmatch(in)
+	if match == nil {
+		return MBLD{}, fmt.Errorf("多盲成绩`%s`不符合'x/y time'的格式", in)
+	}
+	solved, err1 := strconv.Atoi(match[1])
+	attempted, err2 := strconv.Atoi(match[2])
+	if err1 != nil || err2 != nil {
+		return MBLD{}, fmt.Errorf("多盲成绩`%s`还原数或尝试数错误", in)
+	}
+	m := MBLD{Solved: solved, Attempted: attempted, Time: TimeParserS2F(match[3])}
+	if m.Time <= DNF {
+		return MBLD{}, fmt.Errorf("多盲成绩`%s`时间格式错误", in)
+	}
+	return m, m.Validate()
+}
+
+func (m MBLD) Unsolved() int { return m.Attempted - m.Solved }
+
+// Points 分数
+func (m MBLD) Points() int { return m.Solved - m.Unsolved() }
+
+// TimeLimit 时限, 每个魔方10分钟, 最多60分钟
+func (m MBLD) TimeLimit() float64 {
+	return math.Min(float64(m.Attempted*MBLDTimeLimitPerCube), MBLDMaxTimeLimit)
+}
+
+// Validate 校验成绩是否合法, 合法的成绩仍可能是DNF(如超过时限)
+func (m MBLD) Validate() error {
+	switch {
+	case m.Attempted < MBLDMinAttempted:
+		return fmt.Errorf("多盲至少需要尝试%d个魔方", MBLDMinAttempted)
+	case m.Solved < 0 || m.Solved > m.Attempted:
+		return errors.New("多盲还原数需在0到尝试数之间")
+	case m.Time <= 0:
+		return errors.New("多盲用时需大于0")
+	}
+	return nil
+}
+
+func (m MBLD) DNF() bool {
+	return m.Solved < 2 || m.Points() < 0 || m.Time <= 0 || m.Time > m.TimeLimit()
+}
+
+// IsBest 是否优于或等于另一个成绩
+func (m MBLD) IsBest(other MBLD) bool {
+	if m.DNF() || other.DNF() {
+		return !m.DNF() || other.DNF()
+	}
+	return m.rankBefore(other)
+}
+
+// rankBefore 不判断DNF, 按分数、用时、未还原数排序
+func (m MBLD) rankBefore(other MBLD) bool {
+	if m.Points() != other.Points() {
+		return m.Points() > other.Points()
+	}
+	if m.Time != other.Time {
+		return m.Time < other.Time
+	}
+	return m.Unsolved() <= other.Unsolved()
+}
+
+// TimeString 多盲时间只精确到秒, 如 45:12
+func (m MBLD) TimeString() string {
+	sec := int(math.Floor(m.Time))
+	return fmt.Sprintf("%d:%02d", sec/60, sec%60)
+}
+
+func (m MBLD) String() string {
+	return fmt.Sprintf("%d/%d %s", m.Solved, m.Attempted, m.TimeString())
+}
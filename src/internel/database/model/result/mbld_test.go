@@ -0,0 +1,295 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+)
+
+func TestMBLD_Points(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       MBLD
+		points  int
+		wantDNF bool
+	}{
+		{name: "5/6", m: MBLD{Solved: 5, Attempted: 6, Time: 2712}, points: 4},
+		{name: "2/2", m: MBLD{Solved: 2, Attempted: 2, Time: 112}, points: 2},
+		{name: "2/4 零分有效", m: MBLD{Solved: 2, Attempted: 4, Time: 1800}, points: 0},
+		{name: "1/2 还原少于2个", m: MBLD{Solved: 1, Attempted: 2, Time: 300}, points: 0, wantDNF: true},
+		{name: "2/5 负分", m: MBLD{Solved: 2, Attempted: 5, Time: 1800}, points: -1, wantDNF: true},
+		{name: "3/3 超时", m: MBLD{Solved: 3, Attempted: 3, Time: 1801}, points: 3, wantDNF: true},
+		{name: "41/41", m: MBLD{Solved: 41, Attempted: 41, Time: 3600}, points: 41},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.Points(); got != tt.points {
+				t.Errorf("Points() = %v, want %v", got, tt.points)
+			}
+			if got := tt.m.DNF(); got != tt.wantDNF {
+				t.Errorf("DNF() = %v, want %v", got, tt.wantDNF)
+			}
+		})
+	}
+}
+
+func TestMBLD_TimeLimit(t *testing.T) {
+	tests := []struct {
+		attempted int
+		want      float64
+	}{
+		{attempted: 2, want: 20 * 60},
+		{attempted: 3, want: 30 * 60},
+		{attempted: 6, want: 60 * 60},
+		{attempted: 8, want: 60 * 60},
+		{attempted: 60, want: 60 * 60},
+	}
+	for _, tt := range tests {
+		if got := (MBLD{Attempted: tt.attempted}).TimeLimit(); got != tt.want {
+			t.Errorf("TimeLimit(%d) = %v, want %v", tt.attempted, got, tt.want)
+		}
+	}
+}
+
+func TestMBLD_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       MBLD
+		wantErr bool
+	}{
+		{name: "正常", m: MBLD{Solved: 5, Attempted: 6, Time: 2712}},
+		{name: "DNF也是合法成绩", m: MBLD{Solved: 1, Attempted: 2, Time: 600}},
+		{name: "只尝试1个", m: MBLD{Solved: 1, Attempted: 1, Time: 60}, wantErr: true},
+		{name: "还原数大于尝试数", m: MBLD{Solved: 3, Attempted: 2, Time: 60}, wantErr: true},
+		{name: "还原数为负", m: MBLD{Solved: -1, Attempted: 2, Time: 60}, wantErr: true},
+		{name: "无用时", m: MBLD{Solved: 2, Attempted: 2}, wantErr: true},
+		{name: "超过时限为DNF但合法", m: MBLD{Solved: 2, Attempted: 2, Time: 1201}},
+		{name: "超过60分钟上限为DNF但合法", m: MBLD{Solved: 10, Attempted: 10, Time: 3601}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.m.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMBLD_IsBest(t *testing.T) {
+	tests := []struct {
+		name        string
+		a, b        MBLD
+		aBetterThan bool
+	}{
+		{
+			name:        "分数高者优",
+			a:           MBLD{Solved: 10, Attempted: 10, Time: 3523},
+			b:           MBLD{Solved: 11, Attempted: 13, Time: 3599},
+			aBetterThan: true,
+		},
+		{
+			name:        "同分用时少者优",
+			a:           MBLD{Solved: 9, Attempted: 10, Time: 2700},
+			b:           MBLD{Solved: 8, Attempted: 8, Time: 3000},
+			aBetterThan: true,
+		},
+		{
+			name:        "同分同时未还原少者优",
+			a:           MBLD{Solved: 8, Attempted: 8, Time: 3000},
+			b:           MBLD{Solved: 9, Attempted: 10, Time: 3000},
+			aBetterThan: true,
+		},
+		{
+			name:        "DNF最差",
+			a:           MBLD{Solved: 2, Attempted: 4, Time: 1800},
+			b:           MBLD{Solved: 1, Attempted: 2, Time: 100},
+			aBetterThan: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.IsBest(tt.b); got != tt.aBetterThan {
+				t.Errorf("a.IsBest(b) = %v, want %v", got, tt.aBetterThan)
+			}
+			if got := tt.b.IsBest(tt.a); got == tt.aBetterThan {
+				t.Errorf("b.IsBest(a) = %v, want %v", got, !tt.aBetterThan)
+			}
+		})
+	}
+}
+
+func TestMBLD_String(t *testing.T) {
+	tests := []struct {
+		m    MBLD
+		want string
+	}{
+		{m: MBLD{Solved: 5, Attempted: 6, Time: 2712.37}, want: "5/6 45:12"},
+		{m: MBLD{Solved: 2, Attempted: 2, Time: 58.99}, want: "2/2 0:58"},
+		{m: MBLD{Solved: 41, Attempted: 41, Time: 3600}, want: "41/41 60:00"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("String() = %v, want %v", got, tt.want)
+		}
+	}
+}
+
+func TestParseMBLD(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    MBLD
+		wantErr bool
+	}{
+		{in: "5/6 45:12", want: MBLD{Solved: 5, Attempted: 6, Time: 2712}},
+		{in: "21/23 in 53:12", want: MBLD{Solved: 21, Attempted: 23, Time: 3192}},
+		{in: "5/6", wantErr: true},
+		{in: "7/6 45:12", wantErr: true},
+		{in: "2/2 25:00", want: MBLD{Solved: 2, Attempted: 2, Time: 1500}},
+		{in: "2 / 3  in  1:00", want: MBLD{Solved: 2, Attempted: 3, Time: 60}},
+		{in: "2/3 win 1:00", wantErr: true},
+		{in: "2/3 in", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseMBLD(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMBLD() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseMBLD() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResults_updateBestAndAvgWithMBLD(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventID    string
+		route      event.RouteType
+		in         []float64
+		wantBest   float64
+		wantString string
+		wantErr    bool
+	}{
+		{
+			name:       "单轮",
+			eventID:    MBLDEventID,
+			route:      event.RouteTypeRepeatedly,
+			in:         []float64{5, 6, 2712},
+			wantBest:   4,
+			wantString: "5/6 45:12",
+		},
+		{
+			name:       "单轮DNF",
+			route:      event.RouteTypeRepeatedly,
+			in:         []float64{2, 5, 1800},
+			wantBest:   DNF,
+			wantString: "DNF",
+		},
+		{
+			name:       "三轮取同分用时少",
+			eventID:    MBLDEventID,
+			route:      event.RouteType3RepeatedlyBest,
+			in:         []float64{9, 10, 2700, 8, 8, 2400, 1, 2, 100},
+			wantBest:   8,
+			wantString: "8/8 40:00",
+		},
+		{
+			name:       "三轮未录入完",
+			eventID:    MBLDEventID,
+			route:      event.RouteType3RepeatedlyBest,
+			in:         []float64{2, 2, 600},
+			wantBest:   2,
+			wantString: "2/2 10:00",
+		},
+		{
+			name:       "多盲超时为DNF",
+			eventID:    MBLDEventID,
+			route:      event.RouteTypeRepeatedly,
+			in:         []float64{3, 3, 1900},
+			wantBest:   DNF,
+			wantString: "DNF",
+		},
+		{
+			name:       "多盲三轮超时不计入",
+			eventID:    MBLDEventID,
+			route:      event.RouteType3RepeatedlyBest,
+			in:         []float64{3, 3, 1900, 2, 2, 600},
+			wantBest:   2,
+			wantString: "2/2 10:00",
+		},
+		{
+			name:    "多盲只尝试1个",
+			eventID: MBLDEventID,
+			route:   event.RouteTypeRepeatedly,
+			in:      []float64{1, 1, 60},
+			wantErr: true,
+		},
+		{
+			name:       "其他计次项目不限时",
+			eventID:    "333bf_ry",
+			route:      event.RouteTypeRepeatedly,
+			in:         []float64{3, 3, 1900},
+			wantBest:   3,
+			wantString: "3/3 31:40.00",
+		},
+		{
+			name:       "其他计次项目不校验尝试数",
+			eventID:    "333bf_ry",
+			route:      event.RouteTypeRepeatedly,
+			in:         []float64{1, 1, 60},
+			wantBest:   DNF,
+			wantString: "DNF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Results{EventID: tt.eventID, EventRoute: tt.route, Result: tt.in}
+			err := c.updateBestAndAvg()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("updateBestAndAvg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if c.Best != tt.wantBest {
+				t.Errorf("updateBestAndAvg() best = %v, want %v", c.Best, tt.wantBest)
+			}
+			if got := c.BestString(); got != tt.wantString {
+				t.Errorf("BestString() = %v, want %v", got, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestSortResultWithMBLD(t *testing.T) {
+	// 同分同用时: 多盲按未还原数排名, 其他计次项目并列
+	newResults := func(eventID string) []Results {
+		out := []Results{
+			{EventID: eventID, EventRoute: event.RouteTypeRepeatedly, PersonName: "a", Result: []float64{5, 6, 600}},
+			{EventID: eventID, EventRoute: event.RouteTypeRepeatedly, PersonName: "b", Result: []float64{4, 4, 600}},
+		}
+		for i := range out {
+			if err := out[i].updateBestAndAvg(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return out
+	}
+
+	mbld := newResults(MBLDEventID)
+	SortResult(mbld)
+	if mbld[0].PersonName != "b" || mbld[0].Rank != 1 || mbld[1].Rank != 2 {
+		t.Errorf("mbld ranks = %s:%d %s:%d", mbld[0].PersonName, mbld[0].Rank, mbld[1].PersonName, mbld[1].Rank)
+	}
+
+	other := newResults("333bf_ry")
+	SortResult(other)
+	if other[0].Rank != 1 || other[1].Rank != 1 {
+		t.Errorf("other ranks = %d %d, want 1 1", other[0].Rank, other[1].Rank)
+	}
+	if !other[0].IsBest(other[1]) || !other[1].IsBest(other[0]) {
+		t.Error("other repeatedly events with the same points and time should tie")
+	}
+}
@@ -15,7 +15,7 @@ type PreResults struct {
 	Recorder     string `gorm:"column:recorder"`      // 记录人
 	Processor    string `gorm:"column:processor"`     // 处理人
 	ProcessorID  uint   `gorm:"column:processor_id"`  // 处理人ID
-	Finish       bool   `gorm:"column:finish"`        // 是否处理
+	Finish       bool   `gorm:"column:finish;index"`  // 是否处理
 	Detail       string `gorm:"column:detail"`        // 处理结果
 	FinishDetail string `gorm:"column:finish_detail"` // 最终处理结果
 	Source       string `gorm:"column:source"`        // 来源
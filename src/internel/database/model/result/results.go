@@ -19,6 +19,13 @@ const (
 
 type Penalty [][]float64
 
+// FmSolution 最少步提交的解法, 校验不通过时该把成绩记为DNF并保留原因
+type FmSolution struct {
+	Solution string `json:"Solution"`
+	Moves    int    `json:"Moves"`
+	Reason   string `json:"Reason,omitempty"`
+}
+
 type Results struct {
 	basemodel.Model
 
@@ -42,6 +49,10 @@ type Results struct {
 	PenaltyJSON string    `gorm:"column:penalty_json" json:"PenaltyJSON,omitempty"` // 判罚
 	Penalty     Penalty   `gorm:"-" json:"Penalty,omitempty"`                       // 判罚列表
 
+	// 最少步
+	FmSolutionsJSON string       `gorm:"column:fm_solutions_json" json:"-"` // 解法JSON
+	FmSolutions     []FmSolution `gorm:"-" json:"FmSolutions,omitempty"`    // 每一把提交的解法与校验结果
+
 	EventID    string          `gorm:"column:event_id" json:"EventID,omitempty"`      // 项目
 	EventName  string          `gorm:"column:event_name" json:"EventName,omitempty"`  // 项目名
 	EventRoute event.RouteType `gorm:"column:route_type" json:"EventRoute,omitempty"` // 项目类型
@@ -60,6 +71,11 @@ func (c *Results) updateSave() error {
 	if len(c.Penalty) != 0 {
 		c.PenaltyJSON, _ = jsoniter.MarshalToString(c.Penalty)
 	}
+	// 重新录入的成绩没有解法时需要清空旧的解法
+	c.FmSolutionsJSON = ""
+	if len(c.FmSolutions) != 0 {
+		c.FmSolutionsJSON, _ = jsoniter.MarshalToString(c.FmSolutions)
+	}
 	return nil
 }
 
@@ -70,6 +86,9 @@ func (c *Results) updateFind() error {
 	if len(c.PenaltyJSON) != 0 {
 		_ = jsoniter.UnmarshalFromString(c.PenaltyJSON, &c.Penalty)
 	}
+	if len(c.FmSolutionsJSON) != 0 {
+		_ = jsoniter.UnmarshalFromString(c.FmSolutionsJSON, &c.FmSolutions)
+	}
 	return nil
 }
 
@@ -88,6 +107,9 @@ func (c *Results) IsBest(other Results) bool    { return c.isBest(other) }
 func (c *Results) IsBestAvg(other Results) bool { return c.isBestAvg(other) }
 func (c *Results) BestString() string           { return c.bestString() }
 func (c *Results) BestAvgString() string        { return c.bestAvgString() }
+func (c *Results) BestMBLD() MBLD {
+	return NewMBLD(c.BestRepeatedlyReduction, c.BestRepeatedlyTry, c.BestRepeatedlyTime)
+}
 func (c *Results) EqualRepeatedly(other Results) bool {
 	return c.BestRepeatedlyReduction == other.BestRepeatedlyReduction &&
 		c.BestRepeatedlyTry == other.BestRepeatedlyTry &&
@@ -79,7 +79,20 @@ func (c *Results) updateBestAndAvg() error {
 
 	// 2. 计次项目
 	if c.EventRoute.RouteMap().Repeatedly {
-		list := sortRepeatedly(getRepeatedlyList(cache, c.EventRoute.RouteMap().Rounds))
+		list := getRepeatedlyList(cache, c.EventRoute.RouteMap().Rounds)
+		// WCA多盲校验录入的成绩, 超过时限的记为DNF; 其他计次项目沿用原有规则
+		if c.EventID == MBLDEventID {
+			for i, r := range list {
+				if !r.entered() {
+					continue
+				}
+				if err := r.MBLD().Validate(); err != nil {
+					return err
+				}
+				list[i].timeLimit = true
+			}
+		}
+		list = sortRepeatedly(list, c.EventID == MBLDEventID)
 		if !list[0].D() {
 			c.Best = list[0].N()
 			c.BestRepeatedlyTime = list[0].Time
@@ -102,6 +115,8 @@ type repeatedly struct {
 	Reduction float64 // 复原个数
 	Try       float64 // 尝试个数
 	Time      float64
+
+	timeLimit bool // 按WCA多盲时限判断DNF
 }
 
 func getRepeatedlyList(in []float64, roundNum int) []repeatedly {
@@ -111,16 +126,22 @@ func getRepeatedlyList(in []float64, roundNum int) []repeatedly {
 	list := make([]repeatedly, 0)
 
 	for i := 0; i < len(in) && roundNum > 0; i, roundNum = i+3, roundNum-3 {
-		list = append(list, repeatedly{in[i], in[i+1], in[i+2]})
+		list = append(list, repeatedly{Reduction: in[i], Try: in[i+1], Time: in[i+2]})
 	}
 	return list
 }
 
+func (r repeatedly) MBLD() MBLD { return NewMBLD(r.Reduction, r.Try, r.Time) }
+
+// D 还原少于2个或分数为负时为DNF, WCA多盲另外判断时限
 func (r repeatedly) D() bool {
-	if r.Reduction < 2 {
+	if r.Reduction <= DNF || r.Try <= DNF || r.Time <= DNF {
 		return true
 	}
-	return r.N() < 0
+	if r.timeLimit {
+		return r.MBLD().DNF()
+	}
+	return r.Reduction < 2 || r.N() < 0
 }
 
 // N  分数
@@ -128,14 +149,23 @@ func (r repeatedly) N() float64 {
 	return r.Reduction - (r.Try - r.Reduction)
 }
 
-func sortRepeatedly(in []repeatedly) []repeatedly {
-	sort.Slice(
+// entered 是否录入了成绩, 未录入的(DNS等)不做校验
+func (r repeatedly) entered() bool {
+	return r.Reduction > DNF && r.Try > DNF && r.Time > 0
+}
+
+// sortRepeatedly mbld 为 true 时按WCA多盲规则排序
+func sortRepeatedly(in []repeatedly, mbld bool) []repeatedly {
+	sort.SliceStable(
 		in, func(i, j int) bool {
 			ir := in[i]
 			ij := in[j]
 			// 还原需要大于尝试数 还原数必须多于两把
 			if ir.D() || ij.D() {
-				return ij.D()
+				return !ir.D() && ij.D()
+			}
+			if mbld {
+				return ir.MBLD().rankBefore(ij.MBLD()) && !ij.MBLD().rankBefore(ir.MBLD())
 			}
 
 			if ir.N() == ij.N() {
@@ -206,6 +236,9 @@ func (c *Results) isBest(other Results) bool {
 		return !c.DBest()
 	}
 
+	if c.EventID == MBLDEventID {
+		return c.BestMBLD().rankBefore(other.BestMBLD())
+	}
 	if c.Best == other.Best {
 		if c.EventRoute.RouteMap().Repeatedly {
 			return c.BestRepeatedlyTime <= other.BestRepeatedlyTime
@@ -304,7 +337,7 @@ func SortResult(in []Results) {
 	prev := in[0]
 	for i := 1; i < len(in); i++ {
 		if rom.WithBest {
-			if in[i].Best == prev.Best {
+			if (in[i].EventID == MBLDEventID && in[i].EqualRepeatedly(prev)) || (in[i].EventID != MBLDEventID && in[i].Best == prev.Best) {
 				in[i].Rank = prev.Rank
 				continue
 			}
@@ -403,6 +436,9 @@ func (c *Results) bestString() string {
 	if c.DBest() {
 		return "DNF"
 	}
+	if c.EventID == MBLDEventID {
+		return c.BestMBLD().String()
+	}
 	if c.EventRoute.RouteMap().Repeatedly {
 		return fmt.Sprintf("%d/%d %s", int(c.BestRepeatedlyReduction), int(c.BestRepeatedlyTry), TimeParserF2S(c.BestRepeatedlyTime))
 	}
@@ -108,7 +108,7 @@ func Test_sortRepeatedly(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
-				if got := sortRepeatedly(tt.args.in); !reflect.DeepEqual(got, tt.want) {
+				if got := sortRepeatedly(tt.args.in, false); !reflect.DeepEqual(got, tt.want) {
 					t.Errorf("sortRepeatedly() = %v, want %v", got, tt.want)
 				}
 			},
@@ -2,18 +2,29 @@ package wca_model
 
 import (
 	"strings"
+	"time"
 
 	basemodel "github.com/guojia99/cubing-pro/src/internel/database/model/base"
 	jsoniter "github.com/json-iterator/go"
 	"gorm.io/gorm"
 )
 
+// WCAResult 数据来源
+const (
+	WCAResultSourceDump = "dump" // 本地 WCA 数据库快照
+	WCAResultSourceAPI  = "api"  // WCA 官网，仅快照中还没有的选手
+)
+
 type WCAResult struct {
 	basemodel.Model
 
 	WcaID                   string            `gorm:"column:wca_id"`
 	PersonBestResults       PersonBestResults `gorm:"-"`
 	PersonBestResultsString string
+
+	Source    string    `gorm:"column:source"`     // dump / api
+	Snapshot  string    `gorm:"column:snapshot"`   // 数据对应的 WCA 数据库快照
+	FetchedAt time.Time `gorm:"column:fetched_at"` // 最近一次请求 WCA 官网的时间
 }
 
 func (w *WCAResult) TableName() string { return "wca_results" }
@@ -0,0 +1,147 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/xuri/excelize/v2"
+)
+
+type Format = string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+	FormatJSON Format = "json"
+)
+
+var ErrUnknownFormat = errors.New("不支持的导出格式, 仅支持 csv / xlsx / json")
+
+// Table 一张导出表, xlsx 中为一个sheet
+type Table struct {
+	Name   string
+	Header []string
+	Rows   [][]string
+}
+
+func CheckFormat(format Format) error {
+	switch format {
+	case FormatCSV, FormatXLSX, FormatJSON:
+		return nil
+	}
+	return ErrUnknownFormat
+}
+
+func ContentType(format Format) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/json; charset=utf-8"
+}
+
+func FileName(name string, format Format) string {
+	return fmt.Sprintf("%s.%s", name, format)
+}
+
+// Write 按格式写出多张表
+// csv 多张表之间以空行分隔, 每张表前一行为表名; json 为 {表名: [{列名: 值}]}
+func Write(w io.Writer, format Format, tables ...Table) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, tables)
+	case FormatXLSX:
+		return writeXLSX(w, tables)
+	case FormatJSON:
+		return writeJSON(w, tables)
+	}
+	return ErrUnknownFormat
+}
+
+func writeCSV(w io.Writer, tables []Table) error {
+	// 写入BOM, 避免excel打开中文乱码
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	for idx, t := range tables {
+		if len(tables) > 1 {
+			if idx > 0 {
+				_ = cw.Write([]string{})
+			}
+			_ = cw.Write([]string{t.Name})
+		}
+		_ = cw.Write(t.Header)
+		if err := cw.WriteAll(t.Rows); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeXLSX(w io.Writer, tables []Table) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for idx, t := range tables {
+		sheet := t.Name
+		if idx == 0 {
+			if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+				return err
+			}
+		} else if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+
+		sw, err := f.NewStreamWriter(sheet)
+		if err != nil {
+			return err
+		}
+		if err = sw.SetRow("A1", toCells(t.Header)); err != nil {
+			return err
+		}
+		for n, row := range t.Rows {
+			cell, _ := excelize.CoordinatesToCellName(1, n+2)
+			if err = sw.SetRow(cell, toCells(row)); err != nil {
+				return err
+			}
+		}
+		if err = sw.Flush(); err != nil {
+			return err
+		}
+	}
+	_, err := f.WriteTo(w)
+	return err
+}
+
+func toCells(in []string) []interface{} {
+	out := make([]interface{}, len(in))
+	for i, v := range in {
+		out[i] = v
+	}
+	return out
+}
+
+func writeJSON(w io.Writer, tables []Table) error {
+	var out = make(map[string][]map[string]string, len(tables))
+	for _, t := range tables {
+		rows := make([]map[string]string, 0, len(t.Rows))
+		for _, row := range t.Rows {
+			m := make(map[string]string, len(t.Header))
+			for i, h := range t.Header {
+				if i < len(row) {
+					m[h] = row[i]
+				}
+			}
+			rows = append(rows, m)
+		}
+		out[t.Name] = rows
+	}
+	return jsoniter.NewEncoder(w).Encode(out)
+}
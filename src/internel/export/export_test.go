@@ -0,0 +1,144 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/xuri/excelize/v2"
+
+	basemodel "github.com/guojia99/cubing-pro/src/internel/database/model/base"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
+	"github.com/guojia99/cubing-pro/src/internel/utils"
+)
+
+func testResults() []result.Results {
+	r1 := result.Results{
+		Model:           basemodel.Model{ID: 1},
+		CompetitionID:   10,
+		CompetitionName: "测试赛",
+		Round:           "初赛",
+		PersonName:      "张三",
+		CubeID:          "2020ZHAN01",
+		EventName:       "三阶",
+		EventRoute:      event.RouteType5RoundsAvgHT,
+		Result:          []float64{10, 11, 12, result.DNF, 9},
+		Penalty:         result.Penalty{{2}},
+		Rank:            1,
+	}
+	r2 := result.Results{
+		Model:           basemodel.Model{ID: 2},
+		CompetitionID:   10,
+		CompetitionName: "测试赛",
+		Round:           "决赛",
+		PersonName:      "张三",
+		CubeID:          "2020ZHAN01",
+		EventID:         result.MBLDEventID,
+		EventName:       "多盲",
+		EventRoute:      event.RouteTypeRepeatedly,
+		Result:          []float64{5, 6, 2712},
+	}
+	_ = r1.Update()
+	_ = r2.Update()
+	return []result.Results{r1, r2}
+}
+
+func testTable() Table {
+	dates := map[uint]time.Time{10: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	records := RecordsByResult([]result.Record{{ResultId: 1, Type: result.RecordTypeWithCubingPro, Best: utils.Ptr(9.0)}})
+	return ResultsTable("成绩", testResults(), dates, records)
+}
+
+func TestResultsTable(t *testing.T) {
+	table := testTable()
+
+	wantHeader := []string{"比赛", "日期", "项目", "轮次", "排名", "选手", "CubeID", "单次", "平均", "成绩1", "成绩2", "成绩3", "成绩4", "成绩5", "判罚", "记录"}
+	if !reflect.DeepEqual(table.Header, wantHeader) {
+		t.Fatalf("Header = %v", table.Header)
+	}
+
+	wantRows := [][]string{
+		{"测试赛", "2024-05-01", "三阶", "初赛", "1", "张三", "2020ZHAN01", "9.00", "11.00", "10.00", "11.00", "12.00", "DNF", "9.00", "[[2]]", "CR(单次)"},
+		{"测试赛", "2024-05-01", "多盲", "决赛", "", "张三", "2020ZHAN01", "5/6 45:12", "", "5/6 45:12", "", "", "", "", "", ""},
+	}
+	if !reflect.DeepEqual(table.Rows, wantRows) {
+		t.Errorf("Rows = %v\nwant %v", table.Rows, wantRows)
+	}
+}
+
+func TestAttemptStrings(t *testing.T) {
+	r := result.Results{EventID: "333bf_ry", EventRoute: event.RouteType3RepeatedlyBest, Result: []float64{5, 6, 2712.5, 0, 0, result.DNF}}
+	want := []string{"5/6 45:12.50", "DNF"}
+	if got := AttemptStrings(r); !reflect.DeepEqual(got, want) {
+		t.Errorf("AttemptStrings() = %v, want %v", got, want)
+	}
+
+	r.EventID = result.MBLDEventID
+	want = []string{"5/6 45:12", "DNF"}
+	if got := AttemptStrings(r); !reflect.DeepEqual(got, want) {
+		t.Errorf("AttemptStrings() = %v, want %v", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	table := testTable()
+	other := Table{Name: "报名", Header: []string{"选手"}, Rows: [][]string{{"张三"}}}
+
+	t.Run(FormatCSV, func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatCSV, table); err != nil {
+			t.Fatal(err)
+		}
+		rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\xEF\xBB\xBF"))).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 3 || !reflect.DeepEqual(rows[0], table.Header) || !reflect.DeepEqual(rows[1], table.Rows[0]) {
+			t.Errorf("csv = %v", rows)
+		}
+	})
+
+	t.Run(FormatXLSX, func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatXLSX, table, other); err != nil {
+			t.Fatal(err)
+		}
+		f, err := excelize.OpenReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if got := f.GetSheetList(); !reflect.DeepEqual(got, []string{"成绩", "报名"}) {
+			t.Fatalf("sheets = %v", got)
+		}
+		rows, _ := f.GetRows("成绩")
+		if len(rows) != 3 || !reflect.DeepEqual(rows[1], table.Rows[0]) {
+			t.Errorf("xlsx = %v", rows)
+		}
+	})
+
+	t.Run(FormatJSON, func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatJSON, table, other); err != nil {
+			t.Fatal(err)
+		}
+		var out map[string][]map[string]string
+		if err := jsoniter.Unmarshal(buf.Bytes(), &out); err != nil {
+			t.Fatal(err)
+		}
+		if len(out["成绩"]) != 2 || out["成绩"][0]["成绩4"] != "DNF" || out["报名"][0]["选手"] != "张三" {
+			t.Errorf("json = %v", out)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if err := Write(&bytes.Buffer{}, "pdf", table); err != ErrUnknownFormat {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package export
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/result"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/user"
+)
+
+var resultHeader = []string{"比赛", "日期", "项目", "轮次", "排名", "选手", "CubeID", "单次", "平均"}
+var resultTailHeader = []string{"判罚", "记录"}
+
+// ResultsTable 成绩表, 成绩需已按轮次排好名次; compDates 为比赛日期, records 按成绩ID索引
+func ResultsTable(name string, results []result.Results, compDates map[uint]time.Time, records map[uint][]result.Record) Table {
+	maxAttempts := 0
+	for _, r := range results {
+		if n := len(AttemptStrings(r)); n > maxAttempts {
+			maxAttempts = n
+		}
+	}
+
+	header := append([]string{}, resultHeader...)
+	for i := 1; i <= maxAttempts; i++ {
+		header = append(header, fmt.Sprintf("成绩%d", i))
+	}
+	header = append(header, resultTailHeader...)
+
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		date := ""
+		if d, ok := compDates[r.CompetitionID]; ok && !d.IsZero() {
+			date = d.Format(time.DateOnly)
+		}
+		rank := ""
+		if r.Rank > 0 {
+			rank = fmt.Sprint(r.Rank)
+		}
+		row := []string{r.CompetitionName, date, r.EventName, r.Round, rank, r.PersonName, r.CubeID, r.BestString(), r.BestAvgString()}
+
+		attempts := AttemptStrings(r)
+		for i := 0; i < maxAttempts; i++ {
+			if i < len(attempts) {
+				row = append(row, attempts[i])
+				continue
+			}
+			row = append(row, "")
+		}
+		row = append(row, penaltyString(r.Penalty), recordString(records[r.ID]))
+		rows = append(rows, row)
+	}
+	return Table{Name: name, Header: header, Rows: rows}
+}
+
+// AttemptStrings 每一把的成绩, 多次尝试项目按 还原数/尝试数 时间 渲染, 多盲时间只精确到秒
+func AttemptStrings(r result.Results) []string {
+	rm := r.EventRoute.RouteMap()
+	var out []string
+	if rm.Repeatedly {
+		for i := 0; i+2 < len(r.Result); i += 3 {
+			if r.Result[i+2] <= result.DNF {
+				out = append(out, result.TimeParserF2S(r.Result[i+2]))
+				continue
+			}
+			if r.EventID == result.MBLDEventID {
+				out = append(out, result.NewMBLD(r.Result[i], r.Result[i+1], r.Result[i+2]).String())
+				continue
+			}
+			out = append(out, fmt.Sprintf("%d/%d %s", int(r.Result[i]), int(r.Result[i+1]), result.TimeParserF2S(r.Result[i+2])))
+		}
+		return out
+	}
+	for _, v := range r.Result {
+		if rm.Integer && v > result.DNF {
+			out = append(out, fmt.Sprintf("%d", int(v)))
+			continue
+		}
+		out = append(out, result.TimeParserF2S(v))
+	}
+	return out
+}
+
+func penaltyString(p result.Penalty) string {
+	if len(p) == 0 {
+		return ""
+	}
+	s, _ := jsoniter.MarshalToString(p)
+	return s
+}
+
+func recordString(records []result.Record) string {
+	var out []string
+	for _, rec := range records {
+		switch {
+		case rec.Best != nil:
+			out = append(out, rec.Type+"(单次)")
+		case rec.Average != nil:
+			out = append(out, rec.Type+"(平均)")
+		default:
+			out = append(out, rec.Type)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// FlattenCompResults 将比赛成绩按 项目 / 轮次 / 名次 展开
+func FlattenCompResults(in map[string]map[int][]result.Results) []result.Results {
+	var out []result.Results
+	var events []string
+	for ev := range in {
+		events = append(events, ev)
+	}
+	slices.Sort(events)
+	for _, ev := range events {
+		var rounds []int
+		for round := range in[ev] {
+			rounds = append(rounds, round)
+		}
+		slices.Sort(rounds)
+		for _, round := range rounds {
+			out = append(out, in[ev][round]...)
+		}
+	}
+	return out
+}
+
+// RecordsByResult 按成绩ID索引记录
+func RecordsByResult(records []result.Record) map[uint][]result.Record {
+	var out = make(map[uint][]result.Record)
+	for _, rec := range records {
+		out[rec.ResultId] = append(out[rec.ResultId], rec)
+	}
+	return out
+}
+
+var registrationHeader = []string{"选手", "CubeID", "状态", "报名时间", "通过时间", "退赛时间", "报名项目", "报名费"}
+
+// RegistrationsTable 报名信息表, users 按用户ID索引
+func RegistrationsTable(name string, regs []competition.Registration, users map[uint]user.User) Table {
+	rows := make([][]string, 0, len(regs))
+	for _, reg := range regs {
+		if len(reg.Payments) == 0 && reg.PaymentsJSON != "" {
+			_ = jsoniter.UnmarshalFromString(reg.PaymentsJSON, &reg.Payments)
+		}
+		var cost float64
+		for _, p := range reg.Payments {
+			cost += p.BaseResult
+			for _, e := range p.EventResults {
+				cost += e
+			}
+		}
+		rows = append(rows, []string{
+			reg.UserName,
+			users[reg.UserID].CubeID,
+			reg.Status,
+			timeString(&reg.RegistrationTime),
+			timeString(reg.AcceptationTime),
+			timeString(reg.RetireTime),
+			strings.Join(reg.EventsList(), ","),
+			fmt.Sprintf("%.2f", cost),
+		})
+	}
+	return Table{Name: name, Header: registrationHeader, Rows: rows}
+}
+
+func timeString(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateTime)
+}
@@ -1,45 +1,69 @@
 package scramble
 
 import (
+	"errors"
 	"math/rand"
+	"strings"
 )
 
+// autoScrambleKey 随机转动打乱的规则
+// 同轴的转动互相可交换, 打乱中同轴的连续转动只按 Moves 中的顺序各出现一次,
+// 这样不会出现 R R'、R L R 这类可以抵消或合并的序列, 每一步都会改变状态
+type autoScrambleKey struct {
+	Moves    []string // 转动的面
+	Axis     []int    // 每个转动所在的轴
+	Suffixes []string // 转动方向
+	Length   int      // 打乱步数
+}
+
 var (
-	FTOScrambleKey = [2][]string{
-		{"R", "B", "L", "D", "F", "U"},             // 前半段
-		{"R", "B", "L", "D", "F", "U", "BR", "BL"}, // 后半段
+	// FTOScrambleKey 八面体对面的转动互不影响, 视为同轴
+	FTOScrambleKey = autoScrambleKey{
+		Moves:    []string{"U", "D", "F", "B", "R", "BL", "L", "BR"},
+		Axis:     []int{0, 0, 1, 1, 2, 2, 3, 3},
+		Suffixes: []string{"", "'"},
+		Length:   30,
 	}
 
-	Cube444ScrambleKey = [2][]string{
-		{"R", "B", "L", "D", "F", "U"},
-		{"R", "B", "L", "D", "F", "U", "Fw", "Rw", "Uw"},
+	// Cube444ScrambleKey 同WCA四阶的转动集合
+	Cube444ScrambleKey = autoScrambleKey{
+		Moves:    []string{"U", "Uw", "D", "R", "Rw", "L", "F", "Fw", "B"},
+		Axis:     []int{0, 0, 0, 1, 1, 1, 2, 2, 2},
+		Suffixes: []string{"", "'", "2"},
+		Length:   40,
 	}
 )
 
-func (s *scramble) autoScramble(keys [2][]string, min, max int, group int) []string {
+func (s *scramble) autoScramble(key autoScrambleKey, group int) ([]string, error) {
 	var out []string
 	for i := 0; i < group; i++ {
-		data, curNum, last := "", rand.Intn(max-min+1)+min, ""
-		for j := 0; j < curNum; {
-			list := keys[0]
-			if j > min/2 {
-				list = keys[1]
-			}
+		sc, err := key.generate()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sc)
+	}
+	return out, nil
+}
 
-			randomIndex := rand.Intn(len(list))
-			randomKey := list[randomIndex]
-			if randomKey == last {
+func (k autoScrambleKey) generate() (string, error) {
+	moves := make([]string, 0, k.Length)
+	last := -1 // 上一步转动的下标
+	candidates := make([]int, 0, len(k.Moves))
+	for len(moves) < k.Length {
+		candidates = candidates[:0]
+		for m := range k.Moves {
+			// 与上一步同轴时只能按顺序往后选
+			if last >= 0 && k.Axis[m] == k.Axis[last] && m <= last {
 				continue
 			}
-			data += randomKey
-			if pr := rand.Intn(2) == 1; pr {
-				data += "'"
-			}
-			data += " "
-			last = randomKey
-			j++
+			candidates = append(candidates, m)
+		}
+		if len(candidates) == 0 {
+			return "", errors.New("没有可选的转动, 需要至少两个轴")
 		}
-		out = append(out, data)
+		last = candidates[rand.Intn(len(candidates))]
+		moves = append(moves, k.Moves[last]+k.Suffixes[rand.Intn(len(k.Suffixes))])
 	}
-	return out
+	return strings.Join(moves, " "), nil
 }
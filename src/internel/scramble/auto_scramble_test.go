@@ -1,22 +1,133 @@
 package scramble
 
 import (
+	"math"
+	"strings"
 	"testing"
+
+	"github.com/guojia99/cubing-pro/src/internel/scramble/puzzle"
 )
 
 func Test_scramble_autoScramble(t *testing.T) {
-
 	s := &scramble{}
 
-	t.Run("fto", func(t *testing.T) {
-		for _, i := range s.autoScramble(FTOScrambleKey, 25, 30, 10) {
-			t.Logf("fto: %s\n", i)
+	for name, key := range map[string]autoScrambleKey{"fto": FTOScrambleKey, "444": Cube444ScrambleKey} {
+		t.Run(name, func(t *testing.T) {
+			axis := make(map[string]int)
+			index := make(map[string]int)
+			for i, m := range key.Moves {
+				axis[m], index[m] = key.Axis[i], i
+			}
+
+			scrambles, err := s.autoScramble(key, 200)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, sc := range scrambles {
+				moves := strings.Fields(sc)
+				if len(moves) != key.Length {
+					t.Fatalf("%s 步数 %d", sc, len(moves))
+				}
+				last := ""
+				for _, mv := range moves {
+					face := strings.TrimRight(mv, "'2")
+					if _, ok := axis[face]; !ok {
+						t.Fatalf("%s 出现未知转动 %s", sc, mv)
+					}
+					if last != "" && axis[face] == axis[last] && index[face] <= index[last] {
+						t.Fatalf("%s 中 %s %s 可合并或交换", sc, last, face)
+					}
+					last = face
+				}
+			}
+		})
+	}
+}
+
+// stickerDistribution 每个贴片位置上各颜色出现的频率
+func stickerDistribution(t *testing.T, ev string, scrambles []string) [][]float64 {
+	var out [][]float64
+	for _, sc := range scrambles {
+		p, err := puzzle.New(ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = p.Apply(sc); err != nil {
+			t.Fatal(err)
+		}
+		colors := p.(interface{ Colors() []int }).Colors()
+		if out == nil {
+			out = make([][]float64, len(colors))
+			for i := range out {
+				out[i] = make([]float64, 8)
+			}
+		}
+		for i, c := range colors {
+			out[i][c] += 1 / float64(len(scrambles))
+		}
+	}
+	return out
+}
+
+func maxDistributionDiff(a, b [][]float64) float64 {
+	var out float64
+	for i := range a {
+		for c := range a[i] {
+			out = math.Max(out, math.Abs(a[i][c]-b[i][c]))
 		}
-	})
+	}
+	return out
+}
+
+// Test_autoScramble_coverage 打乱后每个位置的颜色分布应接近长随机序列(近似随机状态)的分布
+func Test_autoScramble_coverage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short")
+	}
+	const num = 1500
+	tests := []struct {
+		ev  string
+		key autoScrambleKey
+	}{
+		{ev: "fto", key: FTOScrambleKey},
+		{ev: "444", key: Cube444ScrambleKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ev, func(t *testing.T) {
+			random := tt.key
+			random.Length = 400
+			want := stickerDistribution(t, tt.ev, random.scrambles(num))
+
+			got := maxDistributionDiff(stickerDistribution(t, tt.ev, tt.key.scrambles(num)), want)
+			if got > 0.08 {
+				t.Errorf("%s 打乱颜色分布偏差 %.3f", tt.ev, got)
+			}
+
+			// 步数过少的打乱应能被检测出来
+			short := tt.key
+			short.Length = 4
+			if diff := maxDistributionDiff(stickerDistribution(t, tt.ev, short.scrambles(num)), want); diff <= 0.08 {
+				t.Errorf("%s 4步打乱偏差 %.3f, 检测无效", tt.ev, diff)
+			}
+		})
+	}
+}
+
+func (k autoScrambleKey) scrambles(num int) []string {
+	var out []string
+	for i := 0; i < num; i++ {
+		sc, _ := k.generate()
+		out = append(out, sc)
+	}
+	return out
+}
 
-	t.Run("444", func(t *testing.T) {
-		for _, i := range s.autoScramble(Cube444ScrambleKey, 39, 49, 10) {
-			t.Logf("444: %s\n", i)
+// Test_autoScrambleKey_generateSingleAxis 同轴的转动用完后返回错误而不是 panic
+func Test_autoScrambleKey_generateSingleAxis(t *testing.T) {
+	key := autoScrambleKey{Moves: []string{"U", "D"}, Axis: []int{0, 0}, Suffixes: []string{""}, Length: 3}
+	for i := 0; i < 20; i++ {
+		if _, err := key.generate(); err == nil {
+			t.Fatal("want error for single axis key")
 		}
-	})
+	}
 }
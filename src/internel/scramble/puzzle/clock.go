@@ -0,0 +1,140 @@
+package puzzle
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	clockDialRadius = 12.0
+	clockDialGap    = 34.0
+	clockRadius     = 62.0
+)
+
+var clockPalette = struct {
+	front, back, dial, hand, pinUp, pinDown string
+}{
+	front: "#3375B2", back: "#55CCFF", dial: "#FFFFFF", hand: "#FF0000", pinUp: "#FFFF00", pinDown: "#444444",
+}
+
+// 钉子顺序 UL UR DL DR, 以正面视角
+const (
+	clockUL = iota
+	clockUR
+	clockDL
+	clockDR
+)
+
+var (
+	clockPinQuadrant = [4][]int{{0, 1, 3, 4}, {1, 2, 4, 5}, {3, 4, 6, 7}, {4, 5, 7, 8}}
+	clockPinCorner   = [4]int{0, 2, 6, 8}
+	clockPinMirror   = [4]int{clockUR, clockUL, clockDR, clockDL}
+	clockPinNames    = map[string][]int{
+		"UL": {clockUL}, "UR": {clockUR}, "DL": {clockDL}, "DR": {clockDR},
+		"U": {clockUL, clockUR}, "R": {clockUR, clockDR}, "D": {clockDL, clockDR}, "L": {clockUL, clockDL},
+		"ALL": {clockUL, clockUR, clockDL, clockDR},
+	}
+	clockMoveRegexp = regexp.MustCompile(`^(UL|UR|DL|DR|ALL|U|R|D|L)(\d+)([+-])$`)
+)
+
+// Clock 魔表, 表盘按行排列, 背面使用从背面看的坐标, 数值为 0-11 的钟点
+type Clock struct {
+	front, back [9]int
+	pins        [4]bool // 正面视角下钉子是否按出(朝向正面)
+}
+
+func NewClock() *Clock { return &Clock{} }
+
+// Turn 以 pins 为按出的钉子转动 n 格, 正面顺时针为正
+func (c *Clock) Turn(pins []int, n int) {
+	c.pins = [4]bool{}
+	var moved [9]bool
+	for _, p := range pins {
+		c.pins[p] = true
+		for _, d := range clockPinQuadrant[p] {
+			moved[d] = true
+		}
+		// 角上的表盘正反面联动, 背面看方向相反
+		corner := clockPinCorner[clockPinMirror[p]]
+		c.back[corner] = ((c.back[corner]-n)%12 + 12) % 12
+	}
+	for d := range moved {
+		if moved[d] {
+			c.front[d] = ((c.front[d]+n)%12 + 12) % 12
+		}
+	}
+}
+
+// Flip y2 翻面
+func (c *Clock) Flip() {
+	c.front, c.back = c.back, c.front
+	var pins [4]bool
+	for p := range pins {
+		pins[p] = !c.pins[clockPinMirror[p]]
+	}
+	c.pins = pins
+}
+
+// Apply 执行WCA记号, 如 UR0+ ALL5+ y2 U1- , 末尾单独的钉子名表示最终按出的钉子
+func (c *Clock) Apply(scramble string) error {
+	var pinsReset bool
+	for _, move := range strings.Fields(scramble) {
+		if move == "y2" {
+			c.Flip()
+			continue
+		}
+		if pins, ok := clockPinNames[move]; ok {
+			if !pinsReset {
+				c.pins, pinsReset = [4]bool{}, true
+			}
+			for _, p := range pins {
+				c.pins[p] = true
+			}
+			continue
+		}
+		m := clockMoveRegexp.FindStringSubmatch(move)
+		if m == nil {
+			return fmt.Errorf("无法识别的转动 %s", move)
+		}
+		n, _ := strconv.Atoi(m[2])
+		if m[3] == "-" {
+			n = -n
+		}
+		c.Turn(clockPinNames[m[1]], n)
+		pinsReset = false
+	}
+	return nil
+}
+
+func (c *Clock) Solved() bool { return c.front == [9]int{} && c.back == [9]int{} }
+
+func (c *Clock) drawSide(svg *svgBuilder, dials [9]int, pins [4]bool, center vec2, face string) {
+	svg.circle(center, clockRadius, face)
+	for d, v := range dials {
+		p := center.add(vec2{float64(d%3-1) * clockDialGap, float64(d/3-1) * clockDialGap})
+		svg.circle(p, clockDialRadius, clockPalette.dial)
+		svg.line(p, p.add(polar(clockDialRadius*0.85, float64(v)*30)), clockPalette.hand, 2.5)
+	}
+	for p, up := range pins {
+		pos := center.add(vec2{float64(p%2*2-1) * clockDialGap / 2, float64(p/2*2-1) * clockDialGap / 2})
+		color := clockPalette.pinDown
+		if up {
+			color = clockPalette.pinUp
+		}
+		svg.circle(pos, 4, color)
+	}
+}
+
+func (c *Clock) SVG() string {
+	size := 2*clockRadius + 10
+	svg := newSvgBuilder(2*size, size)
+	c.drawSide(svg, c.front, c.pins, vec2{size / 2, size / 2}, clockPalette.front)
+	var backPins [4]bool
+	for p := range backPins {
+		backPins[p] = !c.pins[clockPinMirror[p]]
+	}
+	c.drawSide(svg, c.back, backPins, vec2{size * 1.5, size / 2}, clockPalette.back)
+	return svg.String()
+}
@@ -0,0 +1,167 @@
+package puzzle
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	cubeFaceSize = 60.0 // 展开图每个面的边长
+	cubeFaceGap  = 4.0
+)
+
+// 面的顺序 U R F D L B
+var cubePalette = []string{"#FFFFFF", "#FF0000", "#00D800", "#FFFF00", "#FF8C00", "#0000F2"}
+
+type cubeFace struct {
+	name   byte
+	normal vec3
+	// cell 根据贴片坐标计算展开图上的行列
+	cell func(n int, p vec3) (row, col int)
+	// 展开图上的位置, 以面为单位
+	netX, netY int
+}
+
+func half(n int, v float64) int { return int(math.Round((v + float64(n) - 1) / 2)) }
+
+var cubeFaces = []cubeFace{
+	{name: 'U', normal: vec3{0, 1, 0}, netX: 1, netY: 0, cell: func(n int, p vec3) (int, int) { return half(n, p.Z), half(n, p.X) }},
+	{name: 'R', normal: vec3{1, 0, 0}, netX: 2, netY: 1, cell: func(n int, p vec3) (int, int) { return half(n, -p.Y), half(n, -p.Z) }},
+	{name: 'F', normal: vec3{0, 0, 1}, netX: 1, netY: 1, cell: func(n int, p vec3) (int, int) { return half(n, -p.Y), half(n, p.X) }},
+	{name: 'D', normal: vec3{0, -1, 0}, netX: 1, netY: 2, cell: func(n int, p vec3) (int, int) { return half(n, -p.Z), half(n, p.X) }},
+	{name: 'L', normal: vec3{-1, 0, 0}, netX: 0, netY: 1, cell: func(n int, p vec3) (int, int) { return half(n, -p.Y), half(n, p.Z) }},
+	{name: 'B', normal: vec3{0, 0, -1}, netX: 3, netY: 1, cell: func(n int, p vec3) (int, int) { return half(n, -p.Y), half(n, -p.X) }},
+}
+
+// Cube N阶魔方, 坐标系 x 向右, y 向上, z 向前, 贴片坐标放大两倍取整
+type Cube struct {
+	*faceletPuzzle
+	n int
+}
+
+func NewCube(n int) *Cube {
+	var stickers []sticker
+	var colors []int
+	cell := cubeFaceSize / float64(n)
+	for fi, f := range cubeFaces {
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				a, b := float64(2*i-n+1), float64(2*j-n+1)
+				var pos vec3
+				switch {
+				case f.normal.X != 0:
+					pos = vec3{f.normal.X * float64(n), a, b}
+				case f.normal.Y != 0:
+					pos = vec3{a, f.normal.Y * float64(n), b}
+				default:
+					pos = vec3{a, b, f.normal.Z * float64(n)}
+				}
+				row, col := f.cell(n, pos)
+				x := float64(f.netX)*(cubeFaceSize+cubeFaceGap) + cubeFaceGap + float64(col)*cell
+				y := float64(f.netY)*(cubeFaceSize+cubeFaceGap) + cubeFaceGap + float64(row)*cell
+				stickers = append(stickers, sticker{
+					pos:  pos,
+					poly: []vec2{{x, y}, {x + cell, y}, {x + cell, y + cell}, {x, y + cell}},
+				})
+				colors = append(colors, fi)
+			}
+		}
+	}
+	return &Cube{faceletPuzzle: newFaceletPuzzle(stickers, colors, cubePalette), n: n}
+}
+
+var cubeMoveRegexp = regexp.MustCompile(`^(\d*)([URFDLBurfdlbxyzMES])(w?)(2?)('?)$`)
+
+// Move 执行单步WCA记号, 支持 R R' R2 Rw 3Rw 2R r x y z M E S
+func (c *Cube) Move(move string) error {
+	m := cubeMoveRegexp.FindStringSubmatch(move)
+	if m == nil {
+		return fmt.Errorf("无法识别的转动 %s", move)
+	}
+	prefix, face, wide := m[1], m[2], m[3] != ""
+
+	times := 1
+	if m[4] != "" {
+		times = 2
+	}
+	if m[5] != "" {
+		times = 4 - times
+	}
+
+	var layers = 1
+	var lower bool
+	if face[0] >= 'a' && face[0] <= 'z' && !strings.Contains("xyz", face) {
+		lower = true
+		face = strings.ToUpper(face)
+	}
+	if wide || lower {
+		layers = 2
+	}
+	// 不带 w 的数字前缀只转第 n 层, 如 2R
+	var slice bool
+	if prefix != "" {
+		slice = !wide && !lower
+		layers, _ = strconv.Atoi(prefix)
+	}
+	if layers < 1 || layers > c.n {
+		return fmt.Errorf("转动 %s 层数超出范围", move)
+	}
+
+	n := float64(c.n)
+	var name byte
+	var lo, hi float64
+	switch face {
+	case "x", "y", "z":
+		name, lo, hi = map[string]byte{"x": 'R', "y": 'U', "z": 'F'}[face], -n, n
+	case "M", "E", "S":
+		if c.n < 3 {
+			return fmt.Errorf("%d阶不支持转动 %s", c.n, move)
+		}
+		name, lo, hi = map[string]byte{"M": 'L', "E": 'D', "S": 'F'}[face], -(n - 3), n-3
+	default:
+		name, lo, hi = face[0], n-2*float64(layers)+1, n
+		if slice {
+			hi = lo + 1
+		}
+		if layers == c.n {
+			lo = -n
+		}
+	}
+
+	var normal vec3
+	for _, f := range cubeFaces {
+		if f.name == name {
+			normal = f.normal
+		}
+	}
+	key := fmt.Sprintf("%c:%v:%v", name, lo, hi)
+	perm, err := c.perm(key,
+		func(p vec3) bool { d := p.dot(normal); return d >= lo && d <= hi },
+		func(p vec3) vec3 { return rotate(p, normal, math.Pi/2) },
+	)
+	if err != nil {
+		return err
+	}
+	c.apply(perm, times)
+	return nil
+}
+
+func (c *Cube) Apply(scramble string) error {
+	for _, move := range strings.Fields(scramble) {
+		if err := c.Move(move); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cube) Solved() bool { return c.solved() }
+
+func (c *Cube) SVG() string {
+	svg := newSvgBuilder(4*(cubeFaceSize+cubeFaceGap)+cubeFaceGap, 3*(cubeFaceSize+cubeFaceGap)+cubeFaceGap)
+	c.draw(svg, vec2{}, 1)
+	return svg.String()
+}
@@ -0,0 +1,89 @@
+package puzzle
+
+import "fmt"
+
+// sticker 贴片, pos 为三维中心点, 用于计算转动后的位置, poly 为展开图上的多边形
+type sticker struct {
+	pos  vec3
+	poly []vec2
+}
+
+// faceletPuzzle 基于贴片置换的通用模型, 适用于正阶魔方、金字塔、斜转和五魔方
+type faceletPuzzle struct {
+	stickers []sticker
+	colors   []int    // 每个位置当前的颜色
+	init     []int    // 还原状态的颜色
+	palette  []string // 颜色下标对应的颜色
+	index    map[[3]int64]int
+
+	perms map[string][]int // 缓存的转动置换
+}
+
+func newFaceletPuzzle(stickers []sticker, colors []int, palette []string) *faceletPuzzle {
+	p := &faceletPuzzle{
+		stickers: stickers,
+		colors:   colors,
+		init:     append([]int(nil), colors...),
+		palette:  palette,
+		index:    make(map[[3]int64]int, len(stickers)),
+		perms:    make(map[string][]int),
+	}
+	for i, s := range stickers {
+		p.index[s.pos.key()] = i
+	}
+	return p
+}
+
+// perm 计算或读取缓存的置换, 选中 sel 的贴片按 rot 转动
+func (p *faceletPuzzle) perm(key string, sel func(vec3) bool, rot func(vec3) vec3) ([]int, error) {
+	if perm, ok := p.perms[key]; ok {
+		return perm, nil
+	}
+	perm := make([]int, len(p.stickers))
+	for i, s := range p.stickers {
+		if !sel(s.pos) {
+			perm[i] = i
+			continue
+		}
+		j, ok := p.index[rot(s.pos).key()]
+		if !ok {
+			return nil, fmt.Errorf("转动 %s 无法匹配贴片", key)
+		}
+		perm[i] = j
+	}
+	p.perms[key] = perm
+	return perm, nil
+}
+
+// apply 执行 times 次置换
+func (p *faceletPuzzle) apply(perm []int, times int) {
+	next := make([]int, len(p.colors))
+	for ; times > 0; times-- {
+		for i, c := range p.colors {
+			next[perm[i]] = c
+		}
+		p.colors, next = next, p.colors
+	}
+}
+
+func (p *faceletPuzzle) solved() bool {
+	for i, c := range p.colors {
+		if c != p.init[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Colors 各贴片位置当前的颜色下标
+func (p *faceletPuzzle) Colors() []int { return append([]int(nil), p.colors...) }
+
+func (p *faceletPuzzle) draw(svg *svgBuilder, offset vec2, scale float64) {
+	for i, s := range p.stickers {
+		points := make([]vec2, len(s.poly))
+		for j, pt := range s.poly {
+			points[j] = pt.scale(scale).add(offset)
+		}
+		svg.polygon(points, p.palette[p.colors[i]])
+	}
+}
@@ -0,0 +1,60 @@
+package puzzle
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FMCMaxMoves 最少步解法的最大步数
+const FMCMaxMoves = 80
+
+// 最少步允许的记号: 面转动, 外层双层转动(Rw), 整体转体(x y z), 不允许M E S 中层转动与小写的双层记号
+var fmcMoveRegexp = regexp.MustCompile(`^(?:([URFDLB])(w?)|([xyz]))(2|')?$`)
+
+// FMCMoves 按WCA最少步规则校验解法记号并按HTM计算步数, 转体不计步数
+func FMCMoves(solution string) (int, error) {
+	solution = strings.NewReplacer("’", "'", "‘", "'").Replace(solution)
+	fields := strings.Fields(solution)
+	if len(fields) == 0 {
+		return 0, errors.New("解法为空")
+	}
+
+	moves := 0
+	for idx, move := range fields {
+		m := fmcMoveRegexp.FindStringSubmatch(move)
+		if m == nil {
+			switch {
+			case strings.ContainsAny(move[:1], "MES"):
+				return 0, fmt.Errorf("第%d步 %s: 不允许使用中层转动", idx+1, move)
+			case strings.ContainsAny(move[:1], "urfdlb"):
+				return 0, fmt.Errorf("第%d步 %s: 双层转动需写作 %sw", idx+1, move, strings.ToUpper(move[:1]))
+			}
+			return 0, fmt.Errorf("第%d步 %s: 无法识别的记号", idx+1, move)
+		}
+		if m[3] == "" {
+			moves++
+		}
+	}
+	if moves > FMCMaxMoves {
+		return moves, fmt.Errorf("解法共%d步, 超过%d步上限", moves, FMCMaxMoves)
+	}
+	return moves, nil
+}
+
+// CheckFMCSolution 校验最少步解法的记号并确认能还原打乱, 返回HTM步数
+func CheckFMCSolution(scramble string, solution string) (int, error) {
+	moves, err := FMCMoves(solution)
+	if err != nil {
+		return moves, err
+	}
+	ok, err := Solves("333fm", scramble, solution)
+	if err != nil {
+		return moves, err
+	}
+	if !ok {
+		return moves, errors.New("解法未能还原打乱")
+	}
+	return moves, nil
+}
@@ -0,0 +1,51 @@
+package puzzle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFMCMoves(t *testing.T) {
+	tests := []struct {
+		solution string
+		want     int
+		wantErr  string
+	}{
+		{solution: "R U R' U'", want: 4},
+		{solution: "x R2 Uw' y2 F", want: 3},
+		{solution: "R’ U2", want: 2},
+		{solution: "", wantErr: "解法为空"},
+		{solution: "R M' U", wantErr: "中层转动"},
+		{solution: "r U", wantErr: "Rw"},
+		{solution: "3Rw U", wantErr: "无法识别"},
+		{solution: "R U2'", wantErr: "无法识别"},
+		{solution: "(R U) R'", wantErr: "无法识别"},
+		{solution: strings.Repeat("R U ", 41), want: 82, wantErr: "超过80步"},
+	}
+	for _, tt := range tests {
+		got, err := FMCMoves(tt.solution)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FMCMoves(%q) error = %v, want %q", tt.solution, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("FMCMoves(%q) = %d, %v, want %d", tt.solution, got, err, tt.want)
+		}
+	}
+}
+
+func TestCheckFMCSolution(t *testing.T) {
+	scramble := "R U F"
+	if got, err := CheckFMCSolution(scramble, "F' U' R'"); err != nil || got != 3 {
+		t.Fatalf("got %d, %v", got, err)
+	}
+	// 转体后的解法同样有效, 转体不计步数
+	if got, err := CheckFMCSolution(scramble, "x U' B' R'"); err != nil || got != 3 {
+		t.Fatalf("got %d, %v", got, err)
+	}
+	if _, err := CheckFMCSolution(scramble, "F' U'"); err == nil {
+		t.Fatalf("want not solved error")
+	}
+}
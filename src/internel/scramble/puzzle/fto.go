@@ -0,0 +1,120 @@
+package puzzle
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	ftoSize = 120.0 // 展开图每一半正方形的边长
+	ftoGap  = 10.0
+)
+
+// ftoFace 面的法向量取各坐标轴的正负号, 面的三个顶点为 (sx,0,0) (0,sy,0) (0,0,sz)
+type ftoFace struct {
+	name   string
+	normal vec3
+}
+
+// 正面从 +z 顶点看, U 上 L 左 F 下 R 右, 背面为各自的对面: D 对 U, B 对 F, BR 对 L, BL 对 R
+var ftoFaces = []ftoFace{
+	{"U", vec3{1, 1, 1}},
+	{"L", vec3{-1, 1, 1}},
+	{"F", vec3{-1, -1, 1}},
+	{"R", vec3{1, -1, 1}},
+	{"D", vec3{-1, -1, -1}},
+	{"BR", vec3{1, -1, -1}},
+	{"B", vec3{1, 1, -1}},
+	{"BL", vec3{-1, 1, -1}},
+}
+
+var ftoPalette = []string{"#FFFFFF", "#8A1AFF", "#00D800", "#FF0000", "#A0A0A0", "#0000F2", "#FFFF00", "#FF8C00"}
+
+// FTO 八面体魔方, 外接球半径为 1, 每个面 9 个三角形贴片
+type FTO struct {
+	*faceletPuzzle
+}
+
+func NewFTO() *FTO {
+	var stickers []sticker
+	var colors []int
+	for fi, f := range ftoFaces {
+		a, b, c := vec3{X: f.normal.X}, vec3{Y: f.normal.Y}, vec3{Z: f.normal.Z}
+		p3 := func(i, j int) vec3 {
+			return a.add(b.sub(a).scale(float64(i) / 3)).add(c.sub(a).scale(float64(j) / 3))
+		}
+		add := func(idx [3][2]int) {
+			var pts3 []vec3
+			var pts2 []vec2
+			for _, ij := range idx {
+				p := p3(ij[0], ij[1])
+				pts3 = append(pts3, p)
+				pts2 = append(pts2, ftoProject(p, f.normal.Z < 0))
+			}
+			stickers = append(stickers, sticker{pos: centroid(pts3), poly: pts2})
+			colors = append(colors, fi)
+		}
+		for i := 0; i < 3; i++ {
+			for j := 0; i+j < 3; j++ {
+				add([3][2]int{{i, j}, {i + 1, j}, {i, j + 1}})
+				if i+j < 2 {
+					add([3][2]int{{i + 1, j}, {i + 1, j + 1}, {i, j + 1}})
+				}
+			}
+		}
+	}
+	return &FTO{faceletPuzzle: newFaceletPuzzle(stickers, colors, ftoPalette)}
+}
+
+// ftoProject 正面从 +z 方向看, 背面从 -z 方向看并画在右侧, 旋转45度使展开图为正方形
+func ftoProject(p vec3, back bool) vec2 {
+	x, y := p.X, p.Y
+	center := vec2{ftoGap + ftoSize/2, ftoGap + ftoSize/2}
+	if back {
+		x = -x
+		center.X += ftoSize + ftoGap
+	}
+	k := ftoSize / 2
+	return center.add(vec2{(x - y) / math.Sqrt2 * k, -(x + y) / math.Sqrt2 * k})
+}
+
+// Move 执行单步记号, 每次转动面所在的一层 120 度, 支持 U U' BR BR' 等
+func (f *FTO) Move(move string) error {
+	name, times := strings.TrimSuffix(move, "'"), 1
+	if name != move {
+		times = 2
+	}
+	for _, face := range ftoFaces {
+		if face.name != name {
+			continue
+		}
+		perm, err := f.perm(name,
+			func(v vec3) bool { return v.dot(face.normal) > 1.0/3 },
+			func(v vec3) vec3 { return rotate(v, face.normal, 2*math.Pi/3) },
+		)
+		if err != nil {
+			return err
+		}
+		f.apply(perm, times)
+		return nil
+	}
+	return fmt.Errorf("无法识别的转动 %s", move)
+}
+
+func (f *FTO) Apply(scramble string) error {
+	for _, move := range strings.Fields(scramble) {
+		if err := f.Move(move); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *FTO) Solved() bool { return f.solved() }
+
+func (f *FTO) SVG() string {
+	svg := newSvgBuilder(2*ftoSize+3*ftoGap, ftoSize+2*ftoGap)
+	f.draw(svg, vec2{}, 1)
+	return svg.String()
+}
@@ -0,0 +1,53 @@
+package puzzle
+
+import "math"
+
+type vec3 struct{ X, Y, Z float64 }
+
+func (a vec3) add(b vec3) vec3             { return vec3{a.X + b.X, a.Y + b.Y, a.Z + b.Z} }
+func (a vec3) sub(b vec3) vec3             { return vec3{a.X - b.X, a.Y - b.Y, a.Z - b.Z} }
+func (a vec3) scale(k float64) vec3        { return vec3{a.X * k, a.Y * k, a.Z * k} }
+func (a vec3) dot(b vec3) float64          { return a.X*b.X + a.Y*b.Y + a.Z*b.Z }
+func (a vec3) length() float64             { return math.Sqrt(a.dot(a)) }
+func (a vec3) normalize() vec3             { return a.scale(1 / a.length()) }
+func (a vec3) lerp(b vec3, t float64) vec3 { return a.add(b.sub(a).scale(t)) }
+func (a vec3) cross(b vec3) vec3 {
+	return vec3{a.Y*b.Z - a.Z*b.Y, a.Z*b.X - a.X*b.Z, a.X*b.Y - a.Y*b.X}
+}
+
+// key 用于匹配旋转后的位置, 精度 1e-4
+func (a vec3) key() [3]int64 {
+	return [3]int64{int64(math.Round(a.X * 1e4)), int64(math.Round(a.Y * 1e4)), int64(math.Round(a.Z * 1e4))}
+}
+
+func centroid(points []vec3) vec3 {
+	var c vec3
+	for _, p := range points {
+		c = c.add(p)
+	}
+	return c.scale(1 / float64(len(points)))
+}
+
+// rotate 绕过原点的 axis 旋转 angle 弧度, 从 axis 指向的一侧看为顺时针
+func rotate(p vec3, axis vec3, angle float64) vec3 {
+	k := axis.normalize()
+	// 右手定则下正角度为逆时针, 取反得到顺时针
+	cos, sin := math.Cos(-angle), math.Sin(-angle)
+	return p.scale(cos).add(k.cross(p).scale(sin)).add(k.scale(k.dot(p) * (1 - cos)))
+}
+
+// rotateAround 绕过 origin 的 axis 旋转
+func rotateAround(p, origin, axis vec3, angle float64) vec3 {
+	return rotate(p.sub(origin), axis, angle).add(origin)
+}
+
+type vec2 struct{ X, Y float64 }
+
+func (a vec2) add(b vec2) vec2      { return vec2{a.X + b.X, a.Y + b.Y} }
+func (a vec2) scale(k float64) vec2 { return vec2{a.X * k, a.Y * k} }
+
+// polar 屏幕坐标下的极坐标, angle 为角度, 0 指向正上方, 顺时针增加
+func polar(r float64, angle float64) vec2 {
+	rad := angle * math.Pi / 180
+	return vec2{r * math.Sin(rad), -r * math.Cos(rad)}
+}
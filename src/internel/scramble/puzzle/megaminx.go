@@ -0,0 +1,239 @@
+package puzzle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	megaminxScale     = 45.0 // 内切球半径为 1 时的缩放
+	megaminxCornerCut = 0.35 // 边上切点与顶点的距离占比
+)
+
+// 面的顺序 U, 上层一圈 F R BR BL L, 下层一圈, D
+var megaminxPalette = []string{
+	"#FFFFFF", "#008000", "#FF0000", "#800080", "#FFFF00", "#0000F2",
+	"#FFFFB3", "#8080FF", "#FF80FF", "#00FF00", "#FF8C00", "#808080",
+}
+
+const (
+	megaminxU = 0
+	megaminxR = 2
+	megaminxD = 11
+)
+
+// Megaminx 五魔方, U 面朝上, F 面朝前, 内切球半径为 1
+type Megaminx struct {
+	*faceletPuzzle
+	normals []vec3
+	inLayer []map[[3]int64]bool
+}
+
+func megaminxNormals() []vec3 {
+	cos := 1 / math.Sqrt(5)
+	sin := math.Sqrt(1 - cos*cos)
+	normals := []vec3{{0, 1, 0}}
+	for i := 0; i < 5; i++ {
+		phi := float64(i) * 72 * math.Pi / 180
+		normals = append(normals, vec3{sin * math.Sin(phi), cos, sin * math.Cos(phi)})
+	}
+	for i := 0; i < 5; i++ {
+		phi := (float64(i)*72 + 36) * math.Pi / 180
+		normals = append(normals, vec3{sin * math.Sin(phi), -cos, sin * math.Cos(phi)})
+	}
+	return append(normals, vec3{0, -1, 0})
+}
+
+// megaminxVertices 每个面按顺时针(从面外看)排列的五个顶点
+func megaminxVertices(normals []vec3) [][]vec3 {
+	adjacent := func(a, b vec3) bool { return math.Abs(a.dot(b)-1/math.Sqrt(5)) < 1e-9 }
+	var vertices []vec3
+	for i := range normals {
+		for j := i + 1; j < len(normals); j++ {
+			for k := j + 1; k < len(normals); k++ {
+				if adjacent(normals[i], normals[j]) && adjacent(normals[j], normals[k]) && adjacent(normals[i], normals[k]) {
+					sum := normals[i].add(normals[j]).add(normals[k])
+					vertices = append(vertices, sum.scale(1/normals[i].dot(sum)))
+				}
+			}
+		}
+	}
+
+	out := make([][]vec3, len(normals))
+	for i, n := range normals {
+		var face []vec3
+		for _, v := range vertices {
+			if math.Abs(v.dot(n)-1) < 1e-9 {
+				face = append(face, v)
+			}
+		}
+		ref := face[0].sub(n)
+		angle := func(v vec3) float64 {
+			d := v.sub(n)
+			return math.Atan2(ref.cross(d).dot(n), ref.dot(d))
+		}
+		// 逆时针角度递减即为从面外看的顺时针
+		sort.Slice(face, func(a, b int) bool { return angle(face[a]) > angle(face[b]) })
+		out[i] = face
+	}
+	return out
+}
+
+// megaminxStickers 单个面的 11 块贴片的三维多边形, 中心块在前, 切割线与相邻的边平行
+func megaminxStickers(v []vec3) [][]vec3 {
+	var inner, a, b []vec3
+	for i := range v {
+		next, prev := v[(i+1)%5], v[(i+4)%5]
+		inner = append(inner, v[i].add(next.sub(v[i]).scale(megaminxCornerCut)).add(prev.sub(v[i]).scale(megaminxCornerCut)))
+		a = append(a, v[i].lerp(next, megaminxCornerCut))
+		b = append(b, v[i].lerp(prev, megaminxCornerCut))
+	}
+	out := [][]vec3{inner}
+	for i := range v {
+		next := (i + 1) % 5
+		out = append(out,
+			[]vec3{v[i], a[i], inner[i], b[i]},
+			[]vec3{a[i], b[next], inner[next], inner[i]},
+		)
+	}
+	return out
+}
+
+func NewMegaminx() *Megaminx {
+	normals := megaminxNormals()
+	vertices := megaminxVertices(normals)
+
+	type face3d struct {
+		polys [][]vec3
+	}
+	faces := make([]face3d, len(normals))
+	for i := range normals {
+		faces[i].polys = megaminxStickers(vertices[i])
+	}
+
+	// 展开: U 花在左, 从上往下看; D 花在右, 从下往上看
+	unfold := func(center int, face int, p vec3) vec3 {
+		if face == center {
+			return p
+		}
+		nc, nf := normals[center], normals[face]
+		var shared vec3
+		for _, v := range vertices[face] {
+			if math.Abs(v.dot(nc)-1) < 1e-9 {
+				shared = v
+			}
+		}
+		axis := nf.cross(nc)
+		return rotateAround(p, shared, axis, -math.Acos(nf.dot(nc)))
+	}
+	var project = []func(p vec3) vec2{
+		func(p vec3) vec2 { return vec2{p.X, p.Z} },
+		func(p vec3) vec2 { return vec2{p.X, -p.Z} },
+	}
+
+	var stickers []sticker
+	var colors []int
+	var polys3d [][]vec3
+	for fi := range normals {
+		flower, center := 0, megaminxU
+		if normals[fi].Y < -1e-9 {
+			flower, center = 1, megaminxD
+		}
+		for _, poly := range faces[fi].polys {
+			pts := make([]vec2, len(poly))
+			for j, p := range poly {
+				pts[j] = project[flower](unfold(center, fi, p)).scale(megaminxScale)
+				pts[j].X += 2.6*megaminxScale + float64(flower)*5.2*megaminxScale
+				pts[j].Y += 2.6 * megaminxScale
+			}
+			stickers = append(stickers, sticker{pos: centroid(poly), poly: pts})
+			colors = append(colors, fi)
+			polys3d = append(polys3d, poly)
+		}
+	}
+
+	// 每个面的转动层: 面上的贴片以及相邻面上接触该面的贴片
+	inLayer := make([]map[[3]int64]bool, len(normals))
+	for fi, n := range normals {
+		inLayer[fi] = make(map[[3]int64]bool)
+		for si, poly := range polys3d {
+			for _, p := range poly {
+				if math.Abs(p.dot(n)-1) < 1e-9 {
+					inLayer[fi][stickers[si].pos.key()] = true
+					break
+				}
+			}
+		}
+	}
+
+	return &Megaminx{
+		faceletPuzzle: newFaceletPuzzle(stickers, colors, megaminxPalette),
+		normals:       normals,
+		inLayer:       inLayer,
+	}
+}
+
+// Move 执行单步WCA(Pochmann)记号 R++ R-- D++ D-- U U'
+func (m *Megaminx) Move(move string) error {
+	var face, fixed, times int
+	switch move {
+	case "R++", "R--":
+		face, fixed, times = megaminxR, m.opposite(megaminxR), 2
+	case "D++", "D--":
+		face, fixed, times = megaminxD, megaminxU, 2
+	case "U", "U'", "U2", "U2'":
+		face, fixed, times = megaminxU, -1, 1
+		if strings.HasPrefix(move, "U2") {
+			times = 2
+		}
+	default:
+		return fmt.Errorf("无法识别的转动 %s", move)
+	}
+	if strings.HasSuffix(move, "--") || strings.HasSuffix(move, "'") {
+		times = 5 - times
+	}
+
+	axis := m.normals[face]
+	var sel func(p vec3) bool
+	if fixed < 0 {
+		sel = func(p vec3) bool { return m.inLayer[face][p.key()] }
+	} else {
+		sel = func(p vec3) bool { return !m.inLayer[fixed][p.key()] }
+	}
+	perm, err := m.perm(fmt.Sprintf("%d:%d", face, fixed), sel,
+		func(p vec3) vec3 { return rotate(p, axis, 2*math.Pi/5) },
+	)
+	if err != nil {
+		return err
+	}
+	m.apply(perm, times)
+	return nil
+}
+
+func (m *Megaminx) opposite(face int) int {
+	for i, n := range m.normals {
+		if n.dot(m.normals[face]) < -1+1e-9 {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Megaminx) Apply(scramble string) error {
+	for _, move := range strings.Fields(scramble) {
+		if err := m.Move(move); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Megaminx) Solved() bool { return m.solved() }
+
+func (m *Megaminx) SVG() string {
+	svg := newSvgBuilder(10.4*megaminxScale, 5.2*megaminxScale)
+	m.draw(svg, vec2{}, 1)
+	return svg.String()
+}
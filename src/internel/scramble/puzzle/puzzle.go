@@ -0,0 +1,57 @@
+// Package puzzle 纯Go实现的魔方状态模型, 用于将打乱应用到魔方上并绘制展开图
+package puzzle
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Puzzle interface {
+	// Apply 按WCA记号执行打乱
+	Apply(scramble string) error
+	// Solved 与初始状态一致
+	Solved() bool
+	// SVG 展开图
+	SVG() string
+}
+
+// New 根据项目ID创建魔方, 盲拧单手等同阶项目使用同一模型
+func New(ev string) (Puzzle, error) {
+	switch ev {
+	case "pyram", "pyrm":
+		return NewPyraminx(), nil
+	case "skewb", "skb":
+		return NewSkewb(), nil
+	case "minx", "mgmo":
+		return NewMegaminx(), nil
+	case "sq1", "sq-1", "sqrs":
+		return NewSquare1(), nil
+	case "clock", "clkwca":
+		return NewClock(), nil
+	case "fto":
+		return NewFTO(), nil
+	}
+	if len(ev) >= 3 && ev[0] == ev[1] && ev[1] == ev[2] && ev[0] >= '2' && ev[0] <= '7' {
+		return NewCube(int(ev[0] - '0')), nil
+	}
+	return nil, fmt.Errorf("unknown event type %s", ev)
+}
+
+func DrawSVG(ev string, scramble string) (string, error) {
+	p, err := New(ev)
+	if err != nil {
+		return "", err
+	}
+	if err = p.Apply(strings.TrimSpace(scramble)); err != nil {
+		return "", err
+	}
+	return p.SVG(), nil
+}
+
+func DrawPNG(ev string, scramble string) ([]byte, error) {
+	svg, err := DrawSVG(ev, scramble)
+	if err != nil {
+		return nil, err
+	}
+	return SvgToPng(svg)
+}
@@ -0,0 +1,156 @@
+package puzzle
+
+import (
+	"bytes"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPuzzle_Identity(t *testing.T) {
+	tests := []struct {
+		ev       string
+		scramble string
+	}{
+		{ev: "222", scramble: "R R R R U2 U2 F' F"},
+		{ev: "333", scramble: strings.Repeat("R U R' U' ", 6)},
+		{ev: "333", scramble: "x y z z' y' x' M M' E2 E2 S S'"},
+		{ev: "444", scramble: "Rw Rw' 3Rw2 3Rw2 r r' Uw Uw Uw Uw"},
+		{ev: "777", scramble: "3Fw 3Fw' 2Lw2 2Lw2 B' B D D'"},
+		{ev: "pyram", scramble: "U U U r r r L' L b b'"},
+		{ev: "skewb", scramble: "R R R U U' L' L B B B"},
+		{ev: "minx", scramble: "U U U U U R++ R-- D++ D++ D++ D++ D++ U2 U2'"},
+		{ev: "sq1", scramble: "(1,0) / / (-1,0)"},
+		{ev: "sq1", scramble: "(3,3) (3,3) (3,3) (3,3) / (6,6) (6,6) /"},
+		{ev: "clock", scramble: "UR3+ UR3- ALL5+ ALL1+ ALL6+ y2 y2"},
+		{ev: "fto", scramble: "U U U BR BR' D' D' D' L R' R L' BL B B' BL'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ev, func(t *testing.T) {
+			p, err := New(tt.ev)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = p.Apply(tt.scramble); err != nil {
+				t.Fatal(err)
+			}
+			if !p.Solved() {
+				t.Errorf("%s 执行 %s 后应为还原状态", tt.ev, tt.scramble)
+			}
+		})
+	}
+}
+
+func TestCube_Move(t *testing.T) {
+	c := NewCube(3)
+	if err := c.Apply("R"); err != nil {
+		t.Fatal(err)
+	}
+	// R 之后 U 面右列为 F 面颜色
+	for i, s := range c.stickers {
+		if s.pos.Y != 3 {
+			continue
+		}
+		want := 0
+		if s.pos.X == 2 {
+			want = 2
+		}
+		if c.colors[i] != want {
+			t.Errorf("U 面 %+v 颜色为 %d, want %d", s.pos, c.colors[i], want)
+		}
+	}
+	for _, move := range []string{"R3", "Q", "4R", "8Rw"} {
+		if err := c.Move(move); err == nil {
+			t.Errorf("%s 应报错", move)
+		}
+	}
+}
+
+func TestFTO_Move(t *testing.T) {
+	f := NewFTO()
+	if len(f.stickers) != 72 {
+		t.Fatalf("stickers = %d", len(f.stickers))
+	}
+	// 对面的转动互不影响, 可交换
+	a, b := NewFTO(), NewFTO()
+	_ = a.Apply("U D' R BL")
+	_ = b.Apply("D' U BL R")
+	if !reflect.DeepEqual(a.colors, b.colors) {
+		t.Error("对面转动应可交换")
+	}
+	_ = f.Apply("U R")
+	if f.Solved() {
+		t.Error("U R 后不应为还原状态")
+	}
+	if err := f.Move("U2"); err == nil {
+		t.Error("FTO 不支持 U2")
+	}
+}
+
+func TestSquare1_Slash(t *testing.T) {
+	s := NewSquare1()
+	if err := s.Apply("(-1,0) /"); err == nil {
+		t.Error("角块卡在切割线上时 / 应报错")
+	}
+}
+
+func TestClock_Turn(t *testing.T) {
+	c := NewClock()
+	if err := c.Apply("UR2+"); err != nil {
+		t.Fatal(err)
+	}
+	if c.front != [9]int{0, 2, 2, 0, 2, 2, 0, 0, 0} {
+		t.Errorf("front = %v", c.front)
+	}
+	// 正面右上角在背面看为左上角, 方向相反
+	if c.back != [9]int{10, 0, 0, 0, 0, 0, 0, 0, 0} {
+		t.Errorf("back = %v", c.back)
+	}
+}
+
+func TestDrawPNG(t *testing.T) {
+	tests := []struct {
+		ev       string
+		scramble string
+	}{
+		{ev: "222", scramble: "R U' F2 R' U2 F R2"},
+		{ev: "333bf", scramble: "D2 F2 U' B2 R2 U F2 U' L2 B' L' D B2 U F' R' U' F L' Rw Uw"},
+		{ev: "444", scramble: "Uw2 R' Fw2 U Rw2 B2 L' Fw2 3Rw"},
+		{ev: "555", scramble: "Bw Dw' 3Uw2 Lw2 r2 u'"},
+		{ev: "666", scramble: "3Fw2 2Rw 3Uw' Lw Bw2"},
+		{ev: "777", scramble: "3Bw' 3Dw Fw2 3Lw R' Dw"},
+		{ev: "pyram", scramble: "L' R B U' B R' U R U R' U' r b'"},
+		{ev: "skewb", scramble: "U R B U L' R' B U' B R' U'"},
+		{ev: "minx", scramble: "R++ D++ R++ D-- R-- D-- R-- D++ R-- D-- U'\nR++ D++ R++ D++ R-- D-- R++ D-- R++ D++ U"},
+		{ev: "sq1", scramble: "(-2, 0) / (3, -3) / (-3, 0) / (-1, -1) / (-3, 0) / (0, -3) / (3, 0) / (-3, 0) / (1, 0) / (-3, -3) / (-1, -2) / (2, -4) / (4, 0)"},
+		{ev: "clock", scramble: "UR0+ DR5+ DL4+ UL1+ U2- R2- D2- L2+ ALL5+ y2 U1- R4+ D2- L5- ALL4- UR DL"},
+		{ev: "fto", scramble: "R' L BR' U' D B' BL F R U' L' BL' D F' B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ev, func(t *testing.T) {
+			svg, err := DrawSVG(tt.ev, tt.scramble)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>") {
+				t.Errorf("svg = %s", svg)
+			}
+			out, err := DrawPNG(tt.ev, tt.scramble)
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(bytes.NewReader(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if img.Bounds().Dx() == 0 || img.Bounds().Dy() == 0 {
+				t.Errorf("bounds = %v", img.Bounds())
+			}
+		})
+	}
+
+	if _, err := DrawSVG("redi", "R"); err == nil {
+		t.Error("不支持的项目应报错")
+	}
+}
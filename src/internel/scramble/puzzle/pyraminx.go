@@ -0,0 +1,123 @@
+package puzzle
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const pyraminxEdge = 100.0
+
+// 面的顺序 F L R D
+var pyraminxPalette = []string{"#00D800", "#FF0000", "#0000F2", "#FFFF00"}
+
+// Pyraminx 金字塔, U 顶点朝上, F 面朝前, 外接球半径为 1
+type Pyraminx struct {
+	*faceletPuzzle
+	vertex map[byte]vec3
+}
+
+func NewPyraminx() *Pyraminx {
+	r := math.Sqrt(8) / 3
+	sin60, cos60 := math.Sqrt(3)/2, 0.5
+	u := vec3{0, 1, 0}
+	l := vec3{-r * sin60, -1.0 / 3, r * cos60}
+	rv := vec3{r * sin60, -1.0 / 3, r * cos60}
+	b := vec3{0, -1.0 / 3, -r}
+
+	h := pyraminxEdge * sin60
+	origin := vec2{pyraminxEdge + 5, 5}
+	u2, l2, r2 := origin, origin.add(vec2{-pyraminxEdge / 2, h}), origin.add(vec2{pyraminxEdge / 2, h})
+	faces := []struct {
+		a, b, c    vec3
+		a2, b2, c2 vec2
+	}{
+		{u, l, rv, u2, l2, r2},
+		{u, l, b, u2, l2, origin.add(vec2{-pyraminxEdge, 0})},
+		{u, rv, b, u2, r2, origin.add(vec2{pyraminxEdge, 0})},
+		{l, rv, b, l2, r2, origin.add(vec2{0, 2 * h})},
+	}
+
+	var stickers []sticker
+	var colors []int
+	for fi, f := range faces {
+		p3 := func(i, j int) vec3 {
+			return f.a.add(f.b.sub(f.a).scale(float64(i) / 3)).add(f.c.sub(f.a).scale(float64(j) / 3))
+		}
+		p2 := func(i, j int) vec2 {
+			return vec2{
+				f.a2.X + (f.b2.X-f.a2.X)*float64(i)/3 + (f.c2.X-f.a2.X)*float64(j)/3,
+				f.a2.Y + (f.b2.Y-f.a2.Y)*float64(i)/3 + (f.c2.Y-f.a2.Y)*float64(j)/3,
+			}
+		}
+		add := func(idx [3][2]int) {
+			var pts3 []vec3
+			var pts2 []vec2
+			for _, ij := range idx {
+				pts3 = append(pts3, p3(ij[0], ij[1]))
+				pts2 = append(pts2, p2(ij[0], ij[1]))
+			}
+			stickers = append(stickers, sticker{pos: centroid(pts3), poly: pts2})
+			colors = append(colors, fi)
+		}
+		for i := 0; i < 3; i++ {
+			for j := 0; i+j < 3; j++ {
+				add([3][2]int{{i, j}, {i + 1, j}, {i, j + 1}})
+				if i+j < 2 {
+					add([3][2]int{{i + 1, j}, {i + 1, j + 1}, {i, j + 1}})
+				}
+			}
+		}
+	}
+	return &Pyraminx{
+		faceletPuzzle: newFaceletPuzzle(stickers, colors, pyraminxPalette),
+		vertex:        map[byte]vec3{'U': u, 'L': l, 'R': rv, 'B': b},
+	}
+}
+
+// Move 执行单步WCA记号, 大写转动两层, 小写只转角块
+func (p *Pyraminx) Move(move string) error {
+	if len(move) == 0 || len(move) > 2 || (len(move) == 2 && move[1] != '\'') {
+		return fmt.Errorf("无法识别的转动 %s", move)
+	}
+	name := move[0]
+	threshold := 1.0 / 9
+	if name >= 'a' && name <= 'z' {
+		name -= 'a' - 'A'
+		threshold = 5.0 / 9
+	}
+	axis, ok := p.vertex[name]
+	if !ok {
+		return fmt.Errorf("无法识别的转动 %s", move)
+	}
+	times := 1
+	if len(move) == 2 {
+		times = 2
+	}
+	perm, err := p.perm(fmt.Sprintf("%c:%v", name, threshold),
+		func(v vec3) bool { return v.dot(axis) > threshold },
+		func(v vec3) vec3 { return rotate(v, axis, 2*math.Pi/3) },
+	)
+	if err != nil {
+		return err
+	}
+	p.apply(perm, times)
+	return nil
+}
+
+func (p *Pyraminx) Apply(scramble string) error {
+	for _, move := range strings.Fields(scramble) {
+		if err := p.Move(move); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Pyraminx) Solved() bool { return p.solved() }
+
+func (p *Pyraminx) SVG() string {
+	svg := newSvgBuilder(2*pyraminxEdge+10, pyraminxEdge*math.Sqrt(3)+10)
+	p.draw(svg, vec2{}, 1)
+	return svg.String()
+}
@@ -0,0 +1,104 @@
+package puzzle
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Skewb 斜转, 展开图与配色同三阶魔方, 贴片坐标中心块为 2*法向量, 角块再偏移 ±1
+type Skewb struct {
+	*faceletPuzzle
+}
+
+// WCA 记号固定角: R 为 DRB, U 为 UBL, L 为 DFL, B 为 DBL
+var skewbAxis = map[byte]vec3{
+	'R': {1, -1, -1},
+	'U': {-1, 1, -1},
+	'L': {-1, -1, 1},
+	'B': {-1, -1, -1},
+}
+
+func NewSkewb() *Skewb {
+	var stickers []sticker
+	var colors []int
+	const s = cubeFaceSize
+	for fi, f := range cubeFaces {
+		x := float64(f.netX)*(s+cubeFaceGap) + cubeFaceGap
+		y := float64(f.netY)*(s+cubeFaceGap) + cubeFaceGap
+		top, right, bottom, left := vec2{x + s/2, y}, vec2{x + s, y + s/2}, vec2{x + s/2, y + s}, vec2{x, y + s/2}
+
+		center := f.normal.scale(2)
+		stickers = append(stickers, sticker{pos: center, poly: []vec2{top, right, bottom, left}})
+		colors = append(colors, fi)
+
+		for _, a := range []float64{-1, 1} {
+			for _, b := range []float64{-1, 1} {
+				var pos vec3
+				switch {
+				case f.normal.X != 0:
+					pos = vec3{center.X, a, b}
+				case f.normal.Y != 0:
+					pos = vec3{a, center.Y, b}
+				default:
+					pos = vec3{a, b, center.Z}
+				}
+				row, col := f.cell(2, pos)
+				corner := vec2{x + float64(col)*s, y + float64(row)*s}
+				mid1, mid2 := top, left
+				switch {
+				case row == 0 && col == 1:
+					mid1, mid2 = top, right
+				case row == 1 && col == 0:
+					mid1, mid2 = bottom, left
+				case row == 1 && col == 1:
+					mid1, mid2 = bottom, right
+				}
+				stickers = append(stickers, sticker{pos: pos, poly: []vec2{corner, mid1, mid2}})
+				colors = append(colors, fi)
+			}
+		}
+	}
+	return &Skewb{faceletPuzzle: newFaceletPuzzle(stickers, colors, cubePalette)}
+}
+
+// Move 执行单步WCA记号 R U L B 及其逆
+func (s *Skewb) Move(move string) error {
+	if len(move) == 0 || len(move) > 2 || (len(move) == 2 && move[1] != '\'') {
+		return fmt.Errorf("无法识别的转动 %s", move)
+	}
+	axis, ok := skewbAxis[move[0]]
+	if !ok {
+		return fmt.Errorf("无法识别的转动 %s", move)
+	}
+	times := 1
+	if len(move) == 2 {
+		times = 2
+	}
+	perm, err := s.perm(string(move[0]),
+		func(v vec3) bool { return v.dot(axis) > 0 },
+		func(v vec3) vec3 { return rotate(v, axis, 2*math.Pi/3) },
+	)
+	if err != nil {
+		return err
+	}
+	s.apply(perm, times)
+	return nil
+}
+
+func (s *Skewb) Apply(scramble string) error {
+	for _, move := range strings.Fields(scramble) {
+		if err := s.Move(move); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Skewb) Solved() bool { return s.solved() }
+
+func (s *Skewb) SVG() string {
+	svg := newSvgBuilder(4*(cubeFaceSize+cubeFaceGap)+cubeFaceGap, 3*(cubeFaceSize+cubeFaceGap)+cubeFaceGap)
+	s.draw(svg, vec2{}, 1)
+	return svg.String()
+}
@@ -0,0 +1,175 @@
+package puzzle
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	sq1Half      = 50.0 // 还原状态下层的半边长
+	sq1SideWidth = 10.0 // 侧面色带宽度
+)
+
+var sq1Palette = struct {
+	up, down string
+	sides    []string
+}{
+	up:    "#FFFFFF",
+	down:  "#FFFF00",
+	sides: []string{"#FF0000", "#00D800", "#FF8C00", "#0000F2"},
+}
+
+type sq1Piece struct {
+	corner bool
+	top    string
+	sides  []string // 按方位角递增的侧面颜色
+}
+
+// Square1 SQ1, 每层按方位角分为 12 格(每格 30 度), 方位角从上往下看顺时针增加, 底层也使用该方位角
+// 中层切割线在 0 度与 180 度, 0-180 度为右半边, / 转动右半边
+type Square1 struct {
+	top, bottom [12]*sq1Piece
+	flipped     bool
+}
+
+// sq1Side 方位角 a 处的侧面颜色, 还原状态下棱块中心在 75+90k 度
+func sq1Side(a float64) string {
+	return sq1Palette.sides[int(math.Floor(math.Mod(a-30+360, 360)/90))]
+}
+
+func NewSquare1() *Square1 {
+	s := &Square1{}
+	for li, layer := range []*[12]*sq1Piece{&s.top, &s.bottom} {
+		color := sq1Palette.up
+		if li == 1 {
+			color = sq1Palette.down
+		}
+		// 角 角 棱 循环
+		for k := 0; k < 12; k += 3 {
+			a := float64(k) * 30
+			corner := &sq1Piece{corner: true, top: color, sides: []string{sq1Side(a + 15), sq1Side(a + 45)}}
+			edge := &sq1Piece{top: color, sides: []string{sq1Side(a + 75)}}
+			layer[k], layer[k+1], layer[k+2] = corner, corner, edge
+		}
+	}
+	return s
+}
+
+// Turn 上层顺时针转 x 格, 下层从底面看顺时针转 y 格
+func (s *Square1) Turn(x, y int) {
+	var top, bottom [12]*sq1Piece
+	for k := 0; k < 12; k++ {
+		top[((k+x)%12+12)%12] = s.top[k]
+		bottom[((k-y)%12+12)%12] = s.bottom[k]
+	}
+	s.top, s.bottom = top, bottom
+}
+
+// Slash 右半边翻转 180 度, 方位角 a 变为 180-a
+func (s *Square1) Slash() error {
+	for _, layer := range []*[12]*sq1Piece{&s.top, &s.bottom} {
+		if layer[11] == layer[0] || layer[5] == layer[6] {
+			return errors.New("角块卡在切割线上, 无法执行 /")
+		}
+	}
+	seen := make(map[*sq1Piece]bool)
+	for k := 0; k < 6; k++ {
+		for _, p := range []*sq1Piece{s.top[k], s.bottom[k]} {
+			if !seen[p] && len(p.sides) == 2 {
+				p.sides[0], p.sides[1] = p.sides[1], p.sides[0]
+			}
+			seen[p] = true
+		}
+	}
+	for k := 0; k < 6; k++ {
+		s.top[k], s.bottom[5-k] = s.bottom[5-k], s.top[k]
+	}
+	s.flipped = !s.flipped
+	return nil
+}
+
+var sq1TurnRegexp = regexp.MustCompile(`^\(?(-?\d+),(-?\d+)\)?$`)
+
+// Apply 执行WCA记号, 如 (1,0) / (-3,3) /
+func (s *Square1) Apply(scramble string) error {
+	scramble = strings.ReplaceAll(scramble, " ", "")
+	scramble = strings.ReplaceAll(scramble, "/", " / ")
+	scramble = strings.ReplaceAll(scramble, ")(", ") (")
+	for _, move := range strings.Fields(scramble) {
+		if move == "/" {
+			if err := s.Slash(); err != nil {
+				return err
+			}
+			continue
+		}
+		m := sq1TurnRegexp.FindStringSubmatch(move)
+		if m == nil {
+			return fmt.Errorf("无法识别的转动 %s", move)
+		}
+		x, _ := strconv.Atoi(m[1])
+		y, _ := strconv.Atoi(m[2])
+		s.Turn(x, y)
+	}
+	return nil
+}
+
+func (s *Square1) Solved() bool {
+	o := NewSquare1()
+	for k := 0; k < 12; k++ {
+		for _, pair := range [][2]*sq1Piece{{s.top[k], o.top[k]}, {s.bottom[k], o.bottom[k]}} {
+			if pair[0].top != pair[1].top || strings.Join(pair[0].sides, "") != strings.Join(pair[1].sides, "") {
+				return false
+			}
+		}
+	}
+	return !s.flipped
+}
+
+func (s *Square1) drawLayer(svg *svgBuilder, layer [12]*sq1Piece, center vec2, mirror bool) {
+	radius := sq1Half / math.Cos(math.Pi/12)
+	corner := sq1Half * math.Sqrt2
+	outer := (sq1Half + sq1SideWidth) / sq1Half
+	// 旋转 15 度使还原状态的方块边与画布对齐
+	at := func(r, a float64) vec2 {
+		if mirror {
+			return polar(r, -a-15).add(center)
+		}
+		return polar(r, a+15).add(center)
+	}
+	for k := 0; k < 12; k++ {
+		p := layer[k]
+		if p.corner && layer[(k+11)%12] == p {
+			continue
+		}
+		a := float64(k) * 30
+		if !p.corner {
+			svg.polygon([]vec2{center, at(radius, a), at(radius, a+30)}, p.top)
+			svg.polygon([]vec2{at(radius, a), at(radius*outer, a), at(radius*outer, a+30), at(radius, a+30)}, p.sides[0])
+			continue
+		}
+		svg.polygon([]vec2{center, at(radius, a), at(corner, a+30), at(radius, a+60)}, p.top)
+		svg.polygon([]vec2{at(radius, a), at(radius*outer, a), at(corner*outer, a+30), at(corner, a+30)}, p.sides[0])
+		svg.polygon([]vec2{at(corner, a+30), at(corner*outer, a+30), at(radius*outer, a+60), at(radius, a+60)}, p.sides[1])
+	}
+}
+
+func (s *Square1) SVG() string {
+	size := 2*(sq1Half+sq1SideWidth)*math.Sqrt2 + 10
+	svg := newSvgBuilder(2*size+10, size+30)
+	s.drawLayer(svg, s.top, vec2{size / 2, size / 2}, false)
+	s.drawLayer(svg, s.bottom, vec2{size*1.5 + 10, size / 2}, true)
+
+	// 中层: 左半边固定, 右半边翻转后显示背面的颜色
+	left, right := sq1Side(345), sq1Side(345)
+	if s.flipped {
+		right = sq1Side(165)
+	}
+	y, w := size+5, size/2
+	svg.polygon([]vec2{{size/2 + 5, y}, {size/2 + 5 + w, y}, {size/2 + 5 + w, y + 20}, {size/2 + 5, y + 20}}, left)
+	svg.polygon([]vec2{{size/2 + 5 + w, y}, {size/2 + 5 + 2*w, y}, {size/2 + 5 + 2*w, y + 20}, {size/2 + 5 + w, y + 20}}, right)
+	return svg.String()
+}
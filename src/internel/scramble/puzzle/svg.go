@@ -0,0 +1,72 @@
+package puzzle
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+)
+
+const (
+	strokeColor = "#000000"
+	strokeWidth = 1.0
+)
+
+type svgBuilder struct {
+	width, height float64
+	body          strings.Builder
+}
+
+func newSvgBuilder(width, height float64) *svgBuilder {
+	return &svgBuilder{width: width, height: height}
+}
+
+func (s *svgBuilder) polygon(points []vec2, fill string) {
+	s.body.WriteString(`<polygon points="`)
+	for i, p := range points {
+		if i > 0 {
+			s.body.WriteString(" ")
+		}
+		_, _ = fmt.Fprintf(&s.body, "%.2f,%.2f", p.X, p.Y)
+	}
+	_, _ = fmt.Fprintf(&s.body, `" fill="%s" stroke="%s" stroke-width="%.1f"/>`, fill, strokeColor, strokeWidth)
+	s.body.WriteString("\n")
+}
+
+func (s *svgBuilder) circle(center vec2, r float64, fill string) {
+	_, _ = fmt.Fprintf(&s.body, `<circle cx="%.2f" cy="%.2f" r="%.2f" fill="%s" stroke="%s" stroke-width="%.1f"/>`+"\n",
+		center.X, center.Y, r, fill, strokeColor, strokeWidth)
+}
+
+func (s *svgBuilder) line(from, to vec2, color string, width float64) {
+	_, _ = fmt.Fprintf(&s.body, `<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="%s" stroke-width="%.1f"/>`+"\n",
+		from.X, from.Y, to.X, to.Y, color, width)
+}
+
+func (s *svgBuilder) String() string {
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f">`+"\n"+
+		`<rect x="0" y="0" width="%.0f" height="%.0f" fill="#FFFFFF"/>`+"\n%s</svg>",
+		s.width, s.height, s.width, s.height, s.width, s.height, s.body.String())
+}
+
+// SvgToPng 将 svg 栅格化为 png
+func SvgToPng(svg string) ([]byte, error) {
+	icon, err := oksvg.ReadIconStream(strings.NewReader(svg))
+	if err != nil {
+		return nil, err
+	}
+	width, height := int(icon.ViewBox.W), int(icon.ViewBox.H)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	raster := rasterx.NewDasher(width, height, rasterx.NewScannerGV(width, height, img, img.Bounds()))
+	icon.Draw(raster, 1.0)
+
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,229 @@
+package puzzle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CaseMask 公式校验时需要还原的部分, 只对正阶魔方生效, 其他魔方按整体还原判断
+type CaseMask int
+
+const (
+	MaskAll       CaseMask = iota // 整个魔方
+	MaskF2L                       // 顶层以外的部分
+	MaskOLL                       // 顶层以外的部分与顶面
+	MaskLLCorners                 // 顶层以外的部分与顶层角块
+	MaskCMLL                      // 左右两个块与顶层角块, 不含M层与顶层棱块
+	MaskUD                        // 顶面与底面, 用于二阶的色向
+)
+
+// contains 贴片是否在需要还原的范围内, 坐标为放大两倍后的整数坐标
+func (m CaseMask) contains(n int, p vec3) bool {
+	top := p.Y >= float64(n-1)
+	corner := 0
+	for _, v := range []float64{p.X, p.Y, p.Z} {
+		if v == float64(n-1) || v == -float64(n-1) {
+			corner++
+		}
+	}
+	switch m {
+	case MaskF2L:
+		return !top
+	case MaskOLL:
+		return !top || p.Y == float64(n)
+	case MaskLLCorners:
+		return !top || corner == 2
+	case MaskUD:
+		return p.Y == float64(n) || p.Y == -float64(n)
+	case MaskCMLL:
+		if top {
+			return corner == 2
+		}
+		return p.X != 0
+	}
+	return true
+}
+
+// NormalizeAlg 去掉公式中的括号等分组记号, 方便直接执行, 五魔和SQ1的记号保持不变
+func NormalizeAlg(ev string, alg string) string {
+	switch ev {
+	case "sq1", "sq-1", "sqrs":
+		return alg
+	}
+	return strings.NewReplacer("(", " ", ")", " ", "[", " ", "]", " ", "’", "'", "‘", "'").Replace(alg)
+}
+
+// Invert 公式的逆序列, 用于由解法得到对应的打乱, 只支持正阶魔方、金字塔与斜转的记号
+func Invert(ev string, alg string) (string, error) {
+	p, err := New(ev)
+	if err != nil {
+		return "", err
+	}
+	switch p.(type) {
+	case *Cube, *Pyraminx, *Skewb, *FTO:
+	default:
+		return "", fmt.Errorf("%s 不支持求逆", ev)
+	}
+	moves, err := ParseMoves(ev, alg)
+	if err != nil {
+		return "", err
+	}
+	out := make([]string, 0, len(moves))
+	for i := len(moves) - 1; i >= 0; i-- {
+		m := moves[i]
+		switch {
+		case strings.HasSuffix(m, "2'"), strings.HasSuffix(m, "2"):
+			m = strings.TrimSuffix(m, "'")
+		case strings.HasSuffix(m, "'"):
+			m = strings.TrimSuffix(m, "'")
+		default:
+			m += "'"
+		}
+		out = append(out, m)
+	}
+	return strings.Join(out, " "), nil
+}
+
+// ParseMoves 按WCA记号拆分为单步转动, 有无法识别的转动时报错
+func ParseMoves(ev string, seq string) ([]string, error) {
+	p, err := New(ev)
+	if err != nil {
+		return nil, err
+	}
+	seq = NormalizeAlg(ev, seq)
+	if err = p.Apply(seq); err != nil {
+		return nil, err
+	}
+	return strings.Fields(seq), nil
+}
+
+// CheckScramble 检查打乱能否执行且执行后不是还原状态, 用于发现损坏的打乱
+func CheckScramble(ev string, scramble string) error {
+	p, err := New(ev)
+	if err != nil {
+		return err
+	}
+	if err = p.Apply(strings.TrimSpace(scramble)); err != nil {
+		return fmt.Errorf("打乱无法执行: %w", err)
+	}
+	if solvedFaces(p) {
+		return errors.New("打乱执行后仍为还原状态")
+	}
+	return nil
+}
+
+// Solves 执行打乱后再执行解法, 是否还原, 解法中可以有整体转体
+func Solves(ev string, scramble string, solution string) (bool, error) {
+	p, err := New(ev)
+	if err != nil {
+		return false, err
+	}
+	if err = p.Apply(strings.TrimSpace(scramble)); err != nil {
+		return false, fmt.Errorf("打乱无法执行: %w", err)
+	}
+	if err = p.Apply(NormalizeAlg(ev, solution)); err != nil {
+		return false, fmt.Errorf("解法无法执行: %w", err)
+	}
+	return solvedFaces(p), nil
+}
+
+// SolvesCase 公式能否还原打乱得到的情况, 允许公式前后调整顶层和整体转体
+func SolvesCase(ev string, setup string, alg string, mask CaseMask) (bool, error) {
+	p, err := New(ev)
+	if err != nil {
+		return false, err
+	}
+	if err = p.Apply(NormalizeAlg(ev, setup)); err != nil {
+		return false, fmt.Errorf("打乱无法执行: %w", err)
+	}
+	alg = NormalizeAlg(ev, alg)
+
+	cube, ok := p.(*Cube)
+	if !ok {
+		if err = p.Apply(alg); err != nil {
+			return false, fmt.Errorf("公式无法执行: %w", err)
+		}
+		return solvedFaces(p), nil
+	}
+
+	// 公式可能按其他朝向书写, 公式前同时尝试绕 y 轴转体和调整顶层
+	for _, pre := range cubeAUF {
+		for _, auf := range cubeAUF {
+			c := cube.clone()
+			if err = c.Apply(strings.ReplaceAll(pre, "U", "y") + " " + auf + " " + alg); err != nil {
+				return false, fmt.Errorf("公式无法执行: %w", err)
+			}
+			for _, rot := range cubeOrientations {
+				for _, post := range cubeAUF {
+					o := c.clone()
+					_ = o.Apply(rot + " " + post)
+					if o.matches(mask) {
+						return true, nil
+					}
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+var (
+	cubeAUF = []string{"", "U", "U2", "U'"}
+	// 24 种整体朝向
+	cubeOrientations = func() []string {
+		var out []string
+		for _, a := range []string{"", "x", "x2", "x'", "z", "z'"} {
+			for _, b := range []string{"", "y", "y2", "y'"} {
+				out = append(out, a+" "+b)
+			}
+		}
+		return out
+	}()
+)
+
+func (c *Cube) clone() *Cube {
+	p := *c.faceletPuzzle
+	p.colors = append([]int(nil), c.colors...)
+	return &Cube{faceletPuzzle: &p, n: c.n}
+}
+
+func (c *Cube) matches(mask CaseMask) bool {
+	for i, s := range c.stickers {
+		if mask.contains(c.n, s.pos) && c.colors[i] != c.init[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// solvedFaces 每个面颜色一致即视为还原, 不要求与初始朝向一致
+func solvedFaces(p Puzzle) bool {
+	var fp *faceletPuzzle
+	switch v := p.(type) {
+	case *Cube:
+		fp = v.faceletPuzzle
+	case *Pyraminx:
+		fp = v.faceletPuzzle
+	case *Skewb:
+		fp = v.faceletPuzzle
+	case *Megaminx:
+		fp = v.faceletPuzzle
+	case *FTO:
+		fp = v.faceletPuzzle
+	default:
+		return p.Solved()
+	}
+	faceColor := make(map[int]int)
+	for i, c := range fp.colors {
+		want, ok := faceColor[fp.init[i]]
+		if !ok {
+			faceColor[fp.init[i]] = c
+			continue
+		}
+		if want != c {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package puzzle
+
+import "testing"
+
+func TestSolves(t *testing.T) {
+	tests := []struct {
+		name     string
+		ev       string
+		scramble string
+		solution string
+		want     bool
+		wantErr  bool
+	}{
+		{name: "逆序还原", ev: "333fm", scramble: "R U R' F2 D", solution: "D' F2 R U' R'", want: true},
+		{name: "带转体", ev: "333fm", scramble: "R U F", solution: "x U' B' R'", want: true},
+		{name: "结束时朝向不同", ev: "333fm", scramble: "R U F", solution: "(F' U') R' y", want: true},
+		{name: "未还原", ev: "333fm", scramble: "R U F", solution: "F' U' R", want: false},
+		{name: "无法识别的转动", ev: "333fm", scramble: "R U F", solution: "F' U' Q'", wantErr: true},
+		{name: "二阶", ev: "222", scramble: "R U' F2", solution: "F2 U R'", want: true},
+		{name: "金字塔", ev: "pyram", scramble: "U L' r b", solution: "b' r' L U'", want: true},
+		{name: "SQ1", ev: "sq1", scramble: "(1,0) / (3,3) /", solution: "/ (-3,-3) / (-1,0)", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Solves(tt.ev, tt.scramble, tt.solution)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Solves() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Solves() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolvesCase(t *testing.T) {
+	tests := []struct {
+		name  string
+		ev    string
+		setup string
+		alg   string
+		mask  CaseMask
+		want  bool
+	}{
+		{name: "T PLL", ev: "333", setup: "R U R' U' R' F R2 U' R' U' R U R' F'", alg: "R U R' U' R' F R2 U' R' U' R U R' F'", mask: MaskAll, want: true},
+		{name: "需要调整顶层", ev: "333", setup: "U R U R' U' R' F R2 U' R' U' R U R' F' U2", alg: "[R U R' U'] R' F R2 U' R' U' R U R' F'", mask: MaskAll, want: true},
+		{name: "Sune 不能还原 T PLL", ev: "333", setup: "R U R' U' R' F R2 U' R' U' R U R' F'", alg: "R U R' U R U2 R'", mask: MaskAll, want: false},
+		{name: "OLL 只看顶面", ev: "333", setup: "R U2 R' U' R U' R'", alg: "R U R' U R U2 R'", mask: MaskOLL, want: true},
+		{name: "F2L 换了朝向", ev: "333", setup: "R U R' U'", alg: "y' U F U' F'", mask: MaskF2L, want: true},
+		{name: "F2L 未还原", ev: "333", setup: "R U R' U'", alg: "R U R'", mask: MaskF2L, want: false},
+		{name: "四阶单层转动", ev: "444", setup: "2R2 U2 2R2 Uw2 2R2 Uw2", alg: "Uw2 2R2 Uw2 2R2 U2 2R2", mask: MaskAll, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SolvesCase(tt.ev, tt.setup, tt.alg, tt.mask)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("SolvesCase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckScramble(t *testing.T) {
+	if err := CheckScramble("333", "R U R' U'"); err != nil {
+		t.Error(err)
+	}
+	if err := CheckScramble("333", "R U U' R'"); err == nil {
+		t.Error("还原状态的打乱应报错")
+	}
+	if err := CheckScramble("333", "R U R' Ux"); err == nil {
+		t.Error("损坏的打乱应报错")
+	}
+	if err := CheckScramble("sq1", "(1,0) / (1,0) /"); err == nil {
+		t.Error("无法执行的 SQ1 打乱应报错")
+	}
+}
+
+func TestInvert(t *testing.T) {
+	got, err := Invert("444", "Rw U2' 2R' F")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "F' 2R U2 Rw'"; got != want {
+		t.Errorf("Invert() = %s, want %s", got, want)
+	}
+	if _, err = Invert("sq1", "(1,0) /"); err == nil {
+		t.Error("SQ1 不支持求逆")
+	}
+}
@@ -100,6 +100,9 @@ func cube333ftScramble() string {
 	return output
 }
 
+// rustAvailable 已链接 Rust 静态库
+const rustAvailable = true
+
 var rustScrambleMp = map[string]func() string{
 	"333":    cube333Scramble,
 	"222":    cube222Scramble,
@@ -3,6 +3,7 @@
 package scramble
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -47,18 +48,12 @@ var rustScrambleMp = map[string]func() string{
 	"444bf":  cube444bfScramble,
 }
 
-// rustScramble 在 macOS 上返回错误提示或空列表，避免崩溃
-func (s *scramble) rustScramble(cube string, nums int) ([]string, error) {
-	// 选项 A: 直接返回错误，告诉用户 macOS 不支持
-	// return nil, errors.New("rust scramble not supported on macOS (disabled for build)")
+// rustAvailable macOS 上未链接 Rust 静态库, 打乱后端会跳过 rust
+const rustAvailable = false
 
-	// 选项 B: 返回空结果但不报错 (根据你的需求 "保留可用的接口（无效接口即可）")
-	// 这里生成 nums 个空字符串或者提示语
-	out := make([]string, nums)
-	for i := 0; i < nums; i++ {
-		out[i] = "[macOS-disabled]"
-	}
-	return out, nil
+// rustScramble 在 macOS 上直接返回错误, 由后端注册表回退到其他后端
+func (s *scramble) rustScramble(cube string, nums int) ([]string, error) {
+	return nil, errors.New("rust scramble not supported on macOS (disabled for build)")
 }
 
 func (s *scramble) rustTestLongScramble() string {
@@ -2,65 +2,96 @@ package scramble
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/guojia99/cubing-pro/src/configs"
 	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
 	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/scramble/puzzle"
 	"gorm.io/gorm"
 )
 
 type Scramble interface {
 	ScrambleWithComp(event event.Event) ([]string, error)
 	ScrambleWithEvent(event event.Event, number int) ([]string, error)
-	Scramble(ev string, num int) []string
+	Scramble(ev string, num int) ([]string, error)
 	Test() string
+	Metrics() Metrics
 	Image(scramble string, ev string) (string, error)
+	ImageFile(scramble string, ev string) (string, error)                                                    // 打乱图片写入临时文件, 用于机器人发送
+	ScrambleSheets(compName string, events []competition.CompetitionEvent, opt SheetOptions) ([]byte, error) // 可打印的打乱表
 
 	// 一体整合式
 	CubingProScrambles(cj competition.CompetitionJson) (competition.CompetitionJson, error)
 	TNoodleScrambles(cj competition.CompetitionJson) (competition.CompetitionJson, error)
 }
 
-func NewScramble(db *gorm.DB, scrambleType string, tNoodleEndpoint string, scrambleDrawType string, scrambleUrl string) Scramble {
-	if scrambleType == "" {
-		scrambleType = scrambleTypeTNoodle
+func NewScramble(db *gorm.DB, cfg configs.Scramble) Scramble {
+	if cfg.Type == "" {
+		cfg.Type = scrambleTypeTNoodle
 	}
-	if scrambleDrawType == "" {
-		scrambleDrawType = scrambleTypeDrawType2Mf8
+	if cfg.FilePath == "" {
+		cfg.FilePath = filepath.Join(os.TempDir(), "tnoodle")
+	}
+	if cfg.ScrambleDrawType == "" {
+		cfg.ScrambleDrawType = scrambleTypeDrawType2Mf8
 	}
 
-	s := &scramble{
-		scrambleType:     scrambleType,
-		tNoodleEndpoint:  tNoodleEndpoint,
-		scrambleDrawType: scrambleDrawType,
-		scrambleUrl:      scrambleUrl,
+	return &scramble{
+		scrambleType:     cfg.Type,
+		tNoodleEndpoint:  cfg.EndPoint,
+		tNoodleFilePath:  cfg.FilePath,
+		scrambleDrawType: cfg.ScrambleDrawType,
+		scrambleUrl:      cfg.ScrambleUrl,
+		backends:         cfg.Backends,
+		eventBackends:    cfg.EventBackends,
+		poolSize:         cfg.PoolSize,
 		db:               db,
 	}
-	if s.scrambleType == scrambleTypeRustTwisty {
-		//go s.loopRustScrambleCache()
-	}
-
-	return s
 }
 
 type scramble struct {
 	scrambleType    string
 	tNoodleEndpoint string
+	tNoodleFilePath string // TNoodle 打乱压缩包与pdf的保存目录
 
-	scrambleDrawType string // 2mf8
+	scrambleDrawType string // 2mf8, native, native_svg
 	scrambleUrl      string
 
+	backends      []string            // 后端尝试顺序, 为空时按 scrambleType 决定
+	eventBackends map[string][]string // 指定项目的后端顺序
+	poolSize      int                 // 每个项目预生成的打乱数, 0 不预生成
+
+	registryOnce sync.Once
+	registry     *backendRegistry
+
 	db *gorm.DB
 }
 
+func (s *scramble) getRegistry() *backendRegistry {
+	s.registryOnce.Do(func() {
+		order := s.backends
+		if len(order) == 0 {
+			order = defaultBackendOrder(s.scrambleType)
+		}
+		s.registry = newBackendRegistry(order, s.eventBackends, s.poolSize,
+			rustBackend{s: s}, tNoodleBackend{s: s}, goBackend{})
+	})
+	return s.registry
+}
+
+func (s *scramble) Metrics() Metrics { return s.getRegistry().Metrics() }
+
 func (s *scramble) Test() string {
-	switch s.scrambleType {
-	case scrambleTypeTNoodle:
-		return ""
-	case scrambleTypeRustTwisty:
-		return s.rustTestLongScramble()
+	out := s.Metrics().String()
+	if s.scrambleType == scrambleTypeRustTwisty {
+		out += s.rustTestLongScramble()
 	}
-	return ""
+	return out
 }
 
 const (
@@ -69,19 +100,24 @@ const (
 )
 
 const (
-	scrambleTypeRustTwisty   = "rust_twisty" // 狼的打乱
-	scrambleTypeTNoodle      = "tnoodle"
-	scrambleTypeDrawType2Mf8 = "2mf8"
+	scrambleTypeRustTwisty        = "rust_twisty" // 狼的打乱
+	scrambleTypeTNoodle           = "tnoodle"
+	scrambleTypeDrawType2Mf8      = "2mf8"
+	scrambleTypeDrawTypeNative    = "native"     // 内置绘制, 输出png
+	scrambleTypeDrawTypeNativeSvg = "native_svg" // 内置绘制, 输出svg
 )
 
 func (s *scramble) ScrambleWithEvent(event event.Event, number int) ([]string, error) {
 	if event.IsWCA {
-		return s.Scramble(event.ID, number), nil
+		return s.Scramble(event.ID, number)
+	}
+	if event.ScrambleDef != nil {
+		return s.definitionScrambles(*event.ScrambleDef, number, 0)
 	}
 
 	switch event.AutoScrambleKey {
 	case "FTO":
-		return s.autoScramble(FTOScrambleKey, 25, 30, number), nil
+		return s.autoScramble(FTOScrambleKey, number)
 	}
 
 	var evs []string
@@ -92,7 +128,10 @@ func (s *scramble) ScrambleWithEvent(event event.Event, number int) ([]string, e
 	var out []string
 	for i := 0; i < event.BaseRouteType.RouteMap().Rounds; i++ {
 		for _, ev := range evs {
-			data := s.Scramble(ev, 1)
+			data, err := s.Scramble(ev, 1)
+			if err != nil {
+				return nil, err
+			}
 			out = append(out, data...)
 		}
 	}
@@ -102,22 +141,25 @@ func (s *scramble) ScrambleWithEvent(event event.Event, number int) ([]string, e
 func (s *scramble) ScrambleWithComp(event event.Event) ([]string, error) {
 	if event.IsWCA {
 		if event.BaseRouteType.RouteMap().Repeatedly {
-			return s.Scramble(event.ID, repeatedlyNum), nil
+			return s.Scramble(event.ID, repeatedlyNum)
 		}
-		return s.Scramble(event.ID, event.BaseRouteType.RouteMap().Rounds+backupNum), nil
+		return s.Scramble(event.ID, event.BaseRouteType.RouteMap().Rounds+backupNum)
+	}
+	if event.ScrambleDef != nil {
+		return s.definitionScrambles(*event.ScrambleDef, event.BaseRouteType.RouteMap().Rounds+backupNum, 0)
 	}
 
 	switch event.AutoScrambleKey {
 	case "FTO":
-		return s.autoScramble(FTOScrambleKey, 25, 30, event.BaseRouteType.RouteMap().Rounds+backupNum), nil
+		return s.autoScramble(FTOScrambleKey, event.BaseRouteType.RouteMap().Rounds+backupNum)
 	}
 
 	switch event.ScrambleValue {
 	case "333mbf":
-		return s.Scramble("333bf", repeatedlyNum), nil
+		return s.Scramble("333bf", repeatedlyNum)
 	case "444", "444bf":
-		// 等狼优化到极致速度再采用随机状态
-		return s.autoScramble(Cube444ScrambleKey, 39, 49, event.BaseRouteType.RouteMap().Rounds+backupNum), nil
+		// 纯Go的同轴过滤随机转动, 不依赖 cgo 的狼打乱
+		return s.autoScramble(Cube444ScrambleKey, event.BaseRouteType.RouteMap().Rounds+backupNum)
 	}
 
 	var evs []string
@@ -128,7 +170,10 @@ func (s *scramble) ScrambleWithComp(event event.Event) ([]string, error) {
 	var out []string
 	for i := 0; i < event.BaseRouteType.RouteMap().Rounds; i++ {
 		for _, ev := range evs {
-			data := s.Scramble(ev, 1)
+			data, err := s.Scramble(ev, 1)
+			if err != nil {
+				return nil, err
+			}
 			out = append(out, data...)
 		}
 	}
@@ -136,35 +181,56 @@ func (s *scramble) ScrambleWithComp(event event.Event) ([]string, error) {
 	return out, nil
 }
 
-func (s *scramble) Scramble(ev string, num int) []string {
-	var wcaHandler func(string, int) ([]string, error)
-	switch s.scrambleType {
-	case scrambleTypeTNoodle:
-		wcaHandler = s.tNoodleCubeScramble
-	case scrambleTypeRustTwisty:
-		wcaHandler = s.rustScramble
-	}
-	if wcaHandler == nil {
-		//, fmt.Errorf("scramble type %s not supported", s.scrambleType)
-		return nil
-	}
-	data, err := wcaHandler(ev, num)
-	if err != nil {
+// Scramble 按项目配置的后端顺序生成, 前一个失败或生成损坏的打乱时使用下一个
+func (s *scramble) Scramble(ev string, num int) ([]string, error) {
+	return s.getRegistry().Scramble(ev, num)
+}
+
+// checkScramble 用魔方模型执行打乱, 无法执行或执行后仍为还原状态的视为损坏, 没有模型的项目不检查
+func checkScramble(ev string, scramble string) error {
+	if _, err := puzzle.New(ev); err != nil {
 		return nil
 	}
-
-	return data
+	return puzzle.CheckScramble(ev, scramble)
 }
 
 func (s *scramble) Image(scramble string, ev string) (string, error) {
 	switch s.scrambleDrawType {
 	case scrambleTypeDrawType2Mf8:
 		return s.SImageWith2mf8(scramble, ev)
+	case scrambleTypeDrawTypeNative:
+		img, err := puzzle.DrawPNG(ev, scramble)
+		return string(img), err
+	case scrambleTypeDrawTypeNativeSvg:
+		return puzzle.DrawSVG(ev, scramble)
 	default:
 		return "", fmt.Errorf("scramble draw type %s not supported", s.scrambleDrawType)
 	}
 }
 
+// ImageFile 聊天消息不支持svg, native_svg 时改为输出png, 返回临时文件路径
+func (s *scramble) ImageFile(scramble string, ev string) (string, error) {
+	var img, ext = "", ".jpg"
+	var err error
+	switch s.scrambleDrawType {
+	case scrambleTypeDrawTypeNative, scrambleTypeDrawTypeNativeSvg:
+		var data []byte
+		data, err = puzzle.DrawPNG(ev, scramble)
+		img, ext = string(data), ".png"
+	default:
+		img, err = s.Image(scramble, ev)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(os.TempDir(), fmt.Sprintf("%d%s", time.Now().UnixNano(), ext))
+	if err = os.WriteFile(filePath, []byte(img), 0644); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
 func (s *scramble) CubingProScrambles(cj competition.CompetitionJson) (competition.CompetitionJson, error) {
 	for i := 0; i < len(cj.Events); i++ {
 		ev := cj.Events[i]
@@ -175,25 +241,26 @@ func (s *scramble) CubingProScrambles(cj competition.CompetitionJson) (competiti
 		if err := s.db.Where("id = ?", ev.EventID).First(&eve).Error; err != nil {
 			continue
 		}
-
-		for j := 0; j < len(ev.Schedule); j++ {
-			if ev.Schedule[j].NotScramble {
-				continue
-			}
-			cj.Events[i].Schedule[j].Scrambles = make([][]string, 0)
-			for k := 0; k < ev.Schedule[j].ScrambleNums; k++ {
-				sc, err := s.ScrambleWithComp(eve)
-				if err != nil {
-					break
-				}
-				cj.Events[i].Schedule[j].Scrambles = append(cj.Events[i].Schedule[j].Scrambles, sc)
-			}
+		if err := s.cubingProEventScrambles(&cj.Events[i], eve); err != nil {
+			return cj, err
 		}
 	}
 	return cj, nil
 }
 
-func (s *scramble) TNoodleScrambles(cj competition.CompetitionJson) (competition.CompetitionJson, error) {
-	//TODO implement me
-	panic("implement me")
+func (s *scramble) cubingProEventScrambles(ev *competition.CompetitionEvent, eve event.Event) error {
+	for j := 0; j < len(ev.Schedule); j++ {
+		if ev.Schedule[j].NotScramble {
+			continue
+		}
+		ev.Schedule[j].Scrambles = make([][]string, 0)
+		for k := 0; k < ev.Schedule[j].ScrambleNums; k++ {
+			sc, err := s.ScrambleWithComp(eve)
+			if err != nil {
+				return fmt.Errorf("%s %s 打乱生成失败: %w", ev.EventName, ev.Schedule[j].Round, err)
+			}
+			ev.Schedule[j].Scrambles = append(ev.Schedule[j].Scrambles, sc)
+		}
+	}
+	return nil
 }
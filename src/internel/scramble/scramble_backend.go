@@ -0,0 +1,272 @@
+package scramble
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend 打乱生成后端
+type Backend interface {
+	Name() string
+	Support(ev string) bool
+	Generate(ev string, num int) ([]string, error)
+}
+
+const (
+	backendRust    = "rust"
+	backendTNoodle = "tnoodle"
+	backendGo      = "go"
+)
+
+// rustBackend 狼的Rust静态库, 需要cgo
+type rustBackend struct{ s *scramble }
+
+func (b rustBackend) Name() string { return backendRust }
+func (b rustBackend) Support(ev string) bool {
+	_, ok := rustScrambleMp[ev]
+	return ok && rustAvailable
+}
+func (b rustBackend) Generate(ev string, num int) ([]string, error) { return b.s.rustScramble(ev, num) }
+
+// tNoodleBackend WCA官方的TNoodle服务
+type tNoodleBackend struct{ s *scramble }
+
+func (b tNoodleBackend) Name() string           { return backendTNoodle }
+func (b tNoodleBackend) Support(ev string) bool { return b.s.tNoodleEndpoint != "" }
+func (b tNoodleBackend) Generate(ev string, num int) ([]string, error) {
+	return b.s.tNoodleCubeScramble(ev, num)
+}
+
+// goBackend 纯Go实现的随机转动打乱
+type goBackend struct{}
+
+var goBackendKeys = map[string]autoScrambleKey{
+	"fto":   FTOScrambleKey,
+	"444":   Cube444ScrambleKey,
+	"444bf": Cube444ScrambleKey,
+}
+
+func (b goBackend) Name() string { return backendGo }
+func (b goBackend) Support(ev string) bool {
+	_, ok := goBackendKeys[ev]
+	return ok
+}
+func (b goBackend) Generate(ev string, num int) ([]string, error) {
+	key, ok := goBackendKeys[ev]
+	if !ok {
+		return nil, fmt.Errorf("go backend not support %s", ev)
+	}
+	var out []string
+	for i := 0; i < num; i++ {
+		sc, err := key.generate()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sc)
+	}
+	return out, nil
+}
+
+// BackendMetric 某个后端生成某个项目打乱的统计
+type BackendMetric struct {
+	Backend   string        `json:"Backend"`
+	Event     string        `json:"Event"`
+	Success   int64         `json:"Success"`
+	Failure   int64         `json:"Failure"`
+	Scrambles int64         `json:"Scrambles"` // 成功生成的打乱数
+	AvgTime   time.Duration `json:"AvgTime"`   // 成功时每条打乱的平均耗时
+	MaxTime   time.Duration `json:"MaxTime"`   // 单次调用最长耗时
+	LastError string        `json:"LastError,omitempty"`
+
+	totalTime time.Duration
+}
+
+// PoolMetric 预生成打乱池的状态
+type PoolMetric struct {
+	Event string `json:"Event"`
+	Size  int    `json:"Size"`
+	Cap   int    `json:"Cap"`
+	Hit   int64  `json:"Hit"`  // 从池中取出的打乱数
+	Miss  int64  `json:"Miss"` // 池中不够时现场生成的打乱数
+}
+
+type Metrics struct {
+	Backends []BackendMetric `json:"Backends"`
+	Pools    []PoolMetric    `json:"Pools"`
+}
+
+// backendRegistry 按项目选择后端, 依次尝试直到成功, 并维护预生成的打乱池
+type backendRegistry struct {
+	backends      map[string]Backend
+	order         []string            // 默认顺序
+	eventBackends map[string][]string // 指定项目的顺序
+	poolSize      int
+
+	mu      sync.Mutex
+	metrics map[string]*BackendMetric // key: backend/event
+	pools   map[string]*scramblePool
+}
+
+func newBackendRegistry(order []string, eventBackends map[string][]string, poolSize int, backends ...Backend) *backendRegistry {
+	r := &backendRegistry{
+		backends:      make(map[string]Backend),
+		order:         order,
+		eventBackends: eventBackends,
+		poolSize:      poolSize,
+		metrics:       make(map[string]*BackendMetric),
+		pools:         make(map[string]*scramblePool),
+	}
+	for _, b := range backends {
+		r.backends[b.Name()] = b
+	}
+	return r
+}
+
+// backendsFor 项目可以使用的后端, 按尝试顺序
+func (r *backendRegistry) backendsFor(ev string) []Backend {
+	order := r.order
+	if o, ok := r.eventBackends[ev]; ok && len(o) > 0 {
+		order = o
+	}
+	var out []Backend
+	for _, name := range order {
+		if b, ok := r.backends[name]; ok && b.Support(ev) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// generate 依次使用各个后端生成, 返回的打乱都经过校验
+func (r *backendRegistry) generate(ev string, num int) ([]string, error) {
+	backends := r.backendsFor(ev)
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("没有支持 %s 的打乱后端", ev)
+	}
+
+	var errs []error
+	for _, b := range backends {
+		start := time.Now()
+		out, err := b.Generate(ev, num)
+		if err == nil && len(out) != num {
+			err = fmt.Errorf("需要 %d 条打乱, 实际生成 %d 条", num, len(out))
+		}
+		if err == nil {
+			for _, sc := range out {
+				if err = checkScramble(ev, sc); err != nil {
+					err = fmt.Errorf("打乱损坏 `%s`: %w", sc, err)
+					break
+				}
+			}
+		}
+		r.record(b.Name(), ev, num, time.Since(start), err)
+		if err == nil {
+			return out, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", b.Name(), err))
+	}
+	return nil, fmt.Errorf("%s 打乱生成失败: %w", ev, errors.Join(errs...))
+}
+
+func (r *backendRegistry) record(backend, ev string, num int, cost time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := backend + "/" + ev
+	m, ok := r.metrics[key]
+	if !ok {
+		m = &BackendMetric{Backend: backend, Event: ev}
+		r.metrics[key] = m
+	}
+	m.MaxTime = max(m.MaxTime, cost)
+	if err != nil {
+		m.Failure++
+		m.LastError = err.Error()
+		return
+	}
+	m.Success++
+	m.Scrambles += int64(num)
+	m.totalTime += cost
+	m.AvgTime = m.totalTime / time.Duration(m.Scrambles)
+}
+
+// Scramble 优先从打乱池中取, 不够的现场生成
+func (r *backendRegistry) Scramble(ev string, num int) ([]string, error) {
+	if num <= 0 {
+		return nil, nil
+	}
+	if r.poolSize <= 0 {
+		return r.generate(ev, num)
+	}
+
+	pool := r.pool(ev)
+	out := pool.take(num)
+	if len(out) < num {
+		more, err := r.generate(ev, num-len(out))
+		if err != nil {
+			pool.put(out)
+			return nil, err
+		}
+		out = append(out, more...)
+	}
+	pool.refill()
+	return out, nil
+}
+
+func (r *backendRegistry) pool(ev string) *scramblePool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.pools[ev]
+	if !ok {
+		p = newScramblePool(ev, r.poolSize, r.generate)
+		r.pools[ev] = p
+	}
+	return p
+}
+
+func (r *backendRegistry) Metrics() Metrics {
+	r.mu.Lock()
+	var out Metrics
+	for _, m := range r.metrics {
+		out.Backends = append(out.Backends, *m)
+	}
+	pools := make([]*scramblePool, 0, len(r.pools))
+	for _, p := range r.pools {
+		pools = append(pools, p)
+	}
+	r.mu.Unlock()
+
+	for _, p := range pools {
+		out.Pools = append(out.Pools, p.metric())
+	}
+	sort.Slice(out.Backends, func(i, j int) bool {
+		if out.Backends[i].Event != out.Backends[j].Event {
+			return out.Backends[i].Event < out.Backends[j].Event
+		}
+		return out.Backends[i].Backend < out.Backends[j].Backend
+	})
+	sort.Slice(out.Pools, func(i, j int) bool { return out.Pools[i].Event < out.Pools[j].Event })
+	return out
+}
+
+func (m Metrics) String() string {
+	var sb strings.Builder
+	for _, b := range m.Backends {
+		sb.WriteString(fmt.Sprintf("%s[%s] => 成功:%d;失败:%d;平均:%v;最长:%v\n", b.Event, b.Backend, b.Success, b.Failure, b.AvgTime, b.MaxTime))
+	}
+	for _, p := range m.Pools {
+		sb.WriteString(fmt.Sprintf("%s[pool] => %d/%d;命中:%d;未命中:%d\n", p.Event, p.Size, p.Cap, p.Hit, p.Miss))
+	}
+	return sb.String()
+}
+
+// defaultBackendOrder 未配置后端顺序时, 按打乱类型决定优先级, 纯Go兜底
+func defaultBackendOrder(scrambleType string) []string {
+	if scrambleType == scrambleTypeRustTwisty {
+		return []string{backendRust, backendTNoodle, backendGo}
+	}
+	return []string{backendTNoodle, backendRust, backendGo}
+}
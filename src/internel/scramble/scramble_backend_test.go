@@ -0,0 +1,153 @@
+package scramble
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeBackend struct {
+	name    string
+	events  []string
+	out     string
+	err     error
+	calls   atomic.Int64
+	blocked chan struct{}
+}
+
+func (b *fakeBackend) Name() string { return b.name }
+func (b *fakeBackend) Support(ev string) bool {
+	for _, e := range b.events {
+		if e == ev {
+			return true
+		}
+	}
+	return false
+}
+func (b *fakeBackend) Generate(ev string, num int) ([]string, error) {
+	b.calls.Add(1)
+	if b.blocked != nil {
+		<-b.blocked
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	var out []string
+	for i := 0; i < num; i++ {
+		out = append(out, b.out)
+	}
+	return out, nil
+}
+
+func Test_backendRegistry_generate(t *testing.T) {
+	a := &fakeBackend{name: "a", events: []string{"333"}, err: errors.New("a down")}
+	b := &fakeBackend{name: "b", events: []string{"333", "222"}, out: "R U F"}
+	c := &fakeBackend{name: "c", events: []string{"333", "222"}, out: "R U R' U'"}
+	bad := &fakeBackend{name: "bad", events: []string{"222"}, out: "R R'"}
+
+	r := newBackendRegistry([]string{"a", "b", "c"}, map[string][]string{"222": {"bad", "c"}}, 0, a, b, c, bad)
+
+	t.Run("fallback", func(t *testing.T) {
+		got, err := r.Scramble("333", 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 3 || got[0] != "R U F" {
+			t.Fatalf("got %v", got)
+		}
+		if c.calls.Load() != 0 {
+			t.Fatalf("c should not be called")
+		}
+	})
+
+	t.Run("corrupted scramble falls back", func(t *testing.T) {
+		got, err := r.Scramble("222", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[0] != "R U R' U'" {
+			t.Fatalf("got %v", got)
+		}
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		r2 := newBackendRegistry([]string{"a"}, nil, 0, a)
+		_, err := r2.Scramble("333", 1)
+		if err == nil || !strings.Contains(err.Error(), "a down") {
+			t.Fatalf("want error from backend, got %v", err)
+		}
+		if _, err = r2.Scramble("sq1", 1); err == nil {
+			t.Fatalf("want error for unsupported event")
+		}
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		m := r.Metrics()
+		var aFail, bOk, badFail bool
+		for _, bm := range m.Backends {
+			switch {
+			case bm.Backend == "a" && bm.Event == "333":
+				aFail = bm.Failure == 1 && bm.LastError == "a down"
+			case bm.Backend == "b" && bm.Event == "333":
+				bOk = bm.Success == 1 && bm.Scrambles == 3
+			case bm.Backend == "bad" && bm.Event == "222":
+				badFail = bm.Failure == 1
+			}
+		}
+		if !aFail || !bOk || !badFail {
+			t.Fatalf("unexpected metrics %+v", m.Backends)
+		}
+	})
+}
+
+func Test_backendRegistry_pool(t *testing.T) {
+	b := &fakeBackend{name: "b", events: []string{"333"}, out: "R U F"}
+	r := newBackendRegistry([]string{"b"}, nil, 10, b)
+
+	got, err := r.Scramble("333", 2)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("got %v, %v", got, err)
+	}
+
+	// 后台补满打乱池
+	deadline := time.Now().Add(time.Second)
+	for r.pool("333").metric().Size != 10 {
+		if time.Now().After(deadline) {
+			t.Fatalf("pool not refilled: %+v", r.pool("333").metric())
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	calls := b.calls.Load()
+	if got, err = r.Scramble("333", 4); err != nil || len(got) != 4 {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	if b.calls.Load() != calls {
+		t.Fatalf("scrambles should come from the pool")
+	}
+	m := r.pool("333").metric()
+	if m.Hit != 4 || m.Miss != 2 || m.Size != 6 {
+		t.Fatalf("unexpected pool metric %+v", m)
+	}
+}
+
+func Test_scramblePool_refillOnce(t *testing.T) {
+	b := &fakeBackend{name: "b", events: []string{"333"}, out: "R U F", blocked: make(chan struct{})}
+	p := newScramblePool("333", 4, b.Generate)
+	p.refill()
+	p.refill()
+	close(b.blocked)
+
+	deadline := time.Now().Add(time.Second)
+	for p.metric().Size != 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("pool not refilled: %+v", p.metric())
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+	if b.calls.Load() != 1 {
+		t.Fatalf("refill should run once at a time, got %d calls", b.calls.Load())
+	}
+}
@@ -0,0 +1,135 @@
+package scramble
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+)
+
+const (
+	maxDefinitionLength = 500 // 自定义随机转动打乱的最多步数
+	maxDefinitionDepth  = 3   // 组合打乱引用其他自定义项目的最大层数
+)
+
+// CheckDefinition 校验自定义打乱规则
+func CheckDefinition(def event.ScrambleDefinition) error {
+	if len(def.Moves) == 0 && len(def.Compose) == 0 {
+		return errors.New("需要设置转动或组合的打乱")
+	}
+	if len(def.Moves) != 0 && len(def.Compose) != 0 {
+		return errors.New("转动与组合的打乱只能设置一种")
+	}
+	for _, c := range def.Compose {
+		if strings.TrimSpace(c) == "" {
+			return errors.New("组合的打乱不能为空")
+		}
+	}
+	if len(def.Moves) == 0 {
+		return nil
+	}
+
+	for _, m := range append(append([]string{}, def.Moves...), def.Suffixes...) {
+		if strings.ContainsAny(m, " \t\n") {
+			return fmt.Errorf("转动 `%s` 不能包含空白", m)
+		}
+	}
+	for _, m := range def.Moves {
+		if m == "" {
+			return errors.New("转动不能为空")
+		}
+	}
+	if len(def.Axis) != 0 && len(def.Axis) != len(def.Moves) {
+		return fmt.Errorf("轴的数量 %d 与转动数量 %d 不一致", len(def.Axis), len(def.Moves))
+	}
+	minL, maxL := definitionLength(def)
+	if minL <= 0 || maxL > maxDefinitionLength || minL > maxL {
+		return fmt.Errorf("打乱步数需要在 1 ~ %d 之间, 且最少步数不大于最多步数", maxDefinitionLength)
+	}
+
+	// 所有转动同轴时, 选到该轴最后一个转动后没有可接的转动
+	key := definitionKey(def)
+	axes := make(map[int]struct{})
+	for _, a := range key.Axis {
+		axes[a] = struct{}{}
+	}
+	if len(axes) == 1 && maxL > 1 {
+		return errors.New("所有转动同轴时, 打乱步数只能为 1")
+	}
+	return nil
+}
+
+func definitionLength(def event.ScrambleDefinition) (int, int) {
+	if def.MaxLength == 0 {
+		return def.MinLength, def.MinLength
+	}
+	return def.MinLength, def.MaxLength
+}
+
+// definitionKey 随机转动规则, 未设置轴时每个转动自成一轴
+func definitionKey(def event.ScrambleDefinition) autoScrambleKey {
+	key := autoScrambleKey{Moves: def.Moves, Axis: def.Axis, Suffixes: def.Suffixes}
+	if len(key.Axis) == 0 {
+		key.Axis = make([]int, len(def.Moves))
+		for i := range key.Axis {
+			key.Axis[i] = i
+		}
+	}
+	if len(key.Suffixes) == 0 {
+		key.Suffixes = []string{"", "'"}
+	}
+	return key
+}
+
+// definitionScrambles 按自定义规则生成 num 条打乱, 组合的打乱每条按行拼接, 每行以打乱来源开头
+func (s *scramble) definitionScrambles(def event.ScrambleDefinition, num int, depth int) ([]string, error) {
+	if err := CheckDefinition(def); err != nil {
+		return nil, err
+	}
+	if depth > maxDefinitionDepth {
+		return nil, errors.New("组合的打乱引用层数过多")
+	}
+
+	out := make([]string, num)
+	if len(def.Moves) != 0 {
+		key := definitionKey(def)
+		minL, maxL := definitionLength(def)
+		for i := range out {
+			key.Length = minL + rand.Intn(maxL-minL+1)
+			sc, err := key.generate()
+			if err != nil {
+				return nil, err
+			}
+			out[i] = sc
+		}
+		return out, nil
+	}
+
+	for _, c := range def.Compose {
+		c = strings.TrimSpace(c)
+		parts, err := s.composeScrambles(c, num, depth)
+		if err != nil {
+			return nil, fmt.Errorf("组合打乱 %s 生成失败: %w", c, err)
+		}
+		for i := range out {
+			if out[i] != "" {
+				out[i] += "\n"
+			}
+			out[i] += c + ": " + parts[i]
+		}
+	}
+	return out, nil
+}
+
+// composeScrambles 组合中的一项, 优先使用定义了打乱规则的项目, 其次为打乱后端支持的项目
+func (s *scramble) composeScrambles(key string, num int, depth int) ([]string, error) {
+	if s.db != nil {
+		var eve event.Event
+		if err := s.db.Where("id = ?", key).First(&eve).Error; err == nil && eve.ScrambleDef != nil {
+			return s.definitionScrambles(*eve.ScrambleDef, num, depth+1)
+		}
+	}
+	return s.Scramble(key, num)
+}
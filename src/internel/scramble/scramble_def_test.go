@@ -0,0 +1,90 @@
+package scramble
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+)
+
+func TestCheckDefinition(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     event.ScrambleDefinition
+		wantErr bool
+	}{
+		{name: "moves", def: event.ScrambleDefinition{Moves: []string{"U", "R"}, MinLength: 10}},
+		{name: "compose", def: event.ScrambleDefinition{Compose: []string{"333", "222"}}},
+		{name: "empty", def: event.ScrambleDefinition{}, wantErr: true},
+		{name: "both", def: event.ScrambleDefinition{Moves: []string{"U"}, Compose: []string{"333"}, MinLength: 1}, wantErr: true},
+		{name: "no length", def: event.ScrambleDefinition{Moves: []string{"U", "R"}}, wantErr: true},
+		{name: "min > max", def: event.ScrambleDefinition{Moves: []string{"U", "R"}, MinLength: 10, MaxLength: 5}, wantErr: true},
+		{name: "too long", def: event.ScrambleDefinition{Moves: []string{"U", "R"}, MinLength: 10, MaxLength: 1000}, wantErr: true},
+		{name: "axis count", def: event.ScrambleDefinition{Moves: []string{"U", "R"}, Axis: []int{0}, MinLength: 10}, wantErr: true},
+		{name: "single axis", def: event.ScrambleDefinition{Moves: []string{"U", "D"}, Axis: []int{0, 0}, MinLength: 10}, wantErr: true},
+		{name: "single axis within moves", def: event.ScrambleDefinition{Moves: []string{"U", "D"}, Axis: []int{0, 0}, MinLength: 2}, wantErr: true},
+		{name: "single axis one move", def: event.ScrambleDefinition{Moves: []string{"U", "D"}, Axis: []int{0, 0}, MinLength: 1}},
+		{name: "space in move", def: event.ScrambleDefinition{Moves: []string{"U R"}, MinLength: 10}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckDefinition(tt.def); (err != nil) != tt.wantErr {
+				t.Errorf("CheckDefinition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_scramble_definitionScrambles(t *testing.T) {
+	s := &scramble{}
+
+	t.Run("moves", func(t *testing.T) {
+		def := event.ScrambleDefinition{
+			Moves:     []string{"U", "D", "R", "L"},
+			Axis:      []int{0, 0, 1, 1},
+			Suffixes:  []string{"", "2"},
+			MinLength: 8,
+			MaxLength: 12,
+		}
+		data, err := s.ScrambleWithEvent(event.Event{ScrambleDef: &def}, 50)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 50 {
+			t.Fatalf("got %d scrambles", len(data))
+		}
+		for _, sc := range data {
+			moves := strings.Fields(sc)
+			if len(moves) < 8 || len(moves) > 12 {
+				t.Fatalf("length out of range: %s", sc)
+			}
+			for i := 1; i < len(moves); i++ {
+				if moves[i][:1] == moves[i-1][:1] {
+					t.Fatalf("repeated face: %s", sc)
+				}
+			}
+		}
+	})
+
+	t.Run("compose", func(t *testing.T) {
+		def := event.ScrambleDefinition{Compose: []string{"fto", "444"}}
+		data, err := s.ScrambleWithComp(event.Event{ScrambleDef: &def, BaseRouteType: event.RouteType3roundsBest})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 3+backupNum {
+			t.Fatalf("got %d scrambles", len(data))
+		}
+		lines := strings.Split(data[0], "\n")
+		if len(lines) != 2 || !strings.HasPrefix(lines[0], "fto: ") || !strings.HasPrefix(lines[1], "444: ") {
+			t.Fatalf("unexpected scramble %q", data[0])
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		def := event.ScrambleDefinition{Compose: []string{"not-exist"}}
+		if _, err := s.ScrambleWithEvent(event.Event{ScrambleDef: &def}, 1); err == nil {
+			t.Fatal("want error")
+		}
+	})
+}
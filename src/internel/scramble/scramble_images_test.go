@@ -1,7 +1,9 @@
 package scramble
 
 import (
+	"bytes"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -50,3 +52,20 @@ func Test_scramble_SImageWith2mf81(t *testing.T) {
 		})
 	}
 }
+
+func Test_scramble_ImageFileWithNativeSvg(t *testing.T) {
+	s := &scramble{scrambleDrawType: scrambleTypeDrawTypeNativeSvg}
+	filePath, err := s.ImageFile("R U R' U'", "333")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(filePath)
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(filePath, ".png") || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Errorf("native_svg 应输出png文件, got %s", filePath)
+	}
+}
@@ -0,0 +1,77 @@
+package scramble
+
+import (
+	"sync"
+
+	"github.com/2mf8/Better-Bot-Go/log"
+)
+
+// scramblePool 单个项目预生成的打乱, 容量固定, 低于一半时后台补充
+type scramblePool struct {
+	ev       string
+	cap      int
+	generate func(ev string, num int) ([]string, error)
+
+	mu        sync.Mutex
+	scrambles []string
+	filling   bool
+	hit, miss int64
+}
+
+func newScramblePool(ev string, cap int, generate func(string, int) ([]string, error)) *scramblePool {
+	return &scramblePool{ev: ev, cap: cap, generate: generate}
+}
+
+func (p *scramblePool) take(num int) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := min(num, len(p.scrambles))
+	out := append([]string(nil), p.scrambles[:n]...)
+	p.scrambles = p.scrambles[n:]
+	p.hit += int64(n)
+	p.miss += int64(num - n)
+	return out
+}
+
+// put 放回未使用的打乱
+func (p *scramblePool) put(scrambles []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scrambles = append(scrambles, p.scrambles...)
+	if len(p.scrambles) > p.cap {
+		p.scrambles = p.scrambles[:p.cap]
+	}
+}
+
+// refill 后台补充到满, 同一时间只有一个补充任务
+func (p *scramblePool) refill() {
+	p.mu.Lock()
+	if p.filling || len(p.scrambles) > p.cap/2 {
+		p.mu.Unlock()
+		return
+	}
+	p.filling = true
+	need := p.cap - len(p.scrambles)
+	p.mu.Unlock()
+
+	go func() {
+		out, err := p.generate(p.ev, need)
+		if err != nil {
+			log.Errorf("scramble pool %s refill error: %v", p.ev, err)
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.filling = false
+		p.scrambles = append(p.scrambles, out...)
+		if len(p.scrambles) > p.cap {
+			p.scrambles = p.scrambles[:p.cap]
+		}
+	}()
+}
+
+func (p *scramblePool) metric() PoolMetric {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolMetric{Event: p.ev, Size: len(p.scrambles), Cap: p.cap, Hit: p.hit, Miss: p.miss}
+}
@@ -0,0 +1,277 @@
+package scramble
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/scramble/puzzle"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// SheetOptions 打乱表导出选项
+type SheetOptions struct {
+	FontPath string // 标题使用的中文字体, 为空时使用不含中文的 Go 字体
+	Password string // 压缩包密码, 为空时不加密
+}
+
+// 打乱表按 150dpi 渲染 A4 纸
+const (
+	sheetWidth   = 1240
+	sheetHeight  = 1754
+	sheetMargin  = 70
+	sheetLabelW  = 90
+	sheetImageW  = 220
+	sheetImageH  = 160
+	sheetPadding = 16
+)
+
+// sheetRow 打乱表中的一行
+type sheetRow struct {
+	label    string
+	ev       string // 用于绘制打乱图的项目
+	scramble string
+	extra    bool
+}
+
+// ScrambleSheets 将各轮次的打乱生成可打印的 PDF, 每个轮次一个文件, 打包为压缩包
+// events 中的 Schedule.Scrambles 需要已经填好, 密封的打乱由调用方解密
+func (s *scramble) ScrambleSheets(compName string, events []competition.CompetitionEvent, opt SheetOptions) ([]byte, error) {
+	fonts, err := newSheetFonts(opt.FontPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []ZipFile
+	for _, ev := range events {
+		var eve event.Event
+		if s.db != nil {
+			_ = s.db.Where("id = ?", ev.EventID).First(&eve).Error
+		}
+		if eve.ID == "" {
+			eve = event.Event{IsWCA: true, BaseRouteType: ev.EventRoute}
+			eve.ID = ev.EventID
+		}
+
+		for _, schedule := range ev.Schedule {
+			if schedule.NotScramble || len(schedule.Scrambles) == 0 {
+				continue
+			}
+			data, err := scrambleSheetPDF(fonts, compName, ev, eve, schedule)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s 打乱表生成失败: %w", ev.EventName, schedule.Round, err)
+			}
+			files = append(files, ZipFile{
+				Name: fmt.Sprintf("%s - %s - %s.pdf", compName, ev.EventName, schedule.Round),
+				Data: data,
+			})
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("没有可以导出的打乱")
+	}
+	return packZip(files, opt.Password)
+}
+
+// sheetEvents 每一把打乱对应的魔方, 与 ScrambleWithComp 的生成规则一致, 以及正式打乱的数量(其余为备打)
+func sheetEvents(eve event.Event, num int) ([]string, int) {
+	route := eve.BaseRouteType.RouteMap()
+	evs := []string{eve.ID}
+	switch {
+	case eve.IsWCA:
+	case eve.ScrambleDef != nil && eve.PuzzleID != "":
+		evs = []string{eve.PuzzleID}
+	case eve.AutoScrambleKey == "FTO":
+		evs = []string{"fto"}
+	case eve.ScrambleValue == "333mbf":
+		evs = []string{"333bf"}
+	case eve.ScrambleValue != "":
+		evs = eve.ScrambleValues()
+	}
+
+	if route.Repeatedly || eve.ScrambleValue == "333mbf" {
+		return evs, num
+	}
+	return evs, min(num, route.Rounds*len(evs))
+}
+
+func sheetRows(eve event.Event, scrambles []string) []sheetRow {
+	evs, attempts := sheetEvents(eve, len(scrambles))
+	var rows []sheetRow
+	for i, sc := range scrambles {
+		row := sheetRow{ev: evs[i%len(evs)], scramble: sc}
+		switch {
+		case i >= attempts:
+			row.label, row.extra = fmt.Sprintf("E%d", i-attempts+1), true
+		case len(evs) > 1:
+			row.label = fmt.Sprintf("%d-%d", i/len(evs)+1, i%len(evs)+1)
+		default:
+			row.label = fmt.Sprintf("%d", i+1)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+type sheetFonts struct {
+	title, subtitle, text, small font.Face
+}
+
+// newSheetFonts 标题等文字使用配置的中文字体, 打乱使用等宽字体
+func newSheetFonts(path string) (sheetFonts, error) {
+	var err error
+	title := goregular.TTF
+	if path != "" {
+		if title, err = os.ReadFile(path); err != nil {
+			return sheetFonts{}, fmt.Errorf("字体加载失败: %w", err)
+		}
+	}
+	titleFont, err := truetype.Parse(title)
+	if err != nil {
+		return sheetFonts{}, fmt.Errorf("字体加载失败: %w", err)
+	}
+	monoFont, err := truetype.Parse(gomono.TTF)
+	if err != nil {
+		return sheetFonts{}, err
+	}
+
+	face := func(f *truetype.Font, points float64) font.Face {
+		return truetype.NewFace(f, &truetype.Options{Size: points})
+	}
+	return sheetFonts{
+		title:    face(titleFont, 40),
+		subtitle: face(titleFont, 30),
+		small:    face(titleFont, 18),
+		text:     face(monoFont, 24),
+	}, nil
+}
+
+// scrambleSheetPDF 一个轮次的打乱表, 每组打乱另起一页
+func scrambleSheetPDF(fonts sheetFonts, compName string, ev competition.CompetitionEvent, eve event.Event, schedule competition.Schedule) ([]byte, error) {
+	var pages []*gg.Context
+	for g, scrambles := range schedule.Scrambles {
+		header := fmt.Sprintf("%s  %s  %s组", ev.EventName, schedule.Round, sheetGroupName(g))
+		var dc *gg.Context
+		var y float64
+		newPage := func() {
+			dc = gg.NewContext(sheetWidth, sheetHeight)
+			dc.SetRGB(1, 1, 1)
+			dc.Clear()
+			dc.SetRGB(0, 0, 0)
+			dc.SetFontFace(fonts.title)
+			dc.DrawStringAnchored(compName, sheetWidth/2, sheetMargin, 0.5, 0.5)
+			dc.SetFontFace(fonts.subtitle)
+			dc.DrawStringAnchored(header, sheetWidth/2, sheetMargin+60, 0.5, 0.5)
+			y = sheetMargin + 100
+			pages = append(pages, dc)
+		}
+		newPage()
+
+		var extraTitle bool
+		for _, row := range sheetRows(eve, scrambles) {
+			lines := sheetWrap(dc, fonts.text, row.scramble)
+			h := max(float64(len(lines))*dc.FontHeight()*1.5, sheetImageH) + sheetPadding*2
+			if row.extra && !extraTitle {
+				h += 50
+			}
+			if y+h > sheetHeight-sheetMargin {
+				newPage()
+			}
+			if row.extra && !extraTitle {
+				extraTitle = true
+				dc.SetFontFace(fonts.subtitle)
+				dc.DrawString("备打", sheetMargin, y+38)
+				y += 50
+				h -= 50
+			}
+			drawSheetRow(dc, fonts, row, lines, y, h)
+			y += h
+		}
+	}
+
+	total := len(pages)
+	images := make([]image.Image, 0, total)
+	for i, dc := range pages {
+		dc.SetFontFace(fonts.small)
+		dc.SetRGB(0.4, 0.4, 0.4)
+		dc.DrawStringAnchored(fmt.Sprintf("第 %d / %d 页", i+1, total), sheetWidth/2, sheetHeight-sheetMargin/2, 0.5, 0.5)
+		images = append(images, dc.Image())
+	}
+	return imagePDF(images)
+}
+
+func drawSheetRow(dc *gg.Context, fonts sheetFonts, row sheetRow, lines []string, y, h float64) {
+	x0, x1 := float64(sheetMargin), float64(sheetWidth-sheetMargin)
+	dc.SetRGB(0, 0, 0)
+	dc.SetLineWidth(2)
+	dc.DrawRectangle(x0, y, x1-x0, h)
+	dc.Stroke()
+	dc.DrawLine(x0+sheetLabelW, y, x0+sheetLabelW, y+h)
+	dc.DrawLine(x1-sheetImageW-sheetPadding*2, y, x1-sheetImageW-sheetPadding*2, y+h)
+	dc.Stroke()
+
+	dc.SetFontFace(fonts.subtitle)
+	dc.DrawStringAnchored(row.label, x0+sheetLabelW/2, y+h/2, 0.5, 0.5)
+
+	dc.SetFontFace(fonts.text)
+	lineH := dc.FontHeight() * 1.5
+	ty := y + h/2 - float64(len(lines))*lineH/2 + lineH/2
+	for i, line := range lines {
+		dc.DrawStringAnchored(line, x0+sheetLabelW+sheetPadding, ty+float64(i)*lineH, 0, 0.5)
+	}
+
+	img := sheetScrambleImage(row.ev, row.scramble)
+	if img == nil {
+		return
+	}
+	b := img.Bounds()
+	scale := min(float64(sheetImageW)/float64(b.Dx()), float64(sheetImageH)/float64(b.Dy()))
+	w, ih := int(float64(b.Dx())*scale), int(float64(b.Dy())*scale)
+	dst := image.NewRGBA(image.Rect(0, 0, w, ih))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, xdraw.Over, nil)
+	dc.DrawImage(dst, int(x1)-sheetPadding-sheetImageW+(sheetImageW-w)/2, int(y+h/2)-ih/2)
+}
+
+// sheetWrap 按打乱的宽度折行, 打乱自带的换行(如五魔)保留
+func sheetWrap(dc *gg.Context, face font.Face, scramble string) []string {
+	dc.SetFontFace(face)
+	width := float64(sheetWidth - sheetMargin*2 - sheetLabelW - sheetImageW - sheetPadding*4)
+	var out []string
+	for _, line := range strings.Split(strings.TrimSpace(scramble), "\n") {
+		out = append(out, dc.WordWrap(strings.TrimSpace(line), width)...)
+	}
+	return out
+}
+
+// sheetScrambleImage 内置绘制的打乱图, 没有模型的项目不画
+func sheetScrambleImage(ev string, scramble string) image.Image {
+	data, err := puzzle.DrawPNG(ev, scramble)
+	if err != nil {
+		return nil
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	return img
+}
+
+func sheetGroupName(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+	return name
+}
@@ -0,0 +1,134 @@
+package scramble
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+)
+
+func Test_sheetRows(t *testing.T) {
+	wca := event.Event{IsWCA: true, BaseRouteType: event.RouteType5RoundsAvgHT}
+	wca.ID = "333"
+	rows := sheetRows(wca, []string{"R", "U", "F", "L", "D", "B", "R2"})
+	if len(rows) != 7 || rows[4].label != "5" || !rows[5].extra || rows[6].label != "E2" || rows[0].ev != "333" {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+
+	relay := event.Event{ScrambleValue: "333,222", BaseRouteType: event.RouteType1rounds}
+	relay.ID = "relay"
+	rows = sheetRows(relay, []string{"R", "U"})
+	if rows[0].label != "1-1" || rows[1].ev != "222" || rows[1].extra {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+
+	if sheetGroupName(0) != "A" || sheetGroupName(27) != "AB" {
+		t.Fatalf("group name error")
+	}
+}
+
+func Test_scramble_ScrambleSheets(t *testing.T) {
+	s := &scramble{}
+	events := []competition.CompetitionEvent{
+		{
+			EventID: "333", EventName: "三阶", EventRoute: event.RouteType5RoundsAvgHT,
+			Schedule: []competition.Schedule{{
+				Round: "初赛",
+				Scrambles: [][]string{
+					{"R U F", "R2 D' B", "L U2 F'", "B D R'", "U F2 L", "R U R' F", "D2 B L"},
+					{"R U F", "R2 D' B", "L U2 F'", "B D R'", "U F2 L", "R U R' F", "D2 B L"},
+				},
+			}},
+		},
+		{
+			EventID: "minx", EventName: "五魔方", EventRoute: event.RouteType5RoundsAvgHT,
+			Schedule: []competition.Schedule{
+				{Round: "初赛", Scrambles: [][]string{{"R++ D-- R++ D++ U\nR-- D++ R-- D-- U'"}}},
+				{Round: "决赛", NotScramble: true},
+			},
+		},
+	}
+
+	for _, password := range []string{"", "cubing"} {
+		data, err := s.ScrambleSheets("测试比赛", events, SheetOptions{Password: password})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(r.File) != 2 || r.File[0].Name != "测试比赛 - 三阶 - 初赛.pdf" {
+			t.Fatalf("unexpected files %v", r.File)
+		}
+		// 中文文件名需要 UTF-8 标记, 否则其他解压软件会乱码
+		for _, f := range r.File {
+			if f.Flags&0x800 == 0 {
+				t.Fatalf("%s missing utf-8 flag, flags %#x", f.Name, f.Flags)
+			}
+		}
+
+		var pdf []byte
+		if password == "" {
+			pdf = readZip(t, r.File[0])
+		} else {
+			if r.File[0].Flags&0x1 == 0 {
+				t.Fatalf("file should be encrypted")
+			}
+			pdf = readEncryptedZip(t, r.File[0], password)
+		}
+		if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || strings.Count(string(pdf), "/Type /Page ") != 2 {
+			t.Fatalf("unexpected pdf")
+		}
+	}
+
+	if _, err := s.ScrambleSheets("测试比赛", nil, SheetOptions{}); err == nil {
+		t.Fatalf("want error without scrambles")
+	}
+}
+
+func readZip(t *testing.T, f *zip.File) []byte {
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func (k *zipCryptoKeys) decrypt(c byte) byte {
+	b := c ^ k.stream()
+	k.update(b)
+	return b
+}
+
+func readEncryptedZip(t *testing.T, f *zip.File, password string) []byte {
+	rc, err := f.OpenRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(rc)
+
+	k := newZipCryptoKeys(password)
+	plain := make([]byte, len(raw))
+	for i, c := range raw {
+		plain[i] = k.decrypt(c)
+	}
+	if plain[11] != byte(f.CRC32>>24) {
+		t.Fatalf("password check failed")
+	}
+	data, err := io.ReadAll(flate.NewReader(bytes.NewReader(plain[12:])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -38,3 +38,24 @@ func Test_scramble_Scramble(t *testing.T) {
 		}
 	})
 }
+
+func Test_checkScramble(t *testing.T) {
+	tests := []struct {
+		ev       string
+		scramble string
+		wantErr  bool
+	}{
+		{ev: "333", scramble: "R U R' U' F2"},
+		{ev: "333", scramble: "R U R' Ux F2", wantErr: true},
+		{ev: "333", scramble: "R U U' R'", wantErr: true},
+		{ev: "minx", scramble: "R++ D-- R++ D++ U\nR-- D++ R-- D-- U'"},
+		{ev: "sq1", scramble: "(1,0) / (1,0) /", wantErr: true},
+		{ev: "333mbf", scramble: "R U F"},
+		{ev: "xx", scramble: "anything"}, // 没有模型的项目不检查
+	}
+	for _, tt := range tests {
+		if err := checkScramble(tt.ev, tt.scramble); (err != nil) != tt.wantErr {
+			t.Errorf("checkScramble(%s, %s) error = %v, wantErr %v", tt.ev, tt.scramble, err, tt.wantErr)
+		}
+	}
+}
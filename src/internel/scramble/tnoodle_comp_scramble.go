@@ -0,0 +1,258 @@
+package scramble
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+	"github.com/guojia99/cubing-pro/src/internel/utils"
+)
+
+const (
+	tNoodleWcIfFormatVersion      = "1.0"
+	tNoodleExtensionSheetCopy     = "org.worldcubeassociation.tnoodle.SheetCopyCountExtension"
+	tNoodleExtensionMultiScramble = "org.worldcubeassociation.tnoodle.MultiScrambleCountExtension"
+	tNoodleExtensionSpecUrl       = "https://github.com/thewca/tnoodle/blob/master/tnoodle-ui/src/main/wcif/extensions.md"
+
+	tNoodleMBLDScrambleNum = 35 // 多盲每次尝试的打乱数
+	tNoodleZipTimeout      = 300
+)
+
+func (s *scramble) TNoodleScrambles(cj competition.CompetitionJson) (competition.CompetitionJson, error) {
+	var events = make(map[string]event.Event)
+	for _, ev := range cj.Events {
+		var eve event.Event
+		if err := s.db.Where("id = ?", ev.EventID).First(&eve).Error; err != nil {
+			continue
+		}
+		events[ev.EventID] = eve
+	}
+	return s.tNoodleCompScrambles(cj, events)
+}
+
+// tNoodleCompScrambles 一次性向TNoodle请求整场比赛的打乱, 非WCA项目仍使用CubingPro打乱
+func (s *scramble) tNoodleCompScrambles(cj competition.CompetitionJson, events map[string]event.Event) (competition.CompetitionJson, error) {
+	id := "CubingPro" + utils.RandomString(8)
+	wcif, rounds := buildTNoodleWcIf(id, cj, events)
+
+	if len(wcif.Events) > 0 {
+		data, err := s.tNoodleZip(wcif)
+		if err != nil {
+			return cj, err
+		}
+		out, pdf, err := readTNoodleZip(data)
+		if err != nil {
+			return cj, err
+		}
+
+		dir := filepath.Join(s.tNoodleFilePath, id)
+		if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+			return cj, err
+		}
+		zipPath, pdfPath := filepath.Join(dir, id+".zip"), filepath.Join(dir, id+".pdf")
+		if err = os.WriteFile(zipPath, data, 0644); err != nil {
+			return cj, err
+		}
+		if err = os.WriteFile(pdfPath, pdf, 0644); err != nil {
+			return cj, err
+		}
+		cj.TNoodlePath, cj.TNoodlePDFPath = zipPath, pdfPath
+
+		for _, ev := range out.Events {
+			for _, round := range ev.Rounds {
+				idx, ok := rounds[round.Id]
+				if !ok {
+					continue
+				}
+				scrambles := tNoodleRoundScrambles(round)
+				for _, group := range scrambles {
+					for _, sc := range group {
+						if err = checkScramble(ev.Id, sc); err != nil {
+							return cj, fmt.Errorf("%s 打乱损坏 `%s`: %w", round.Id, sc, err)
+						}
+					}
+				}
+				cj.Events[idx[0]].Schedule[idx[1]].Scrambles = scrambles
+			}
+		}
+	}
+
+	for i, ev := range cj.Events {
+		eve, ok := events[ev.EventID]
+		if !ev.IsComp || !ok || eve.IsWCA {
+			continue
+		}
+		s.cubingProEventScrambles(&cj.Events[i], eve)
+	}
+	return cj, nil
+}
+
+// buildTNoodleWcIf 生成WCIF, 返回 round id 到 (项目下标, 轮次下标) 的映射
+func buildTNoodleWcIf(id string, cj competition.CompetitionJson, events map[string]event.Event) (WcIf, map[string][2]int) {
+	wcif := WcIf{
+		FormatVersion: tNoodleWcIfFormatVersion,
+		Name:          id,
+		ShortName:     id,
+		Id:            id,
+		Events:        make([]WcIfEvent, 0),
+		Schedule:      WcIfSchedule{NumberOfDays: 1, Venues: make([]interface{}, 0)},
+		Extensions:    make([]WcIfExtension, 0),
+	}
+	var rounds = make(map[string][2]int)
+
+	for i, ev := range cj.Events {
+		eve, ok := events[ev.EventID]
+		if !ev.IsComp || !ok || !eve.IsWCA {
+			continue
+		}
+		route := ev.EventRoute
+		if route == event.RouteTypeNot {
+			route = eve.BaseRouteType
+		}
+
+		we := WcIfEvent{Id: eve.ID, Rounds: make([]WcIfRound, 0), Extensions: make([]WcIfExtension, 0)}
+		for j, sc := range ev.Schedule {
+			if sc.NotScramble {
+				continue
+			}
+			roundNum := sc.RoundNum
+			if roundNum == 0 {
+				roundNum = j + 1
+			}
+			round := WcIfRound{
+				Format:           tNoodleRoundFormat(route),
+				Id:               fmt.Sprintf("%s-r%d", eve.ID, roundNum),
+				ScrambleSetCount: max(sc.ScrambleNums, 1),
+				Extensions:       make([]WcIfExtension, 0),
+			}
+			switch {
+			case route.RouteMap().Repeatedly:
+				round.Extensions = append(round.Extensions, WcIfExtension{
+					Id:      tNoodleExtensionMultiScramble,
+					SpecUrl: tNoodleExtensionSpecUrl,
+					Data:    WcIfExtensionsData{RequestedScrambles: tNoodleMBLDScrambleNum},
+				})
+			case eve.ID == "333fm":
+				round.Extensions = append(round.Extensions, WcIfExtension{
+					Id:      tNoodleExtensionSheetCopy,
+					SpecUrl: tNoodleExtensionSpecUrl,
+					Data:    WcIfExtensionsData{NumCopies: max(sc.Competitors, 1)},
+				})
+			}
+			rounds[round.Id] = [2]int{i, j}
+			we.Rounds = append(we.Rounds, round)
+		}
+		if len(we.Rounds) > 0 {
+			wcif.Events = append(wcif.Events, we)
+		}
+	}
+	return wcif, rounds
+}
+
+// tNoodleRoundFormat WCIF的赛制, a: 五次平均, m: 三次平均, 数字为取最佳的次数
+func tNoodleRoundFormat(route event.RouteType) string {
+	rm := route.RouteMap()
+	switch {
+	case rm.Repeatedly:
+		return strconv.Itoa(rm.RepeatedlyNum)
+	case rm.WithBest:
+		return strconv.Itoa(rm.Rounds)
+	case rm.Rounds == 5:
+		return "a"
+	case rm.Rounds == 3:
+		return "m"
+	}
+	return strconv.Itoa(max(rm.Rounds, 1))
+}
+
+// tNoodleRoundScrambles 每组打乱后接备打, 多盲每次尝试的多条打乱展开
+func tNoodleRoundScrambles(round WcIfRound) [][]string {
+	var out = make([][]string, 0, len(round.ScrambleSets))
+	for _, set := range round.ScrambleSets {
+		var group []string
+		for _, sc := range append(append([]string{}, set.Scrambles...), set.ExtraScrambles...) {
+			// 五魔的打乱本身是多行的, 只展开多盲
+			if !strings.HasPrefix(round.Id, "333mbf") {
+				group = append(group, strings.TrimSpace(sc))
+				continue
+			}
+			for _, line := range strings.Split(sc, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					group = append(group, line)
+				}
+			}
+		}
+		out = append(out, group)
+	}
+	return out
+}
+
+func (s *scramble) tNoodleZip(wcif WcIf) ([]byte, error) {
+	url := fmt.Sprintf("%s/wcif/zip", s.tNoodleEndpoint)
+	resp, err := utils.HTTPRequestFullWithTimeout(http.MethodPost, url, nil,
+		map[string]interface{}{"Content-Type": "application/json"},
+		tNoodleGenerateScramblesRequest{WcIf: wcif}, tNoodleZipTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tnoodle status code %d: %s", resp.StatusCode, string(resp.Body))
+	}
+	return resp.Body, nil
+}
+
+// readTNoodleZip 读取TNoodle压缩包中 Interchange 目录下带打乱的WCIF与汇总的pdf
+func readTNoodleZip(data []byte) (out WcIf, pdf []byte, err error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return out, nil, err
+	}
+
+	var wcifFile, pdfFile *zip.File
+	for _, f := range reader.File {
+		name := f.Name
+		switch {
+		case strings.HasPrefix(name, "Interchange/") && strings.HasSuffix(name, ".json") && !strings.Contains(strings.TrimPrefix(name, "Interchange/"), "/"):
+			wcifFile = f
+		case strings.HasSuffix(name, "All Scrambles.pdf"):
+			pdfFile = f
+		case strings.HasSuffix(name, ".pdf") && pdfFile == nil:
+			pdfFile = f
+		}
+	}
+	if wcifFile == nil {
+		return out, nil, errors.New("tnoodle zip 中没有打乱数据")
+	}
+	if pdfFile == nil {
+		return out, nil, errors.New("tnoodle zip 中没有打乱pdf")
+	}
+
+	body, err := readZipFile(wcifFile)
+	if err != nil {
+		return out, nil, err
+	}
+	if err = json.Unmarshal(body, &out); err != nil {
+		return out, nil, err
+	}
+	pdf, err = readZipFile(pdfFile)
+	return out, pdf, err
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
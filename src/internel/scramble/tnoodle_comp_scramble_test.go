@@ -0,0 +1,181 @@
+package scramble
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	basemodel "github.com/guojia99/cubing-pro/src/internel/database/model/base"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/competition"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
+)
+
+// newTNoodleStub 模拟TNoodle的 /wcif/zip, 按请求的轮次生成打乱并打包
+// stubScramble 可以执行的打乱, 用 L B D 的次数区分组号、序号与多盲的第几条
+func stubScramble(k, n, c int) string {
+	return strings.TrimSpace("R U F " + strings.Repeat("L ", k) + strings.Repeat("B ", n) + strings.Repeat("D ", c))
+}
+
+func newTNoodleStub(t *testing.T, got *tNoodleGenerateScramblesRequest) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wcif/zip" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		wcif := got.WcIf
+		for i, ev := range wcif.Events {
+			for j, round := range ev.Rounds {
+				num, extra := 5, 2
+				if round.Format == "m" {
+					num, extra = 3, 1
+				}
+				cubes := 1
+				for _, ext := range round.Extensions {
+					if ext.Id == tNoodleExtensionMultiScramble {
+						num, extra, cubes = 1, 0, ext.Data.RequestedScrambles
+					}
+				}
+				for k := 0; k < round.ScrambleSetCount; k++ {
+					set := WcIfScrambleSet{Id: k}
+					for n := 0; n < num; n++ {
+						var lines []string
+						for c := 0; c < cubes; c++ {
+							lines = append(lines, stubScramble(k, n, c))
+						}
+						set.Scrambles = append(set.Scrambles, strings.Join(lines, "\n"))
+					}
+					for n := 0; n < extra; n++ {
+						set.ExtraScrambles = append(set.ExtraScrambles, stubScramble(k, 10+n, 0))
+					}
+					wcif.Events[i].Rounds[j].ScrambleSets = append(wcif.Events[i].Rounds[j].ScrambleSets, set)
+				}
+			}
+		}
+
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		f, _ := zw.Create("Interchange/" + wcif.Name + ".json")
+		_ = json.NewEncoder(f).Encode(wcif)
+		f, _ = zw.Create("Interchange/txt/333.txt")
+		_, _ = f.Write([]byte("ignored"))
+		f, _ = zw.Create("Printing/" + wcif.Name + " - All Scrambles.pdf")
+		_, _ = f.Write([]byte("%PDF-stub"))
+		_ = zw.Close()
+		w.Header().Set("Content-Type", "application/zip")
+		_, _ = w.Write(buf.Bytes())
+	}))
+}
+
+func Test_scramble_tNoodleCompScrambles(t *testing.T) {
+	var got tNoodleGenerateScramblesRequest
+	server := newTNoodleStub(t, &got)
+	defer server.Close()
+
+	s := &scramble{tNoodleEndpoint: server.URL, tNoodleFilePath: t.TempDir()}
+	events := map[string]event.Event{
+		"333":    {StringIDModel: basemodel.StringIDModel{ID: "333"}, IsWCA: true, BaseRouteType: event.RouteType5RoundsAvgHT},
+		"333fm":  {StringIDModel: basemodel.StringIDModel{ID: "333fm"}, IsWCA: true, BaseRouteType: event.RouteType3RoundsAvgWithInteger},
+		"333mbf": {StringIDModel: basemodel.StringIDModel{ID: "333mbf"}, IsWCA: true, BaseRouteType: event.RouteTypeRepeatedly},
+		"xx":     {StringIDModel: basemodel.StringIDModel{ID: "xx"}, BaseRouteType: event.RouteType1rounds},
+	}
+	cj := competition.CompetitionJson{Events: []competition.CompetitionEvent{
+		{EventID: "333", IsComp: true, EventRoute: event.RouteType5RoundsAvgHT, Schedule: []competition.Schedule{
+			{RoundNum: 1, ScrambleNums: 2},
+			{RoundNum: 2, ScrambleNums: 1},
+			{RoundNum: 3, NotScramble: true},
+		}},
+		{EventID: "333fm", IsComp: true, Schedule: []competition.Schedule{{RoundNum: 1, ScrambleNums: 1, Competitors: 12}}},
+		{EventID: "333mbf", IsComp: true, EventRoute: event.RouteTypeRepeatedly, Schedule: []competition.Schedule{{RoundNum: 1, ScrambleNums: 1}}},
+		{EventID: "xx", IsComp: true, Schedule: []competition.Schedule{{RoundNum: 1, ScrambleNums: 1}}},
+		{EventID: "222", IsComp: false, Schedule: []competition.Schedule{{RoundNum: 1, ScrambleNums: 1}}},
+	}}
+
+	out, err := s.tNoodleCompScrambles(cj, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 请求内容
+	if len(got.WcIf.Events) != 3 {
+		t.Fatalf("wcif events = %+v", got.WcIf.Events)
+	}
+	if r := got.WcIf.Events[0].Rounds; len(r) != 2 || r[0].Id != "333-r1" || r[0].Format != "a" || r[0].ScrambleSetCount != 2 {
+		t.Errorf("333 rounds = %+v", r)
+	}
+	if r := got.WcIf.Events[1].Rounds[0]; r.Format != "m" || r.Extensions[0].Id != tNoodleExtensionSheetCopy || r.Extensions[0].Data.NumCopies != 12 {
+		t.Errorf("333fm round = %+v", r)
+	}
+	if r := got.WcIf.Events[2].Rounds[0]; r.Format != "1" || r.Extensions[0].Data.RequestedScrambles != tNoodleMBLDScrambleNum {
+		t.Errorf("333mbf round = %+v", r)
+	}
+
+	// 打乱回填
+	r1 := out.Events[0].Schedule[0].Scrambles
+	if len(r1) != 2 || len(r1[0]) != 7 || r1[1][0] != stubScramble(1, 0, 0) || r1[0][6] != stubScramble(0, 11, 0) {
+		t.Errorf("333 r1 = %v", r1)
+	}
+	if len(out.Events[0].Schedule[1].Scrambles) != 1 || out.Events[0].Schedule[2].Scrambles != nil {
+		t.Errorf("333 schedule = %+v", out.Events[0].Schedule)
+	}
+	if fm := out.Events[1].Schedule[0].Scrambles; len(fm) != 1 || len(fm[0]) != 4 {
+		t.Errorf("333fm = %v", fm)
+	}
+	if mbf := out.Events[2].Schedule[0].Scrambles; len(mbf) != 1 || len(mbf[0]) != tNoodleMBLDScrambleNum {
+		t.Errorf("333mbf = %v", mbf)
+	}
+	if out.Events[3].Schedule[0].Scrambles == nil {
+		t.Error("非WCA项目应使用CubingPro打乱")
+	}
+	if out.Events[4].Schedule[0].Scrambles != nil {
+		t.Error("非比赛项目不应生成打乱")
+	}
+
+	// 文件
+	for _, path := range []string{out.TNoodlePath, out.TNoodlePDFPath} {
+		if _, err = os.Stat(path); err != nil {
+			t.Errorf("stat %s: %v", path, err)
+		}
+	}
+	if pdf, _ := os.ReadFile(out.TNoodlePDFPath); string(pdf) != "%PDF-stub" {
+		t.Errorf("pdf = %s", pdf)
+	}
+}
+
+func Test_scramble_tNoodleCompScramblesError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	s := &scramble{tNoodleEndpoint: server.URL, tNoodleFilePath: t.TempDir()}
+	events := map[string]event.Event{"333": {StringIDModel: basemodel.StringIDModel{ID: "333"}, IsWCA: true}}
+	cj := competition.CompetitionJson{Events: []competition.CompetitionEvent{
+		{EventID: "333", IsComp: true, EventRoute: event.RouteType5RoundsAvgHT, Schedule: []competition.Schedule{{RoundNum: 1, ScrambleNums: 1}}},
+	}}
+	if _, err := s.tNoodleCompScrambles(cj, events); err == nil {
+		t.Error("TNoodle 返回错误时应返回 error")
+	}
+}
+
+func Test_tNoodleRoundScrambles(t *testing.T) {
+	minx := "R++ D-- U\nR-- D++ U'"
+	got := tNoodleRoundScrambles(WcIfRound{Id: "minx-r1", ScrambleSets: []WcIfScrambleSet{{Scrambles: []string{minx}}}})
+	if len(got[0]) != 1 || got[0][0] != minx {
+		t.Errorf("五魔打乱不应拆分, got %v", got)
+	}
+	got = tNoodleRoundScrambles(WcIfRound{Id: "333mbf-r1", ScrambleSets: []WcIfScrambleSet{{Scrambles: []string{"R U\nF D"}}}})
+	if len(got[0]) != 2 {
+		t.Errorf("多盲打乱应拆分, got %v", got)
+	}
+}
@@ -76,10 +76,10 @@ func (s *scramble) events() map[string]Event {
 
 type (
 	WcIfExtensionsData struct {
-		IsStaging          bool `json:"isStaging"`
-		IsManual           bool `json:"isManual"`
-		IsSignedBuild      bool `json:"isSignedBuild"`
-		IsAllowedVersion   bool `json:"isAllowedVersion"`
+		IsStaging          bool `json:"isStaging,omitempty"`
+		IsManual           bool `json:"isManual,omitempty"`
+		IsSignedBuild      bool `json:"isSignedBuild,omitempty"`
+		IsAllowedVersion   bool `json:"isAllowedVersion,omitempty"`
 		NumCopies          int  `json:"numCopies,omitempty"`
 		RequestedScrambles int  `json:"requestedScrambles,omitempty"`
 	}
@@ -95,18 +95,18 @@ type (
 		Venues       []interface{} `json:"venues"`
 	}
 
+	WcIfScrambleSet struct {
+		Id             int      `json:"id"`
+		Scrambles      []string `json:"scrambles"`
+		ExtraScrambles []string `json:"extraScrambles"`
+	}
+
 	WcIfRound struct {
-		Format           string `json:"format"`
-		Id               string `json:"id"`
-		ScrambleSetCount int    `json:"scrambleSetCount"`
-		Extensions       []struct {
-			Id      string `json:"id"`
-			SpecUrl string `json:"specUrl"`
-			Data    struct {
-				NumCopies          int `json:"numCopies,omitempty"`
-				RequestedScrambles int `json:"requestedScrambles,omitempty"`
-			} `json:"data"`
-		} `json:"extensions"`
+		Format           string            `json:"format"`
+		Id               string            `json:"id"`
+		ScrambleSetCount int               `json:"scrambleSetCount"`
+		ScrambleSets     []WcIfScrambleSet `json:"scrambleSets,omitempty"`
+		Extensions       []WcIfExtension   `json:"extensions"`
 	}
 	WcIfEvent struct {
 		Id         string          `json:"id"`
@@ -125,16 +125,8 @@ type (
 	}
 
 	tNoodleGenerateScramblesRequest struct {
-		WcIf WcIf `json:"wcif"`
-	}
-
-	tNoodleGenerateScramblesRequestResp struct {
-		// 原始数据
-		ZipData []byte `json:"zipData"`
-		ZipPath string `json:"zipPath"`
-
-		//
+		WcIf        WcIf   `json:"wcif"`
+		PdfPassword string `json:"pdfPassword"`
+		ZipPassword string `json:"zipPassword"`
 	}
 )
-
-func (s *scramble) GenerateScrambles() {}
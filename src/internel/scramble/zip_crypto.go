@@ -0,0 +1,116 @@
+package scramble
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"hash/crc32"
+	"time"
+)
+
+// ZipFile 打包进压缩包的文件
+type ZipFile struct {
+	Name string
+	Data []byte
+}
+
+// packZip 打包文件, 密码不为空时使用与 TNoodle 相同的传统 PKWARE 加密, 常见解压软件都可以打开
+func packZip(files []ZipFile, password string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range files {
+		if password == "" {
+			fw, err := w.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: time.Now()})
+			if err != nil {
+				return nil, err
+			}
+			if _, err = fw.Write(f.Data); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var compressed bytes.Buffer
+		fl, _ := flate.NewWriter(&compressed, flate.BestCompression)
+		if _, err := fl.Write(f.Data); err != nil {
+			return nil, err
+		}
+		if err := fl.Close(); err != nil {
+			return nil, err
+		}
+
+		crc := crc32.ChecksumIEEE(f.Data)
+		data, err := zipCryptoEncrypt(password, crc, compressed.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		fh := &zip.FileHeader{
+			Name:               f.Name,
+			Method:             zip.Deflate,
+			Modified:           time.Now(),
+			Flags:              0x1 | 0x800, // 加密, 文件名为 UTF-8
+			CRC32:              crc,
+			CompressedSize64:   uint64(len(data)),
+			UncompressedSize64: uint64(len(f.Data)),
+		}
+		fw, err := w.CreateRaw(fh)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = fw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// zipCryptoKeys 传统 PKWARE 加密的三个密钥
+type zipCryptoKeys [3]uint32
+
+func newZipCryptoKeys(password string) *zipCryptoKeys {
+	k := &zipCryptoKeys{0x12345678, 0x23456789, 0x34567890}
+	for i := 0; i < len(password); i++ {
+		k.update(password[i])
+	}
+	return k
+}
+
+func (k *zipCryptoKeys) update(b byte) {
+	k[0] = crc32.IEEETable[byte(k[0])^b] ^ (k[0] >> 8)
+	k[1] = (k[1]+k[0]&0xff)*134775813 + 1
+	k[2] = crc32.IEEETable[byte(k[2])^byte(k[1]>>24)] ^ (k[2] >> 8)
+}
+
+func (k *zipCryptoKeys) stream() byte {
+	t := uint16(k[2] | 2)
+	return byte((t * (t ^ 1)) >> 8)
+}
+
+func (k *zipCryptoKeys) encrypt(b byte) byte {
+	c := b ^ k.stream()
+	k.update(b)
+	return c
+}
+
+// zipCryptoEncrypt 12字节的加密头 + 加密后的数据, 加密头最后一字节为 crc 的高8位, 用于解压时校验密码
+func zipCryptoEncrypt(password string, crc uint32, data []byte) ([]byte, error) {
+	header := make([]byte, 12)
+	if _, err := rand.Read(header[:11]); err != nil {
+		return nil, err
+	}
+	header[11] = byte(crc >> 24)
+
+	k := newZipCryptoKeys(password)
+	out := make([]byte, 0, len(header)+len(data))
+	for _, b := range header {
+		out = append(out, k.encrypt(b))
+	}
+	for _, b := range data {
+		out = append(out, k.encrypt(b))
+	}
+	return out, nil
+}
@@ -48,12 +48,7 @@ func NewAPISvc(file string, job bool, syncWca bool, scr bool) (*Svc, error) {
 		return nil, err
 	}
 	if scr {
-		c.Scramble = scramble.NewScramble(c.DB,
-			cfg.GlobalConfig.Scramble.Type,
-			cfg.GlobalConfig.Scramble.EndPoint,
-			cfg.GlobalConfig.Scramble.ScrambleDrawType,
-			cfg.GlobalConfig.Scramble.ScrambleUrl,
-		)
+		c.Scramble = scramble.NewScramble(c.DB, cfg.GlobalConfig.Scramble)
 	}
 
 	if cfg.GlobalConfig.AlgTrainersPath != "" {
@@ -62,12 +57,7 @@ func NewAPISvc(file string, job bool, syncWca bool, scr bool) (*Svc, error) {
 		}
 	}
 
-	c.Wca = wca.NewWCA(
-		c.Cfg.GlobalConfig.WcaDB.MysqlUrl,
-		c.Cfg.GlobalConfig.WcaDB.DbPath,
-		c.Cfg.GlobalConfig.WcaDB.SyncPath,
-		syncWca,
-	)
+	c.Wca = wca.NewWCAWithConfig(c.Cfg.GlobalConfig.WcaDB, syncWca)
 
 	// todo 多个程序时
 	c.Cov = convenient.NewConvenient(c.DB, job, cfg)
@@ -75,6 +65,19 @@ func NewAPISvc(file string, job bool, syncWca bool, scr bool) (*Svc, error) {
 	return c, nil
 }
 
+// NewWcaSvc 只加载配置与本地 WCA 数据库，供离线的 WCA 命令使用
+func NewWcaSvc(file string) (*Svc, error) {
+	var cfg configs.Config
+	if err := cfg.Load(file); err != nil {
+		return nil, err
+	}
+	return &Svc{
+		Cfg:   cfg,
+		Cache: cache.New(time.Minute*5, time.Minute*5),
+		Wca:   wca.NewWCAWithConfig(cfg.GlobalConfig.WcaDB, false),
+	}, nil
+}
+
 func newDB(cfg configs.GlobalConfig) (*gorm.DB, error) {
 	var err error
 	var db *gorm.DB
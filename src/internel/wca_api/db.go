@@ -1,47 +1,14 @@
 package wca_api
 
 import (
-	"strings"
-	"time"
-
 	wca_model "github.com/guojia99/cubing-pro/src/internel/database/model/wca"
+	"github.com/guojia99/cubing-pro/src/wca"
 	"gorm.io/gorm"
 )
 
 const dbVersion = "20251121-1551"
 
-func GetWcaResultWithDbAndAPI(db *gorm.DB, wcaId string) (*wca_model.PersonBestResults, error) {
-	wcaId = strings.ToUpper(wcaId)
-	if db == nil {
-		return GetWCAPersonResult(wcaId)
-	}
-
-	// 从db中查询
-	var dbResult wca_model.WCAResult
-	if err := db.Where("wca_id = ?", wcaId).First(&dbResult).Error; err == nil {
-		if time.Since(dbResult.UpdatedAt) <= time.Hour && dbResult.PersonBestResults.DBVersion == dbVersion {
-			return &dbResult.PersonBestResults, nil
-		}
-	}
-
-	// api真实查询
-	defer time.Sleep(time.Second)
-	res, err := GetWCAPersonResult(wcaId)
-	if err != nil {
-		return nil, err
-	}
-	res.DBVersion = dbVersion
-
-	// 缓存到数据库
-	if dbResult.ID != 0 {
-		dbResult.PersonBestResults = *res
-		_ = db.Save(&dbResult)
-		return res, nil
-	}
-	dbResult = wca_model.WCAResult{
-		WcaID:             strings.ToUpper(wcaId),
-		PersonBestResults: *res,
-	}
-	_ = db.Create(&dbResult)
-	return res, nil
+// GetWcaResultWithDbAndAPI 选手查询，优先使用本地 WCA 数据库，w 为空时只使用官网，数据缓存在 wca_results
+func GetWcaResultWithDbAndAPI(db *gorm.DB, w wca.WCA, wcaId string) (*wca_model.PersonBestResults, error) {
+	return NewProfileResolver(db, w).Resolve(wcaId)
 }
@@ -0,0 +1,178 @@
+package wca_api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	wca_model "github.com/guojia99/cubing-pro/src/internel/database/model/wca"
+	"github.com/guojia99/cubing-pro/src/wca"
+	"github.com/guojia99/cubing-pro/src/wca/types"
+	"gorm.io/gorm"
+)
+
+// personAPITTL 快照中还没有的选手，官网数据的有效期
+const personAPITTL = time.Hour * 12
+
+// 官网请求全进程串行，两次请求至少间隔 apiMinInterval
+const apiMinInterval = time.Second
+
+var (
+	apiMu        sync.Mutex
+	lastAPIFetch time.Time
+)
+
+func throttledFetch(fetch func(wcaID string) (*wca_model.PersonBestResults, error), wcaID string) (*wca_model.PersonBestResults, error) {
+	apiMu.Lock()
+	defer apiMu.Unlock()
+	if elapsed := time.Since(lastAPIFetch); elapsed < apiMinInterval {
+		time.Sleep(apiMinInterval - elapsed)
+	}
+	defer func() { lastAPIFetch = time.Now() }()
+	return fetch(wcaID)
+}
+
+// ProfileResolver 选手 PB 查询：优先使用本地 WCA 数据库快照，只有比快照更新的选手才请求 WCA 官网，
+// Wca 为空时（未使用本地数据库）全部来自官网。
+// 每位选手的数据来源与快照记录在 wca_results 中，同一快照内不重复计算，官网数据按 personAPITTL 过期
+type ProfileResolver struct {
+	DB  *gorm.DB
+	Wca wca.WCA
+
+	fetch func(wcaID string) (*wca_model.PersonBestResults, error) // 默认 GetWCAPersonResult
+}
+
+func NewProfileResolver(db *gorm.DB, w wca.WCA) *ProfileResolver {
+	return &ProfileResolver{DB: db, Wca: w, fetch: GetWCAPersonResult}
+}
+
+func (r *ProfileResolver) snapshot() (name string, date time.Time) {
+	if r.Wca == nil {
+		return "", time.Time{}
+	}
+	status := r.Wca.Status()
+	date, _ = time.Parse(time.DateOnly, status.SnapshotDate)
+	return status.CurrentDB, date
+}
+
+// newerThanSnapshot WCA ID 以首次参赛年份开头，早于快照年份的选手不可能是快照之后才出现的；
+// 快照未就绪时无法判断，不请求官网
+func newerThanSnapshot(wcaID string, date time.Time) bool {
+	if date.IsZero() {
+		return false
+	}
+	if len(wcaID) < 4 {
+		return false
+	}
+	year, err := strconv.Atoi(wcaID[:4])
+	return err == nil && year >= date.Year()
+}
+
+func (r *ProfileResolver) Resolve(wcaID string) (*wca_model.PersonBestResults, error) {
+	wcaID = strings.ToUpper(strings.TrimSpace(wcaID))
+
+	var cached wca_model.WCAResult
+	if r.DB != nil {
+		r.DB.Where("wca_id = ?", wcaID).First(&cached)
+	}
+	snapshot, snapshotDate := r.snapshot()
+
+	// 1. 本地快照
+	if snapshot != "" {
+		if cached.Source == wca_model.WCAResultSourceDump && cached.Snapshot == snapshot {
+			return &cached.PersonBestResults, nil
+		}
+		if info, err := r.Wca.GetPersonInfo(wcaID); err == nil {
+			res := PersonInfoToBestResults(info)
+			res.DBVersion = snapshot
+			cached.Source, cached.Snapshot = wca_model.WCAResultSourceDump, snapshot
+			r.save(&cached, wcaID, res)
+			return &res, nil
+		}
+	}
+
+	// 2. 快照之后才出现的选手
+	if cached.Source == wca_model.WCAResultSourceAPI && time.Since(cached.FetchedAt) <= personAPITTL {
+		return &cached.PersonBestResults, nil
+	}
+	if r.Wca != nil && !newerThanSnapshot(wcaID, snapshotDate) {
+		return nil, fmt.Errorf("not found wca id %s", wcaID)
+	}
+
+	res, err := throttledFetch(r.fetch, wcaID)
+	if err != nil {
+		// 官网请求失败时继续使用过期数据
+		if cached.Source == wca_model.WCAResultSourceAPI {
+			return &cached.PersonBestResults, nil
+		}
+		return nil, err
+	}
+	res.DBVersion = dbVersion
+	cached.Source, cached.Snapshot, cached.FetchedAt = wca_model.WCAResultSourceAPI, snapshot, time.Now()
+	r.save(&cached, wcaID, *res)
+	return res, nil
+}
+
+func (r *ProfileResolver) save(row *wca_model.WCAResult, wcaID string, res wca_model.PersonBestResults) {
+	if r.DB == nil {
+		return
+	}
+	row.WcaID = wcaID
+	row.PersonBestResults = res
+	_ = r.DB.Save(row).Error
+}
+
+// PersonInfoToBestResults 本地 WCA 数据库的选手信息转为 wca_results 缓存格式
+func PersonInfoToBestResults(in types.PersonInfo) wca_model.PersonBestResults {
+	out := wca_model.PersonBestResults{
+		PersonName:       in.PersonName,
+		WCAID:            in.WcaID,
+		Best:             make(map[string]wca_model.Results),
+		Avg:              make(map[string]wca_model.Results),
+		CompetitionCount: in.CompetitionCount,
+		MedalCount: wca_model.MedalCount{
+			Gold:   in.MedalCount.Gold,
+			Silver: in.MedalCount.Silver,
+			Bronze: in.MedalCount.Bronze,
+			Total:  in.MedalCount.Total,
+		},
+		RecordCount: wca_model.RecordCount{
+			National:    in.RecordCount.National,
+			Continental: in.RecordCount.Continental,
+			World:       in.RecordCount.World,
+			Total:       in.RecordCount.Total,
+		},
+	}
+
+	for ev, rcs := range in.PersonalRecords {
+		if rcs.Best != nil {
+			out.Best[ev] = wca_model.Results{
+				EventId:       ev,
+				Best:          rcs.Best.Best,
+				BestStr:       rcs.Best.BestStr,
+				PersonName:    rcs.Best.PersonName,
+				PersonId:      rcs.Best.PersonId,
+				WorldRank:     rcs.Best.WorldRank,
+				ContinentRank: rcs.Best.ContinentRank,
+				CountryRank:   rcs.Best.CountryRank,
+				Rank:          rcs.Best.Rank,
+			}
+		}
+		if rcs.Avg != nil {
+			out.Avg[ev] = wca_model.Results{
+				EventId:       ev,
+				Average:       rcs.Avg.Best,
+				AverageStr:    rcs.Avg.BestStr,
+				PersonName:    rcs.Avg.PersonName,
+				PersonId:      rcs.Avg.PersonId,
+				WorldRank:     rcs.Avg.WorldRank,
+				ContinentRank: rcs.Avg.ContinentRank,
+				CountryRank:   rcs.Avg.CountryRank,
+				Rank:          rcs.Avg.Rank,
+			}
+		}
+	}
+	return out
+}
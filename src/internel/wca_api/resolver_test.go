@@ -0,0 +1,136 @@
+package wca_api
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	wca_model "github.com/guojia99/cubing-pro/src/internel/database/model/wca"
+	"github.com/guojia99/cubing-pro/src/wca"
+	"github.com/guojia99/cubing-pro/src/wca/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeWCA 只实现 Resolve 用到的方法
+type fakeWCA struct {
+	wca.WCA
+	snapshot string
+	date     string
+	persons  map[string]types.PersonInfo
+	calls    int
+}
+
+func (f *fakeWCA) Status() types.WcaDBStatus {
+	return types.WcaDBStatus{CurrentDB: f.snapshot, SnapshotDate: f.date}
+}
+
+func (f *fakeWCA) GetPersonInfo(wcaId string) (types.PersonInfo, error) {
+	f.calls++
+	if p, ok := f.persons[wcaId]; ok {
+		return p, nil
+	}
+	return types.PersonInfo{}, fmt.Errorf("not found wca id %s", wcaId)
+}
+
+func TestProfileResolver_Resolve(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&wca_model.WCAResult{}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &fakeWCA{
+		snapshot: "wca_20250601",
+		date:     "2025-06-01",
+		persons: map[string]types.PersonInfo{
+			"2018GUOZ01": {
+				PersonName: "Zhi Guo",
+				WcaID:      "2018GUOZ01",
+				PersonalRecords: map[string]types.PersonalRecord{
+					"333": {
+						Best: &types.PersonResult{EventId: "333", Best: 580, BestStr: "5.80", PersonId: "2018GUOZ01", WorldRank: 4},
+						Avg:  &types.PersonResult{EventId: "333", Best: 650, BestStr: "6.50", PersonId: "2018GUOZ01", WorldRank: 3},
+					},
+				},
+			},
+		},
+	}
+	apiCalls := 0
+	r := NewProfileResolver(db, w)
+	r.fetch = func(wcaID string) (*wca_model.PersonBestResults, error) {
+		apiCalls++
+		return &wca_model.PersonBestResults{PersonName: "New Person", WCAID: wcaID}, nil
+	}
+
+	// 快照中的选手只计算一次
+	for i := 0; i < 2; i++ {
+		res, err := r.Resolve("2018guoz01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Avg["333"].AverageStr != "6.50" || res.Avg["333"].WorldRank != 3 || res.DBVersion != "wca_20250601" {
+			t.Fatalf("unexpected dump result %+v", res)
+		}
+	}
+	if w.calls != 1 || apiCalls != 0 {
+		t.Fatalf("want 1 local query and no api call, got %d / %d", w.calls, apiCalls)
+	}
+
+	// 快照之后的新选手请求官网，有效期内不再请求
+	for i := 0; i < 2; i++ {
+		if res, err := r.Resolve("2025NEWP01"); err != nil || res.PersonName != "New Person" {
+			t.Fatalf("unexpected api result %+v %v", res, err)
+		}
+	}
+	if apiCalls != 1 {
+		t.Fatalf("want 1 api call, got %d", apiCalls)
+	}
+	var row wca_model.WCAResult
+	db.Where("wca_id = ?", "2025NEWP01").First(&row)
+	if row.Source != wca_model.WCAResultSourceAPI || time.Since(row.FetchedAt) > time.Minute {
+		t.Fatalf("unexpected freshness %+v", row)
+	}
+
+	// 早于快照年份又不在快照中的 ID 不请求官网
+	if _, err = r.Resolve("2010NONE01"); err == nil || apiCalls != 1 {
+		t.Fatalf("want not found without api call, got %v / %d", err, apiCalls)
+	}
+
+	// 新快照包含该选手后改用本地数据
+	w.snapshot = "wca_20250701"
+	w.persons["2025NEWP01"] = types.PersonInfo{PersonName: "New Person", WcaID: "2025NEWP01"}
+	if res, _ := r.Resolve("2025NEWP01"); res == nil || res.DBVersion != "wca_20250701" || apiCalls != 1 {
+		t.Fatalf("want dump result after new snapshot, got %+v", res)
+	}
+}
+
+func TestProfileResolver_ResolveWithoutSnapshot(t *testing.T) {
+	apiCalls := 0
+	fetch := func(wcaID string) (*wca_model.PersonBestResults, error) {
+		apiCalls++
+		return &wca_model.PersonBestResults{WCAID: wcaID}, nil
+	}
+
+	// 本地数据库未就绪时不回退到官网
+	r := NewProfileResolver(nil, &fakeWCA{})
+	r.fetch = fetch
+	if _, err := r.Resolve("2025NEWP01"); err == nil || apiCalls != 0 {
+		t.Fatalf("want not found without api call, got %v / %d", err, apiCalls)
+	}
+
+	// 未使用本地数据库时全部来自官网，请求间隔不少于 apiMinInterval
+	r = NewProfileResolver(nil, nil)
+	r.fetch = fetch
+	start := time.Now()
+	for _, id := range []string{"2010NONE01", "2025NEWP01"} {
+		if res, err := r.Resolve(id); err != nil || res.WCAID != id {
+			t.Fatalf("unexpected api result %+v %v", res, err)
+		}
+	}
+	if apiCalls != 2 || time.Since(start) < apiMinInterval {
+		t.Fatalf("want 2 throttled api calls, got %d in %v", apiCalls, time.Since(start))
+	}
+}
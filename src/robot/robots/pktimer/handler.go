@@ -2,8 +2,6 @@ package pktimer
 
 import (
 	"fmt"
-	"os"
-	"path"
 	"sort"
 	"strconv"
 	"strings"
@@ -321,14 +319,11 @@ func (p *PkTimer) sendScrambleMessage(msg types.InMessage) {
 
 	scrMsg := fmt.Sprintf("本次pk第%d / %d把:\n %s", pkTimerResult.PkResults.CurCount, pkTimerResult.PkResults.Count, sc[0])
 
-	img, err := p.Svc.Scramble.Image(sc[0], pkTimerResult.PkResults.Event.ID)
+	filePath, err := p.Svc.Scramble.ImageFile(sc[0], pkTimerResult.PkResults.Event.ID)
 	if err != nil {
 		p.sendMessage(msg.NewOutMessage(scrMsg))
 		return
 	}
-
-	filePath := path.Join(os.TempDir(), fmt.Sprintf("%d.jpg", time.Now().UnixNano()))
-	_ = os.WriteFile(filePath, []byte(img), 0644)
 	p.sendMessage(msg.NewOutMessageWithImage(scrMsg, filePath))
 }
 
@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	_interface "github.com/guojia99/cubing-pro/src/internel/convenient/interface"
+	"github.com/guojia99/cubing-pro/src/internel/database/model/user"
+	"github.com/guojia99/cubing-pro/src/internel/svc"
+	"github.com/guojia99/cubing-pro/src/internel/utils"
+	"github.com/guojia99/cubing-pro/src/robot/types"
+)
+
+type HeadToHeadPlugin struct {
+	Svc *svc.Svc
+}
+
+var _ types.Plugin = &HeadToHeadPlugin{}
+
+func (c *HeadToHeadPlugin) ID() []string {
+	return []string{"h2h", "对比", "对战"}
+}
+
+func (c *HeadToHeadPlugin) Help() string {
+	return `选手对比:
+1. 对比-{名称/CubeID}: 自己与该选手对比
+2. 对比-{名称/CubeID}-{名称/CubeID}: 两位选手对比
+* 对比各项目最佳成绩及同场同轮次的胜负
+`
+}
+
+func (c *HeadToHeadPlugin) getUser(message types.InMessage, key string) (user.User, error) {
+	var usr user.User
+	var err error
+	if key == "" {
+		if message.QQ != 0 {
+			err = c.Svc.DB.Where("qq = ?", fmt.Sprintf("%d", message.QQ)).First(&usr).Error
+		} else if message.QQBot != "" {
+			err = c.Svc.DB.Where("qq_uni_id = ?", message.QQBot).First(&usr).Error
+		}
+		if err != nil || usr.ID == 0 {
+			return usr, fmt.Errorf("你未绑定选手")
+		}
+		return usr, nil
+	}
+	err = c.Svc.DB.Where("name = ?", key).Or("en_name = ?", key).Or("cube_id = ?", strings.ToUpper(key)).First(&usr).Error
+	if err != nil {
+		return usr, fmt.Errorf("查询不到选手 `%s`", key)
+	}
+	return usr, nil
+}
+
+func (c *HeadToHeadPlugin) Do(message types.InMessage) (*types.OutMessage, error) {
+	msg := types.RemoveID(message.Message, c.ID())
+	keys := utils.Split(utils.ReplaceAll(msg, " ", "-"), " ")
+
+	var a, b string
+	switch len(keys) {
+	case 1:
+		b = keys[0]
+	case 2:
+		a, b = keys[0], keys[1]
+	default:
+		return message.NewOutMessage(c.Help()), nil
+	}
+
+	ua, err := c.getUser(message, a)
+	if err != nil {
+		return message.NewOutMessage(err.Error()), nil
+	}
+	ub, err := c.getUser(message, b)
+	if err != nil {
+		return message.NewOutMessage(err.Error()), nil
+	}
+
+	h2h, err := c.Svc.Cov.SelectHeadToHead(ua.CubeID, ub.CubeID, nil)
+	if err != nil {
+		return message.NewOutMessage(err.Error()), nil
+	}
+	return message.NewOutMessage(headToHeadMessage(h2h)), nil
+}
+
+func headToHeadMessage(h2h _interface.HeadToHead) string {
+	mark := func(winner string, side string) string {
+		if winner == side {
+			return "*"
+		}
+		return ""
+	}
+	orNo := func(in string) string {
+		if in == "" {
+			return "-"
+		}
+		return in
+	}
+
+	out := fmt.Sprintf("===== %s VS %s =====\n", h2h.A.PlayerName, h2h.B.PlayerName)
+	for _, ev := range h2h.Events {
+		out += fmt.Sprintf("%s: %s%s vs %s%s", ev.EventName,
+			orNo(ev.ASingle), mark(ev.SingleWinner, _interface.HeadToHeadWinnerA),
+			orNo(ev.BSingle), mark(ev.SingleWinner, _interface.HeadToHeadWinnerB))
+		if ev.AAvg != "" || ev.BAvg != "" {
+			out += fmt.Sprintf(" | %s%s vs %s%s",
+				orNo(ev.AAvg), mark(ev.AvgWinner, _interface.HeadToHeadWinnerA),
+				orNo(ev.BAvg), mark(ev.AvgWinner, _interface.HeadToHeadWinnerB))
+		}
+		if ev.ARoundWins+ev.BRoundWins > 0 {
+			out += fmt.Sprintf(" (同轮 %d:%d)", ev.ARoundWins, ev.BRoundWins)
+		}
+		out += "\n"
+	}
+	out += "========================\n"
+	out += fmt.Sprintf("最佳成绩 %d:%d\n", h2h.APBWins, h2h.BPBWins)
+	out += fmt.Sprintf("同轮交手%d次 %d:%d (平%d)\n", len(h2h.Rounds), h2h.ARoundWins, h2h.BRoundWins, h2h.RoundDraws)
+	out += fmt.Sprintf("总分 %d:%d", h2h.AScore, h2h.BScore)
+	switch h2h.WinnerTotal {
+	case _interface.HeadToHeadWinnerA:
+		out += fmt.Sprintf(" %s胜", h2h.A.PlayerName)
+	case _interface.HeadToHeadWinnerB:
+		out += fmt.Sprintf(" %s胜", h2h.B.PlayerName)
+	default:
+		out += " 平局"
+	}
+	return out + "\n"
+}
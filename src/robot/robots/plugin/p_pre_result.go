@@ -260,5 +260,16 @@ func (c *PreResultPlugin) setResults(msg string, message types.InMessage, usr us
 	out += "录入成功!\n"
 	c.Svc.DB.Save(&pres)
 
+	// 按比赛规则自动审核
+	var autoNum int
+	for i := range pres {
+		if ok, _ := c.Svc.Cov.AutoApprovalPreResult(comp, &pres[i]); ok {
+			autoNum++
+		}
+	}
+	if autoNum > 0 {
+		out += fmt.Sprintf("(已自动审核通过%d条成绩)\n", autoNum)
+	}
+
 	return message.NewOutMessage(out), nil
 }
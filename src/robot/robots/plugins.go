@@ -17,12 +17,13 @@ func NewPlugins(svc *svc.Svc) []types.Plugin {
 		&plugin.RankPlugin{Svc: svc},
 		&plugin.PreResultPlugin{Svc: svc},
 		&plugin.BindPlugin{Svc: svc},
+		&plugin.HeadToHeadPlugin{Svc: svc},
 		//&PersonValPlugin{Svc: svc},
 
 		&tools.TRandom{},
 		&tools.TAlgDB{Svc: svc},
 		&tools.TScramble{Svc: svc},
 
-		&tools.TWca{Cache: cache.New(cache.DefaultExpiration, cache.NoExpiration), DB: svc.DB},
+		&tools.TWca{Cache: cache.New(cache.DefaultExpiration, cache.NoExpiration), DB: svc.DB, Wca: svc.Wca},
 	}
 }
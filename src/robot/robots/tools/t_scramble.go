@@ -2,9 +2,6 @@ package tools
 
 import (
 	"fmt"
-	"os"
-	"path"
-	"time"
 
 	"github.com/guojia99/cubing-pro/src/internel/database/model/event"
 	"github.com/guojia99/cubing-pro/src/internel/svc"
@@ -87,13 +84,10 @@ func (t *TScramble) Do(message types.InMessage) (*types.OutMessage, error) {
 	}
 
 	if len(out) == 1 {
-		img, err := t.Svc.Scramble.Image(out[0], curEv.ID)
+		filePath, err := t.Svc.Scramble.ImageFile(out[0], curEv.ID)
 		if err != nil {
 			return message.NewOutMessage(out[0]), nil
 		}
-
-		filePath := path.Join(os.TempDir(), fmt.Sprintf("%d.jpg", time.Now().UnixNano()))
-		_ = os.WriteFile(filePath, []byte(img), 0644)
 		return message.NewOutMessageWithImage(out[0], filePath), nil
 	}
 
@@ -3,14 +3,19 @@ package tools
 import (
 	"fmt"
 	"log"
+	"os"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 
-	resultDB "github.com/guojia99/cubing-pro/src/internel/database/model/result"
 	wca_model "github.com/guojia99/cubing-pro/src/internel/database/model/wca"
 	"github.com/guojia99/cubing-pro/src/internel/database/model/wca/utils"
 	utils2 "github.com/guojia99/cubing-pro/src/internel/utils"
 	"github.com/guojia99/cubing-pro/src/internel/wca_api"
 	"github.com/guojia99/cubing-pro/src/robot/types"
+	"github.com/guojia99/cubing-pro/src/wca"
+	wcaTypes "github.com/guojia99/cubing-pro/src/wca/types"
 	"github.com/patrickmn/go-cache"
 	"gorm.io/gorm"
 )
@@ -18,6 +23,7 @@ import (
 type TWca struct {
 	DB    *gorm.DB
 	Cache *cache.Cache
+	Wca   wca.WCA
 }
 
 func (t *TWca) ID() []string {
@@ -27,6 +33,8 @@ func (t *TWca) ID() []string {
 	cx := []string{"超炫", "out", "cx", "CX"}
 
 	senior := []string{"s", "senior", "-senior"}
+	comp := []string{"比赛", "comp", "-comp"}
+	rank := []string{"排名", "rank", "-rank"}
 
 	var out []string
 	for _, w := range wcaL {
@@ -40,6 +48,12 @@ func (t *TWca) ID() []string {
 		for _, s := range senior {
 			out = append(out, fmt.Sprintf("%s%s", w, s))
 		}
+		for _, c := range comp {
+			out = append(out, fmt.Sprintf("%s%s", w, c))
+		}
+		for _, r := range rank {
+			out = append(out, fmt.Sprintf("%s%s", w, r))
+		}
 	}
 
 	return out
@@ -49,6 +63,8 @@ func (t *TWca) Help() string {
 	out := `1. 输入 WCA {WcaID} 可查询选手成绩
 2. 输入 WCA-PK {WCAID-1}-{WCAID-2} 可对比成绩（只有双方都有的项目), WCA-PKAll可展示全部项目
 3. 输入 WCA-超炫 {WCAID-1}-{WCAID-2} 可对比成绩后， 列出1超炫2需要进步多少
+4. 输入 WCA-比赛 {比赛ID} 可查询比赛领奖台与纪录, WCA-比赛 {比赛ID} {项目} 可查询该项目各轮次成绩
+5. 输入 WCA-排名 {WcaID} {项目} {目标排名} [单次/平均] [世界/洲/国家] 可查询达到目标排名需要的成绩, 默认国家排名平均
 `
 	return out
 }
@@ -73,6 +89,10 @@ func (t *TWca) Do(message types.InMessage) (*types.OutMessage, error) {
 		return t.handlerCxDoublePersonResult(message)
 	case "wcas", "wca-senior", "wcasenior":
 		return t.handlerSeniorPersonResult(message)
+	case "wca比赛", "wcacomp", "wca-comp":
+		return t.handlerCompetitionResults(message)
+	case "wca排名", "wcarank", "wca-rank":
+		return t.handlerRankTarget(message)
 	default:
 		return message.NewOutMessage(t.Help()), nil
 	}
@@ -100,7 +120,7 @@ func (t *TWca) getPersonResult(msg string) (*wca_model.PersonBestResults, error)
 		return nil, err
 	}
 
-	result, err := wca_api.GetWcaResultWithDbAndAPI(t.DB, personWCAID)
+	result, err := wca_api.NewProfileResolver(t.DB, t.Wca).Resolve(personWCAID)
 	if err != nil {
 		return nil, fmt.Errorf("选手%s成绩查询错误", personWCAID)
 	}
@@ -126,70 +146,16 @@ const (
 	starP2     = "★"
 )
 
-func (t *TWca) pk(p1 *wca_model.Results, p2 *wca_model.Results, best bool, full bool) (p1Count, p2Count int, msg string) {
-	if p1 == nil && p2 == nil {
-		return 0, 0, ""
-	}
-
-	// 两边都有成绩才对比
-	if !full && (p1 == nil || p2 == nil) {
-		return 0, 0, ""
-	}
-
-	if p1 != nil && p2 == nil {
-		p1ResultStr := p1.BestStr
-		if !best {
-			p1ResultStr = p1.AverageStr
-		}
-		return 1, 0, fmt.Sprintf("%s %s|| -", starP1, p1ResultStr)
-	}
-
-	if p1 == nil {
-		p2ResultStr := p2.BestStr
-		if !best {
-			p2ResultStr = p2.AverageStr
-		}
-		return 0, 1, fmt.Sprintf("%s - || %s %s", startEmpty, p2ResultStr, starP2)
-	}
-
-	p1ResultStr, p1Result, p2ResultStr, p2Result := p1.BestStr, p1.Best, p2.BestStr, p2.Best
-	if !best {
-		p1ResultStr, p1Result, p2ResultStr, p2Result = p1.AverageStr, p1.Average, p2.AverageStr, p2.Average
-	}
-
-	if p1.EventId == "333mbf" {
-		p1Solved, p1Attempted, p1Senconds, _ := utils.Get333MBFResult(p1.Best)
-		p2Solved, p2Attempted, p2Senconds, _ := utils.Get333MBFResult(p2.Best)
-
-		p1Num := p1Solved - (p1Attempted - p1Solved)
-		p2Num := p2Solved - (p2Attempted - p2Solved)
-
-		if p1Num == p2Num { // 分数相同时
-			if p1Senconds == p2Senconds { // 时间相同
-				p1Result, p2Result = 0, 0
-			} else if p1Senconds < p2Senconds {
-				p1Result, p2Result = -1, 1 // p1 更快
-			} else {
-				p1Result, p2Result = 1, -1 // p2更快
-			}
-		} else if p1Num > p2Num {
-			p1Result, p2Result = -1, 1 // p1更多
-		} else {
-			p1Result, p2Result = 1, -1 // p2更多
-		}
+// getPersonID WCA ID 直接使用，否则按名字搜索
+func (t *TWca) getPersonID(msg string) (string, error) {
+	msg = strings.TrimSpace(msg)
+	if id := strings.ToUpper(msg); len(id) == 10 {
+		return id, nil
 	}
-
-	// 对比
-	if p1Result == p2Result {
-		return 1, 1, fmt.Sprintf("%s %s || %s %s", starP1, p1ResultStr, p2ResultStr, starP2)
-	}
-	if p1Result < p2Result {
-		return 1, 0, fmt.Sprintf("%s %s || %s", starP1, p1ResultStr, p2ResultStr)
-	}
-	return 0, 1, fmt.Sprintf("%s %s || %s %s", startEmpty, p1ResultStr, p2ResultStr, starP2)
+	return t.getPersonWCAID(msg)
 }
 
-func (t *TWca) getDoublePerson(message types.InMessage) (*wca_model.PersonBestResults, *wca_model.PersonBestResults, error) {
+func (t *TWca) getDoublePerson(message types.InMessage) (wcaTypes.PersonCompare, error) {
 	msg := types.RemoveID(message.Message, t.ID())
 	slices := utils2.Split(msg, "-")
 	var person1, person2 string
@@ -202,167 +168,127 @@ func (t *TWca) getDoublePerson(message types.InMessage) (*wca_model.PersonBestRe
 		person1 = slices[0]
 		person2 = slices[1]
 	} else {
-		return nil, nil, fmt.Errorf("%+v", t.Help())
+		return wcaTypes.PersonCompare{}, fmt.Errorf("%+v", t.Help())
+	}
+	if t.Wca == nil {
+		return wcaTypes.PersonCompare{}, fmt.Errorf("WCA 数据暂不可用")
 	}
 
-	person1Result, err := t.getPersonResult(person1)
+	person1ID, err := t.getPersonID(person1)
+	if err != nil {
+		return wcaTypes.PersonCompare{}, err
+	}
+	person2ID, err := t.getPersonID(person2)
 	if err != nil {
-		return nil, nil, err
+		return wcaTypes.PersonCompare{}, err
 	}
-	person2Result, err := t.getPersonResult(person2)
+	cmp, err := t.Wca.ComparePersons(person1ID, person2ID)
 	if err != nil {
-		return nil, nil, err
+		return wcaTypes.PersonCompare{}, fmt.Errorf("选手%s或%s成绩查询错误", person1ID, person2ID)
 	}
-	return person1Result, person2Result, nil
+	return cmp, nil
 }
 
-func (t *TWca) handlerPkDoublePersonResult(message types.InMessage, full bool) (*types.OutMessage, error) {
-
-	// 对比两个人的
-	person1Count := 0
-	person2Count := 0
+// pkLine 单个项目单次或平均的对比行
+func pkLine(res *wcaTypes.PersonCompareResult) string {
+	p1, p2 := res.ValueStrs[0], res.ValueStrs[1]
+	switch {
+	case res.Values[1] == 0:
+		return fmt.Sprintf("%s %s|| -", starP1, p1)
+	case res.Values[0] == 0:
+		return fmt.Sprintf("%s - || %s %s", startEmpty, p2, starP2)
+	case res.Winner == 0:
+		return fmt.Sprintf("%s %s || %s %s", starP1, p1, p2, starP2)
+	case res.Winner == 1:
+		return fmt.Sprintf("%s %s || %s", starP1, p1, p2)
+	}
+	return fmt.Sprintf("%s %s || %s %s", startEmpty, p1, p2, starP2)
+}
 
-	person1Result, person2Result, err := t.getDoublePerson(message)
+func (t *TWca) handlerPkDoublePersonResult(message types.InMessage, full bool) (*types.OutMessage, error) {
+	cmp, err := t.getDoublePerson(message)
 	if err != nil {
 		return message.NewOutMessage(err.Error()), nil
 	}
 
 	var out string
-	out += fmt.Sprintf("%s PK %s\n", person1Result.PersonName, person2Result.PersonName)
+	out += fmt.Sprintf("%s PK %s\n", cmp.Persons[0].Name, cmp.Persons[1].Name)
 
-	for _, ev := range wca_model.WcaEventsList {
-		var p1BestResult *wca_model.Results
-		var p2BestResult *wca_model.Results
-		if v, ok := person1Result.Best[ev]; ok {
-			p1BestResult = &v
-		}
-		if v, ok := person2Result.Best[ev]; ok {
-			p2BestResult = &v
-		}
-
-		if p1BestResult == nil && p2BestResult == nil {
+	// 两边都有成绩才对比, full 时展示全部项目
+	both := func(res *wcaTypes.PersonCompareResult) bool {
+		return res != nil && (full || (res.Values[0] > 0 && res.Values[1] > 0))
+	}
+	for _, ev := range cmp.Events {
+		if !both(ev.Single) {
 			continue
 		}
-
-		p1, p2, pkMsg := t.pk(p1BestResult, p2BestResult, true, full)
-		if pkMsg == "" {
-			continue
+		out += fmt.Sprintf("%s %s\n", wca_model.WcaEventsCnMap[ev.EventID], pkLine(ev.Single))
+		if both(ev.Average) {
+			out += fmt.Sprintf("%s %s\n", strings.Repeat(" ", len(wca_model.WcaEventsCnMap[ev.EventID])/2), pkLine(ev.Average))
 		}
+	}
 
-		out += fmt.Sprintf("%s %s\n", wca_model.WcaEventsCnMap[ev], pkMsg)
-		person1Count += p1
-		person2Count += p2
-
-		var p1AvgResult *wca_model.Results
-		var p2AvgResult *wca_model.Results
-		if v, ok := person1Result.Avg[ev]; ok {
-			p1AvgResult = &v
-		}
-		if v, ok := person2Result.Avg[ev]; ok {
-			p2AvgResult = &v
-		}
-		if p1AvgResult == nil && p2AvgResult == nil {
-			continue
-		}
-		p1, p2, pkMsg = t.pk(p1AvgResult, p2AvgResult, false, full)
-		if pkMsg == "" {
-			continue
-		}
-		out += fmt.Sprintf("%s %s\n", strings.Repeat(" ", len(wca_model.WcaEventsCnMap[ev])/2), pkMsg)
-		person1Count += p1
-		person2Count += p2
+	person1Count, person2Count := cmp.SharedScore[0], cmp.SharedScore[1]
+	if full {
+		person1Count, person2Count = cmp.Score[0], cmp.Score[1]
 	}
 	out += "\n"
 	if person1Count == person2Count {
-		out += fmt.Sprintf("结果: 平手 %d:%d", person1Count, person2Count)
+		out += fmt.Sprintf("结果: 平手 %d:%d\n", person1Count, person2Count)
 	} else if person1Count > person2Count {
 		out += fmt.Sprintf("结果: (%s)%d:%d\n", starWin, person1Count, person2Count)
-		out += fmt.Sprintf("%s胜利 \n", person1Result.PersonName)
-
+		out += fmt.Sprintf("%s胜利 \n", cmp.Persons[0].Name)
 	} else {
 		out += fmt.Sprintf("结果:%d:%d(%s)\n", person1Count, person2Count, starWin)
-		out += fmt.Sprintf("%s胜利 \n", person2Result.PersonName)
-
+		out += fmt.Sprintf("%s胜利 \n", cmp.Persons[1].Name)
+	}
+	if len(cmp.Competitions) > 0 {
+		out += fmt.Sprintf("同场对决: %d 场比赛 %d:%d\n", len(cmp.Competitions), cmp.HeadToHead[0], cmp.HeadToHead[1])
 	}
 
-	return message.NewOutMessage(out), nil
-}
-
-func (t *TWca) cx(p1 *wca_model.Results, p2 *wca_model.Results, best bool) (p1Wined, p1WillWin, notWin string) {
-	if p1 == nil && p2 == nil {
-		return
+	img, err := t.Wca.GetPersonCompareImage(cmp.Persons[0].WcaID, cmp.Persons[1].WcaID)
+	if err != nil {
+		return message.NewOutMessage(out), nil
+	}
+	filePath := path.Join(os.TempDir(), fmt.Sprintf("wca_pk_%d.png", time.Now().UnixNano()))
+	if err = utils2.SaveImage(filePath, img); err != nil {
+		return message.NewOutMessage(out), nil
 	}
+	return message.NewOutMessageWithImage(out, filePath), nil
+}
 
+// cxLine 以第一位选手的视角描述与第二位选手的差距
+func cxLine(eventID string, res *wcaTypes.PersonCompareResult, best bool) (p1Wined, p1WillWin, notWin string) {
 	evs := "平均"
 	start := starP1
 	if best {
 		evs = "单次"
 		start = starP2
 	}
+	evName := wca_model.WcaEventsCnMap[eventID]
+	p1ResultStr, p2ResultStr := res.ValueStrs[0], res.ValueStrs[1]
 
-	if p1 != nil && p2 == nil {
-		p1ResultStr := p1.BestStr
-		if !best {
-			p1ResultStr = p1.AverageStr
-		}
-		return fmt.Sprintf("%s %s项目%s已经完全超炫他了! 你: %s", start, wca_model.WcaEventsCnMap[p1.EventId], evs, p1ResultStr), "", ""
-	}
-
-	if p1 == nil {
-		p2ResultStr := p2.BestStr
-		if !best {
-			p2ResultStr = p2.AverageStr
-		}
-		return "", fmt.Sprintf("%s %s项目%s被他完全超炫! 他: %s", start, wca_model.WcaEventsCnMap[p2.EventId], evs, p2ResultStr), ""
-	}
-
-	p1ResultStr, p1Result, p2ResultStr, p2Result := p1.BestStr, p1.Best, p2.BestStr, p2.Best
-	if !best {
-		p1ResultStr, p1Result, p2ResultStr, p2Result = p1.AverageStr, p1.Average, p2.AverageStr, p2.Average
-	}
-	wcaP1Result, wcaP2Result := utils.WCAResultIntToSeconds(p1Result, p1.EventId), utils.WCAResultIntToSeconds(p2Result, p2.EventId)
-
-	if p1.EventId == "333mbf" {
-		p1Solved, p1Attempted, p1Senconds, _ := utils.Get333MBFResult(p1.Best)
-		p2Solved, p2Attempted, p2Senconds, _ := utils.Get333MBFResult(p2.Best)
-
-		p1Num := p1Solved - (p1Attempted - p1Solved)
-		p2Num := p2Solved - (p2Attempted - p2Solved)
-
-		if p1Num == p2Num { // 分数相同时
-			if p1Senconds == p2Senconds { // 时间相同
-				p1Result, p2Result = 0, 0
-			} else if p1Senconds < p2Senconds {
-				p1Result, p2Result = -1, 1 // p1 更快
-			} else {
-				p1Result, p2Result = 1, -1 // p2更快
-			}
-		} else if p1Num > p2Num {
-			p1Result, p2Result = -1, 1 // p1更多
-		} else {
-			p1Result, p2Result = 1, -1 // p2更多
+	switch {
+	case res.Values[1] == 0:
+		return fmt.Sprintf("%s %s项目%s已经完全超炫他了! 你: %s", start, evName, evs, p1ResultStr), "", ""
+	case res.Values[0] == 0:
+		return "", fmt.Sprintf("%s %s项目%s被他完全超炫! 他: %s", start, evName, evs, p2ResultStr), ""
+	case res.Winner == 0:
+		return "", fmt.Sprintf("%s %s%s你们打平手了, 你们:%s, 你只需要进步0.01秒", start, evName, evs, p1ResultStr), ""
+	case res.Winner == 1:
+		if eventID == "333mbf" {
+			return fmt.Sprintf("%s %s 你已经超炫了他, 你:%s, 他:%s", start, evName, p1ResultStr, p2ResultStr), "", ""
 		}
+		return fmt.Sprintf("%s %s%s, 你:%s, 他:%s, 你超炫了他%s", start, evName, evs, p1ResultStr, p2ResultStr, res.DiffStr), "", ""
 	}
-
-	if p1Result == p2Result {
-		return "", fmt.Sprintf("%s %s%s你们打平手了, 你们:%s, 你只需要进步0.01秒", start, wca_model.WcaEventsCnMap[p1.EventId], evs, p1ResultStr), ""
+	if eventID == "333mbf" {
+		return "", fmt.Sprintf("%s %s 你被他超炫了,你:%s, 他:%s", start, evName, p1ResultStr, p2ResultStr), ""
 	}
-
-	if p1Result < p2Result {
-		if p1.EventId == "333mbf" {
-			return fmt.Sprintf("%s %s 你已经超炫了他, 你:%s, 他:%s", start, wca_model.WcaEventsCnMap[p1.EventId], p1ResultStr, p2ResultStr), "", ""
-		}
-		return fmt.Sprintf("%s %s%s, 你:%s, 他:%s, 你超炫了他%s", start, wca_model.WcaEventsCnMap[p1.EventId], evs, p1ResultStr, p2ResultStr, resultDB.TimeParserF2S(wcaP2Result-wcaP1Result)), "", ""
-	}
-
-	if p2.EventId == "333mbf" {
-		return "", fmt.Sprintf("%s %s 你被他超炫了,你:%s, 他:%s", start, wca_model.WcaEventsCnMap[p2.EventId], p1ResultStr, p2ResultStr), ""
-	}
-	return "", fmt.Sprintf("%s %s%s你被他超炫, 你:%s, 他:%s, 你需要进步:%s", start, wca_model.WcaEventsCnMap[p2.EventId], evs, p1ResultStr, p2ResultStr, resultDB.TimeParserF2S(wcaP1Result-wcaP2Result)), ""
+	return "", fmt.Sprintf("%s %s%s你被他超炫, 你:%s, 他:%s, 你需要进步:%s", start, evName, evs, p1ResultStr, p2ResultStr, res.DiffStr), ""
 }
 
 func (t *TWca) handlerCxDoublePersonResult(message types.InMessage) (*types.OutMessage, error) {
-	person1Result, person2Result, err := t.getDoublePerson(message)
+	cmp, err := t.getDoublePerson(message)
 	if err != nil {
 		return message.NewOutMessage(err.Error()), nil
 	}
@@ -371,60 +297,30 @@ func (t *TWca) handlerCxDoublePersonResult(message types.InMessage) (*types.OutM
 	var p1WillWinP2Results []string
 	var notWineds []string
 
-	for _, ev := range wca_model.WcaEventsList {
-		// 单次
-		var p1BestResult *wca_model.Results
-		var p2BestResult *wca_model.Results
-		if v, ok := person1Result.Best[ev]; ok {
-			p1BestResult = &v
-		}
-		if v, ok := person2Result.Best[ev]; ok {
-			p2BestResult = &v
-		}
-
-		if p1BestResult == nil && p2BestResult == nil {
-			continue
-		}
-		p1W, p2W, notWin := t.cx(p1BestResult, p2BestResult, true)
-		if p1W != "" {
-			p1WinP2Results = append(p1WinP2Results, p1W)
-		}
-		if p2W != "" {
-			p1WillWinP2Results = append(p1WillWinP2Results, p2W)
-		}
-		if notWin != "" {
-			notWineds = append(notWineds, notWin)
-		}
-
-		// 平均
-		var p1AvgResult *wca_model.Results
-		var p2AvgResult *wca_model.Results
-		if v, ok := person1Result.Avg[ev]; ok {
-			p1AvgResult = &v
-		}
-		if v, ok := person2Result.Avg[ev]; ok {
-			p2AvgResult = &v
-		}
-		if p1AvgResult == nil && p2AvgResult == nil {
-			continue
-		}
-		p1W, p2W, notWin = t.cx(p1AvgResult, p2AvgResult, false)
-		if p1W != "" {
-			p1WinP2Results = append(p1WinP2Results, p1W)
-		}
-		if p2W != "" {
-			p1WillWinP2Results = append(p1WillWinP2Results, p2W)
-		}
-		if notWin != "" {
-			notWineds = append(notWineds, notWin)
+	for _, ev := range cmp.Events {
+		for _, res := range []*wcaTypes.PersonCompareResult{ev.Single, ev.Average} {
+			if res == nil {
+				continue
+			}
+			p1W, p2W, notWin := cxLine(ev.EventID, res, res == ev.Single)
+			if p1W != "" {
+				p1WinP2Results = append(p1WinP2Results, p1W)
+			}
+			if p2W != "" {
+				p1WillWinP2Results = append(p1WillWinP2Results, p2W)
+			}
+			if notWin != "" {
+				notWineds = append(notWineds, notWin)
+			}
 		}
 	}
 
+	person1, person2 := cmp.Persons[0].Name, cmp.Persons[1].Name
 	out := "\n"
 
 	if len(p1WinP2Results) > 0 {
 		out += "\n =============================\n"
-		out += fmt.Sprintf("%s 超炫 %s的项目:\n", person1Result.PersonName, person2Result.PersonName)
+		out += fmt.Sprintf("%s 超炫 %s的项目:\n", person1, person2)
 		for _, p1WinP2Result := range p1WinP2Results {
 			out += p1WinP2Result + "\n"
 		}
@@ -432,7 +328,7 @@ func (t *TWca) handlerCxDoublePersonResult(message types.InMessage) (*types.OutM
 
 	if len(p1WillWinP2Results) > 0 {
 		out += "\n =============================\n"
-		out += fmt.Sprintf("%s 被%s超炫的项目: \n", person1Result.PersonName, person2Result.PersonName)
+		out += fmt.Sprintf("%s 被%s超炫的项目: \n", person1, person2)
 		for _, p1WillWinP2Result := range p1WillWinP2Results {
 			out += p1WillWinP2Result + "\n"
 		}
@@ -440,7 +336,7 @@ func (t *TWca) handlerCxDoublePersonResult(message types.InMessage) (*types.OutM
 
 	if len(notWineds) > 0 {
 		out += "\n =============================\n"
-		out += fmt.Sprintf("%s和%s打平手的项目: \n", person1Result.PersonName, person2Result.PersonName)
+		out += fmt.Sprintf("%s和%s打平手的项目: \n", person1, person2)
 		for _, notWined := range notWineds {
 			out += notWined + "\n"
 		}
@@ -464,3 +360,179 @@ func (t *TWca) handlerSeniorPersonResult(message types.InMessage) (*types.OutMes
 	}
 	return message.NewOutMessage(out.String()), nil
 }
+
+// compRoundMaxLines 机器人单轮最多展示的成绩条数
+const compRoundMaxLines = 16
+
+var podiumIcons = []string{"🥇", "🥈", "🥉"}
+
+func compResultStr(r wcaTypes.Result) string {
+	// 平均赛制按平均成绩排名
+	if r.FormatID == "a" || r.FormatID == "m" {
+		return fmt.Sprintf("%s / %s", utils.ResultsTimeFormat(r.Average, r.EventID), utils.ResultsTimeFormat(r.Best, r.EventID))
+	}
+	return utils.ResultsTimeFormat(r.Best, r.EventID)
+}
+
+func compEventID(in string) string {
+	for id, name := range wca_model.WcaEventsCnMap {
+		if strings.EqualFold(in, id) || in == name {
+			return id
+		}
+	}
+	return in
+}
+
+func (t *TWca) handlerCompetitionResults(message types.InMessage) (*types.OutMessage, error) {
+	// 比赛 ID 区分大小写，使用原始消息
+	slices := utils2.Split(message.Message, " ")
+	if len(slices) < 2 || t.Wca == nil {
+		return message.NewOutMessage(t.Help()), nil
+	}
+
+	res, err := t.Wca.GetCompetitionResults(slices[1])
+	if err != nil {
+		return message.NewOutMessagef("查询不到比赛%s", slices[1]), nil
+	}
+	comp := res.Competition
+
+	out := fmt.Sprintf("%s\n%s ~ %s %s\n", comp.Name, comp.StartDate, comp.EndDate, comp.CityName)
+
+	// 指定项目时输出该项目各轮次成绩
+	if len(slices) >= 3 {
+		eventID := compEventID(slices[2])
+		found := false
+		for _, round := range res.Rounds {
+			if round.EventID != eventID {
+				continue
+			}
+			found = true
+			out += fmt.Sprintf("\n== %s %s ==\n", wca_model.WcaEventsCnMap[eventID], round.RoundName)
+			for idx, r := range round.Results {
+				if idx >= compRoundMaxLines {
+					out += fmt.Sprintf("... 共%d人\n", len(round.Results))
+					break
+				}
+				out += fmt.Sprintf("%d. %s %s\n", r.Pos, r.PersonName, compResultStr(r))
+			}
+		}
+		if !found {
+			return message.NewOutMessagef("%s没有项目%s的成绩", comp.Name, slices[2]), nil
+		}
+		return message.NewOutMessage(out), nil
+	}
+
+	if len(res.Podiums) == 0 {
+		out += "暂无成绩\n"
+	}
+	for _, podium := range res.Podiums {
+		out += fmt.Sprintf("\n%s\n", wca_model.WcaEventsCnMap[podium.EventID])
+		for _, r := range podium.Results {
+			out += fmt.Sprintf("%s %s %s\n", podiumIcons[r.Pos-1], r.PersonName, compResultStr(r))
+		}
+	}
+
+	if len(res.Records) > 0 {
+		out += "\n== 纪录 ==\n"
+		for _, rec := range res.Records {
+			kind := "单次"
+			if rec.IsAvg {
+				kind = "平均"
+			}
+			out += fmt.Sprintf("%s%s %s %s %s\n", wca_model.WcaEventsCnMap[rec.EventID], kind, rec.Record, rec.ResultStr, rec.Name)
+		}
+	}
+	return message.NewOutMessage(out), nil
+}
+
+// rankTargetMaxLines 机器人最多展示的会超过的选手
+const rankTargetMaxLines = 10
+
+var rankTargetScopeNames = map[string]string{
+	"世界": "world", "world": "world",
+	"洲": "continent", "continent": "continent",
+	"国家": "country", "country": "country",
+}
+
+func (t *TWca) handlerRankTarget(message types.InMessage) (*types.OutMessage, error) {
+	slices := utils2.Split(message.Message, " ")
+	if len(slices) < 4 || t.Wca == nil {
+		return message.NewOutMessage(t.Help()), nil
+	}
+
+	wcaID := strings.ToUpper(slices[1])
+	if len(wcaID) != 10 {
+		id, err := t.getPersonWCAID(slices[1])
+		if err != nil {
+			return message.NewOutMessage(err.Error()), nil
+		}
+		wcaID = id
+	}
+	eventID := compEventID(slices[2])
+	rank, err := strconv.Atoi(slices[3])
+	if err != nil || rank <= 0 {
+		return message.NewOutMessagef("目标排名%s无效", slices[3]), nil
+	}
+
+	isAvg, scope := true, "country"
+	for _, opt := range slices[4:] {
+		switch strings.ToLower(opt) {
+		case "单次", "single":
+			isAvg = false
+		case "平均", "avg", "average":
+			isAvg = true
+		default:
+			if sc, ok := rankTargetScopeNames[strings.ToLower(opt)]; ok {
+				scope = sc
+			}
+		}
+	}
+
+	res, err := t.Wca.GetRankTarget(wcaID, eventID, scope, rank, isAvg)
+	if err != nil {
+		return message.NewOutMessagef("查询不到选手%s", wcaID), nil
+	}
+
+	kind := "单次"
+	if res.IsAvg {
+		kind = "平均"
+	}
+	scopeName := map[string]string{"world": "世界", "continent": "洲", "country": "国家"}[res.Scope]
+	out := fmt.Sprintf("%s %s%s %s排名第%d名\n", res.Name, wca_model.WcaEventsCnMap[eventID], kind, scopeName, rank)
+	if res.Current > 0 {
+		out += fmt.Sprintf("当前: %s (第%d名)\n", res.CurrentStr, res.CurrentRank)
+	} else {
+		out += "当前: 暂无成绩\n"
+	}
+
+	switch {
+	case res.Reached:
+		out += "已经达到目标排名\n"
+		return message.NewOutMessage(out), nil
+	case res.Required == 0:
+		out += "排名人数不足, 任意有效成绩即可达到\n"
+		return message.NewOutMessage(out), nil
+	}
+
+	out += fmt.Sprintf("需要: %s", res.RequiredStr)
+	if res.TargetPerson != nil {
+		out += fmt.Sprintf(" (目前第%d名 %s)", res.TargetPerson.Rank, res.TargetPerson.Name)
+	}
+	out += "\n"
+
+	if res.PassedCount > 0 {
+		out += fmt.Sprintf("\n将超过%d人:\n", res.PassedCount)
+		for idx, p := range res.Passed {
+			if idx >= rankTargetMaxLines {
+				out += "...\n"
+				break
+			}
+			out += fmt.Sprintf("%d. %s %s\n", p.Rank, p.Name, p.BestStr)
+		}
+	}
+
+	if c := res.Counting; c != nil {
+		out += fmt.Sprintf("\n按最近一次平均 %s (%s), 计数成绩需要在 %s ~ %s\n", c.AverageStr, c.CompetitionName, c.BestCountingStr, c.WorstCountingStr)
+	}
+	return message.NewOutMessage(out), nil
+}
@@ -0,0 +1,181 @@
+package wca
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/wca/utils"
+	"github.com/guojia99/cubing-pro/src/wca/types"
+)
+
+// compareWinner 比较两个成绩，返回 1 / 2 为较好的一方，0 为持平；没有成绩（0）的一方判负
+func compareWinner(eventId string, a, b int) int {
+	switch {
+	case a == b:
+		return 0
+	case b == 0:
+		return 1
+	case a == 0:
+		return 2
+	case utils.IsBestResult(eventId, a, b):
+		return 1
+	}
+	return 2
+}
+
+func compareDiffStr(eventId string, isAvg bool, diff int) string {
+	switch {
+	case eventId == "333fm" && isAvg:
+		return fmt.Sprintf("%.2f", float64(diff)/100)
+	case eventId == "333fm":
+		return fmt.Sprintf("%d", diff)
+	}
+	return utils.ResultsTimeFormat(diff, eventId)
+}
+
+func (w *wca) ComparePersons(wcaIdA, wcaIdB string) (types.PersonCompare, error) {
+	ids := [2]string{normalizeWcaID(wcaIdA), normalizeWcaID(wcaIdB)}
+	key := fmt.Sprintf("ComparePersons_%s_%s", ids[0], ids[1])
+	if data, ok := w.cache.Get(key); ok {
+		return data.(types.PersonCompare), nil
+	}
+
+	out := types.PersonCompare{
+		Events:       make([]types.PersonCompareEvent, 0),
+		Competitions: make([]types.PersonCompareCompetition, 0),
+	}
+	countries := w.GetAllCountry()
+	for i, id := range ids {
+		if err := w.db().Where("wca_id = ? AND sub_id = 1", id).First(&out.Persons[i]).Error; err != nil {
+			return out, fmt.Errorf("not found wca id %s", id)
+		}
+		out.Persons[i].Iso2 = countries[out.Persons[i].CountryID].ISO2
+	}
+
+	// 1. PB 与排名
+	var singles []types.RanksSingle
+	var avgs []types.RanksAverage
+	w.db().Where("person_id IN ?", ids[:]).Find(&singles)
+	w.db().Where("person_id IN ?", ids[:]).Find(&avgs)
+	singleMap := make(map[string]*types.PersonCompareResult)
+	avgMap := make(map[string]*types.PersonCompareResult)
+	set := func(m map[string]*types.PersonCompareResult, personID, eventID string, best, wr, cr, nr int) {
+		if _, ok := m[eventID]; !ok {
+			m[eventID] = &types.PersonCompareResult{}
+		}
+		for i := range ids {
+			if ids[i] != personID {
+				continue
+			}
+			m[eventID].Values[i] = best
+			m[eventID].ValueStrs[i] = utils.ResultsTimeFormat(best, eventID)
+			m[eventID].WorldRanks[i], m[eventID].ContinentRanks[i], m[eventID].CountryRanks[i] = wr, cr, nr
+		}
+	}
+	for _, r := range singles {
+		set(singleMap, r.PersonID, r.EventID, r.Best, r.WorldRank, r.ContinentRank, r.CountryRank)
+	}
+	for _, r := range avgs {
+		set(avgMap, r.PersonID, r.EventID, r.Best, r.WorldRank, r.ContinentRank, r.CountryRank)
+	}
+
+	score := func(eventID string, isAvg bool, res *types.PersonCompareResult) {
+		if res == nil {
+			return
+		}
+		a, b := res.Values[0], res.Values[1]
+		res.Winner = compareWinner(eventID, a, b)
+		if a > 0 && b > 0 && eventID != "333mbf" {
+			res.Diff = max(a-b, b-a)
+			res.DiffStr = compareDiffStr(eventID, isAvg, res.Diff)
+		}
+		for i := range ids {
+			if res.Winner == 0 || res.Winner == i+1 {
+				out.Score[i]++
+				if a > 0 && b > 0 {
+					out.SharedScore[i]++
+				}
+			}
+		}
+	}
+	for _, ev := range wcaEventsList {
+		if singleMap[ev] == nil {
+			continue
+		}
+		item := types.PersonCompareEvent{EventID: ev, Single: singleMap[ev], Average: avgMap[ev]}
+		score(ev, false, item.Single)
+		score(ev, true, item.Average)
+		out.Events = append(out.Events, item)
+	}
+
+	// 2. 同场对决，按名次比较同一轮次
+	var results []types.Result
+	if err := w.db().Where("person_id IN ?", ids[:]).Find(&results).Error; err != nil {
+		return out, err
+	}
+	compPersons := make(map[string]map[string]bool)
+	for _, r := range results {
+		if compPersons[r.CompetitionID] == nil {
+			compPersons[r.CompetitionID] = make(map[string]bool)
+		}
+		compPersons[r.CompetitionID][r.PersonID] = true
+	}
+	var shared []types.Result
+	for _, r := range results {
+		if len(compPersons[r.CompetitionID]) == 2 {
+			shared = append(shared, r)
+		}
+	}
+	if len(shared) > 0 {
+		shared = w.setResultAttempts(shared)
+		shared = w.setCompetitionNameAndSort(shared)
+	}
+
+	roundTypes := w.getRoundTypeMap()
+	roundMap := make(map[string]*types.PersonCompareRound)
+	var comps []*types.PersonCompareCompetition
+	compMap := make(map[string]*types.PersonCompareCompetition)
+	for _, r := range shared {
+		comp, ok := compMap[r.CompetitionID]
+		if !ok {
+			comp = &types.PersonCompareCompetition{
+				CompetitionID:   r.CompetitionID,
+				CompetitionName: r.CompetitionName,
+				Date:            r.CompetitionTime,
+				Rounds:          make([]types.PersonCompareRound, 0),
+			}
+			compMap[r.CompetitionID] = comp
+			comps = append(comps, comp)
+		}
+
+		k := r.CompetitionID + "_" + r.EventID + "_" + r.RoundTypeID
+		round, ok := roundMap[k]
+		if !ok {
+			round = &types.PersonCompareRound{EventID: r.EventID, RoundTypeID: r.RoundTypeID, RoundName: roundTypes[r.RoundTypeID].Name}
+			roundMap[k] = round
+		}
+		if r.PersonID == ids[0] {
+			round.Results[0] = r
+		} else {
+			round.Results[1] = r
+		}
+		if round.Results[0].ID == 0 || round.Results[1].ID == 0 {
+			continue
+		}
+
+		round.Winner = compareWinner("", int(round.Results[0].Pos), int(round.Results[1].Pos))
+		if round.Winner != 0 {
+			comp.Wins[round.Winner-1]++
+			out.HeadToHead[round.Winner-1]++
+		}
+		comp.Rounds = append(comp.Rounds, *round)
+	}
+	for i := len(comps) - 1; i >= 0; i-- {
+		if len(comps[i].Rounds) > 0 {
+			out.Competitions = append(out.Competitions, *comps[i])
+		}
+	}
+
+	w.cache.Set(key, out, time.Minute*30)
+	return out, nil
+}
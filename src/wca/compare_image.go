@@ -0,0 +1,118 @@
+package wca
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/fogleman/gg"
+	wca_model "github.com/guojia99/cubing-pro/src/internel/database/model/wca"
+	"github.com/guojia99/cubing-pro/src/internel/ttf"
+	"github.com/guojia99/cubing-pro/src/wca/types"
+)
+
+const (
+	compareImageWidth   = 960
+	compareImagePadding = 24
+	compareRowHeight    = 36
+	compareMaxComps     = 5 // 图片中最多展示的同场比赛
+)
+
+var (
+	compareWinColor  = color.RGBA{R: 0x2e, G: 0x9d, B: 0x4f, A: 0xff}
+	compareTextColor = color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
+	compareGrayColor = color.RGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff}
+	compareRowColor  = color.RGBA{R: 0xf4, G: 0xf6, B: 0xf8, A: 0xff}
+)
+
+func (w *wca) GetPersonCompareImage(wcaIdA, wcaIdB string) (image.Image, error) {
+	cmp, err := w.ComparePersons(wcaIdA, wcaIdB)
+	if err != nil {
+		return nil, err
+	}
+	return drawPersonCompare(cmp), nil
+}
+
+// drawPersonCompare 绘制对比总结图：标题、比分、各项目 PB 与最近的同场比赛
+func drawPersonCompare(cmp types.PersonCompare) image.Image {
+	comps := cmp.Competitions
+	if len(comps) > compareMaxComps {
+		comps = comps[:compareMaxComps]
+	}
+	height := compareImagePadding*2 + 110 + (len(cmp.Events)+1)*compareRowHeight
+	if len(comps) > 0 {
+		height += (len(comps) + 1) * compareRowHeight
+	}
+
+	dc := gg.NewContext(compareImageWidth, height)
+	dc.SetColor(color.White)
+	dc.Clear()
+
+	// 标题与比分
+	y := float64(compareImagePadding)
+	dc.SetColor(compareTextColor)
+	dc.SetFontFace(ttf.HuaWenHeiTiTTFFontFace(28))
+	dc.DrawStringAnchored(fmt.Sprintf("1. %s  VS  2. %s", cmp.Persons[0].Name, cmp.Persons[1].Name), compareImageWidth/2, y+20, 0.5, 0.5)
+	dc.SetFontFace(ttf.HuaWenHeiTiTTFFontFace(18))
+	dc.DrawStringAnchored(fmt.Sprintf("PB 对比 %d : %d    同场对决 %d : %d", cmp.Score[0], cmp.Score[1], cmp.HeadToHead[0], cmp.HeadToHead[1]),
+		compareImageWidth/2, y+64, 0.5, 0.5)
+	y += 110
+
+	// 各项目 PB
+	cols := []float64{compareImagePadding, 200, 380, 560, 740}
+	drawRow := func(idx int, cells []string, colors []color.Color) {
+		if idx%2 == 1 {
+			dc.SetColor(compareRowColor)
+			dc.DrawRectangle(compareImagePadding/2, y, compareImageWidth-compareImagePadding, compareRowHeight)
+			dc.Fill()
+		}
+		for i, cell := range cells {
+			dc.SetColor(colors[i])
+			dc.DrawStringAnchored(cell, cols[i], y+compareRowHeight/2, 0, 0.5)
+		}
+		y += compareRowHeight
+	}
+	valueCells := func(res *types.PersonCompareResult) ([]string, []color.Color) {
+		cells := []string{"-", "-"}
+		colors := []color.Color{compareGrayColor, compareGrayColor}
+		if res == nil {
+			return cells, colors
+		}
+		for i := range cells {
+			if res.Values[i] == 0 {
+				continue
+			}
+			cells[i] = res.ValueStrs[i]
+			colors[i] = compareTextColor
+			if res.Winner == i+1 {
+				colors[i] = compareWinColor
+			}
+		}
+		return cells, colors
+	}
+
+	drawRow(0, []string{"项目", "单次 1", "单次 2", "平均 1", "平均 2"},
+		[]color.Color{compareGrayColor, compareGrayColor, compareGrayColor, compareGrayColor, compareGrayColor})
+	for idx, ev := range cmp.Events {
+		single, singleColors := valueCells(ev.Single)
+		avg, avgColors := valueCells(ev.Average)
+		cells := append([]string{wca_model.WcaEventsCnMap[ev.EventID]}, append(single, avg...)...)
+		colors := append([]color.Color{compareTextColor}, append(singleColors, avgColors...)...)
+		drawRow(idx+1, cells, colors)
+	}
+
+	// 最近的同场比赛
+	if len(comps) == 0 {
+		return dc.Image()
+	}
+	dc.SetColor(compareGrayColor)
+	dc.DrawStringAnchored(fmt.Sprintf("共同参加 %d 场比赛", len(cmp.Competitions)), compareImagePadding, y+compareRowHeight/2, 0, 0.5)
+	y += compareRowHeight
+	for _, comp := range comps {
+		dc.SetColor(compareTextColor)
+		dc.DrawStringAnchored(fmt.Sprintf("%s  %s", comp.Date, comp.CompetitionName), compareImagePadding, y+compareRowHeight/2, 0, 0.5)
+		dc.DrawStringAnchored(fmt.Sprintf("%d : %d", comp.Wins[0], comp.Wins[1]), compareImageWidth-compareImagePadding, y+compareRowHeight/2, 1, 0.5)
+		y += compareRowHeight
+	}
+	return dc.Image()
+}
@@ -0,0 +1,137 @@
+package wca
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/wca/utils"
+	"github.com/guojia99/cubing-pro/src/wca/types"
+)
+
+// podiumSize 领奖台人数
+const podiumSize = 3
+
+func (w *wca) GetCompetitionResults(compId string) (types.CompetitionResults, error) {
+	comp, err := w.GetCompetition(compId)
+	if err != nil {
+		return types.CompetitionResults{}, err
+	}
+
+	key := fmt.Sprintf("GetCompetitionResults_%s", comp.ID)
+	if data, ok := w.cache.Get(key); ok {
+		return data.(types.CompetitionResults), nil
+	}
+
+	var results []types.Result
+	if err = w.db().Where("competition_id = ?", comp.ID).Find(&results).Error; err != nil {
+		return types.CompetitionResults{}, err
+	}
+	results = w.setResultAttempts(results)
+	for idx := range results {
+		results[idx].CompetitionName = comp.Name
+		results[idx].CompetitionTime = comp.StartDate
+	}
+
+	out := types.CompetitionResults{
+		Competition: comp,
+		Rounds:      make([]types.CompetitionRound, 0),
+		Podiums:     make([]types.CompetitionPodium, 0),
+		Records:     make([]types.CompetitionRecord, 0),
+	}
+
+	// 按项目 + 轮次分组
+	roundTypes := w.getRoundTypeMap()
+	roundMap := make(map[string]*types.CompetitionRound)
+	for _, r := range results {
+		k := r.EventID + "_" + r.RoundTypeID
+		if _, ok := roundMap[k]; !ok {
+			rt := roundTypes[r.RoundTypeID]
+			roundMap[k] = &types.CompetitionRound{
+				EventID:     r.EventID,
+				RoundTypeID: r.RoundTypeID,
+				RoundName:   rt.Name,
+				Final:       rt.Final,
+				FormatID:    r.FormatID,
+			}
+		}
+		roundMap[k].Results = append(roundMap[k].Results, r)
+	}
+	for _, round := range roundMap {
+		sort.Slice(round.Results, func(i, j int) bool {
+			if round.Results[i].Pos != round.Results[j].Pos {
+				return round.Results[i].Pos < round.Results[j].Pos
+			}
+			return round.Results[i].ID < round.Results[j].ID
+		})
+		out.Rounds = append(out.Rounds, *round)
+	}
+
+	eventOrder := buildEventOrderMap()
+	order := func(eventID string) int {
+		if o, ok := eventOrder[eventID]; ok {
+			return o
+		}
+		return len(wcaEventsList)
+	}
+	sort.Slice(out.Rounds, func(i, j int) bool {
+		a, b := out.Rounds[i], out.Rounds[j]
+		if a.EventID != b.EventID {
+			if order(a.EventID) != order(b.EventID) {
+				return order(a.EventID) < order(b.EventID)
+			}
+			return a.EventID < b.EventID
+		}
+		return roundTypes[a.RoundTypeID].Rank < roundTypes[b.RoundTypeID].Rank
+	})
+
+	for _, round := range out.Rounds {
+		// 领奖台
+		if round.Final {
+			podium := types.CompetitionPodium{EventID: round.EventID}
+			for _, r := range round.Results {
+				if r.Pos > podiumSize {
+					break
+				}
+				if r.Best > 0 {
+					podium.Results = append(podium.Results, r)
+				}
+			}
+			if len(podium.Results) > 0 {
+				out.Podiums = append(out.Podiums, podium)
+			}
+		}
+
+		// 纪录
+		for _, r := range round.Results {
+			if r.RegionalSingleRecord != "" {
+				out.Records = append(out.Records, newCompetitionRecord(r, false))
+			}
+			if r.RegionalAverageRecord != "" {
+				out.Records = append(out.Records, newCompetitionRecord(r, true))
+			}
+		}
+	}
+
+	w.cache.Set(key, out, time.Minute*30)
+	return out, nil
+}
+
+func newCompetitionRecord(r types.Result, isAvg bool) types.CompetitionRecord {
+	rec := types.CompetitionRecord{
+		EventID:     r.EventID,
+		RoundTypeID: r.RoundTypeID,
+		WcaID:       r.PersonID,
+		Name:        r.PersonName,
+		CountryID:   r.PersonCountryID,
+		IsAvg:       isAvg,
+		Record:      r.RegionalSingleRecord,
+		Result:      r.Best,
+	}
+	if isAvg {
+		rec.Record = r.RegionalAverageRecord
+		rec.Result = r.Average
+	}
+	rec.ResultStr = utils.ResultsTimeFormat(rec.Result, r.EventID)
+	return rec
+}
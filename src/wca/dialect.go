@@ -0,0 +1,93 @@
+package wca
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	wcaDriverMySQL  = "mysql"
+	wcaDriverSqlite = "sqlite"
+)
+
+// sqliteMaxBatch SQLite 单条语句最多 32766 个参数，批量写入时按此限制行数
+const sqliteMaxBatch = 1000
+
+func wcaDriver(driver string) string {
+	if strings.EqualFold(driver, wcaDriverSqlite) {
+		return wcaDriverSqlite
+	}
+	return wcaDriverMySQL
+}
+
+// sqliteDbFile SQLite 模式下每个 WCA 导出版本对应 dbPath 下的一个文件，如 wca_20251225.db
+func sqliteDbFile(dbPath, dbName string) string {
+	return filepath.Join(dbPath, dbName+".db")
+}
+
+// openWcaDB 打开名为 dbName 的 WCA 数据库
+func openWcaDB(driver, dbURL, dbPath, dbName string) (*gorm.DB, error) {
+	if driver == wcaDriverSqlite {
+		return gorm.Open(sqlite.Open(sqliteDbFile(dbPath, dbName)), &gorm.Config{})
+	}
+	return gorm.Open(mysql.Open(dbURL+dbName+mysqlOtherSet), &gorm.Config{})
+}
+
+var (
+	mysqlAlterIndexRe  = regexp.MustCompile(`(?i)^ALTER\s+TABLE\s+(\w+)\s+ADD\s+INDEX\s+(\w+)\s*(\(.*\))\s*;?$`)
+	mysqlCreateIndexRe = regexp.MustCompile(`(?i)^CREATE\s+INDEX\s+(\w+)\s+ON\s+(\w+)\s*(\(.*\))\s*;?$`)
+)
+
+// indexDef 从建索引语句中解析出的索引
+type indexDef struct {
+	table   string
+	name    string
+	columns string // 含括号，如 (event_id, best)
+}
+
+// nameFor SQLite 的索引名在整个库内唯一，因此加上表名前缀
+func (d indexDef) nameFor(driver string) string {
+	if driver == wcaDriverSqlite {
+		return d.table + "_" + d.name
+	}
+	return d.name
+}
+
+// parseIndexStatements 解析 MySQL 的建索引语句，全文索引无法在 SQLite 中创建，直接跳过
+func parseIndexStatements(indexData string) ([]indexDef, error) {
+	var out []indexDef
+	for _, stmt := range strings.Split(indexData, "\n") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" || strings.HasPrefix(stmt, "--") || strings.Contains(strings.ToUpper(stmt), "FULLTEXT") {
+			continue
+		}
+
+		if m := mysqlAlterIndexRe.FindStringSubmatch(stmt); m != nil {
+			out = append(out, indexDef{table: m[1], name: m[2], columns: m[3]})
+		} else if m = mysqlCreateIndexRe.FindStringSubmatch(stmt); m != nil {
+			out = append(out, indexDef{table: m[2], name: m[1], columns: m[3]})
+		} else {
+			return nil, fmt.Errorf("unsupported index statement: %s", stmt)
+		}
+	}
+	return out, nil
+}
+
+// sqliteIndexStatements 将 MySQL 的建索引语句转换为 SQLite 语句
+func sqliteIndexStatements(indexData string) ([]string, error) {
+	defs, err := parseIndexStatements(indexData)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(defs))
+	for _, d := range defs {
+		out = append(out, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s %s", d.nameFor(wcaDriverSqlite), d.table, d.columns))
+	}
+	return out, nil
+}
@@ -0,0 +1,254 @@
+package wca
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/wca/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// exportDataset 可导出的数据集，country / event / year 为对应过滤的查询条件，空表示不支持该过滤
+type exportDataset struct {
+	name    string
+	new     func() interface{}
+	country string
+	event   string
+	year    string
+}
+
+const (
+	exportRankCountry   = "person_id IN (SELECT wca_id FROM persons WHERE sub_id = 1 AND country_id = ?)"
+	exportCompYear      = "competition_id IN (SELECT id FROM competitions WHERE year = ?)"
+	exportAttemptPrefix = "result_id IN (SELECT id FROM results WHERE "
+)
+
+// exportDatasets WCA 原始数据表与预计算的统计表
+var exportDatasets = []exportDataset{
+	{name: "competitions", new: func() interface{} { return &types.Competition{} }, country: "country_id = ?", year: "year = ?"},
+	{name: "continents", new: func() interface{} { return &types.Continent{} }},
+	{name: "countries", new: func() interface{} { return &types.Country{} }},
+	{name: "events", new: func() interface{} { return &types.Event{} }},
+	{name: "formats", new: func() interface{} { return &types.Format{} }},
+	{name: "round_types", new: func() interface{} { return &types.RoundType{} }},
+	{name: "championships", new: func() interface{} { return &types.Championship{} }},
+	{name: "persons", new: func() interface{} { return &types.Person{} }, country: "country_id = ?"},
+	{name: "ranks_single", new: func() interface{} { return &types.RanksSingle{} }, country: exportRankCountry, event: "event_id = ?"},
+	{name: "ranks_average", new: func() interface{} { return &types.RanksAverage{} }, country: exportRankCountry, event: "event_id = ?"},
+	{name: "results", new: func() interface{} { return &types.Result{} }, country: "person_country_id = ?", event: "event_id = ?", year: exportCompYear},
+	{
+		name:    "result_attempts",
+		new:     func() interface{} { return &types.ResultAttempt{} },
+		country: exportAttemptPrefix + "person_country_id = ?)",
+		event:   exportAttemptPrefix + "event_id = ?)",
+		year:    exportAttemptPrefix + exportCompYear + ")",
+	},
+
+	// 统计数据，需要先完成同步
+	{name: "rank_timers", new: func() interface{} { return &types.StaticWithTimerRank{} }, country: "country = ?", event: "event_id = ?", year: "year = ?"},
+	{name: "success_rates", new: func() interface{} { return &types.StaticSuccessRateResult{} }, country: "country = ?", event: "event_id = ?"},
+	{name: "all_events", new: func() interface{} { return &types.AllEventAvgPersonResults{} }, country: "country = ?"},
+	{name: "record_history", new: func() interface{} { return &types.StaticRecordHistory{} }, country: "country = ?", event: "event_id = ?", year: "SUBSTR(record_date, 1, 4) = ?"},
+	{name: "person_provinces", new: func() interface{} { return &types.StaticPersonProvince{} }},
+}
+
+func (w *wca) ExportDatasets() []string {
+	out := make([]string, 0, len(exportDatasets))
+	for _, ds := range exportDatasets {
+		out = append(out, ds.name)
+	}
+	return out
+}
+
+// exportWriter 导出格式的行写入
+type exportWriter interface {
+	WriteRow(values []any) error
+	Close() error
+}
+
+type csvExportWriter struct{ w *csv.Writer }
+
+func (c *csvExportWriter) WriteRow(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvExportWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// exportParquetType 字段对应的 Parquet 类型，整数与布尔导出为 INT64，时间导出为字符串
+func exportParquetType(t reflect.Type) int32 {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Bool:
+		return parquetInt64
+	case reflect.Float32, reflect.Float64:
+		return parquetDouble
+	}
+	return parquetByteArray
+}
+
+// exportValue 转为写入使用的 int64 / float64 / string，空指针为 nil
+func exportValue(v reflect.Value) any {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		if v.Bool() {
+			return int64(1)
+		}
+		return int64(0)
+	case reflect.String:
+		return v.String()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.DateTime)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Export 按行读取数据集写入 CSV 或 Parquet，返回导出的行数；不会一次性读入整张表
+func (w *wca) Export(out io.Writer, opt types.ExportOptions) (int64, error) {
+	var ds *exportDataset
+	for i := range exportDatasets {
+		if exportDatasets[i].name == opt.Dataset {
+			ds = &exportDatasets[i]
+		}
+	}
+	if ds == nil {
+		return 0, fmt.Errorf("unknown dataset %s, available: %s", opt.Dataset, strings.Join(w.ExportDatasets(), ", "))
+	}
+
+	db := w.db()
+	if db == nil {
+		return 0, fmt.Errorf("wca db is not ready")
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(ds.new()); err != nil {
+		return 0, err
+	}
+	columns := opt.Columns
+	if len(columns) == 0 {
+		columns = stmt.Schema.DBNames
+	}
+	fields := make([]*schema.Field, 0, len(columns))
+	for _, col := range columns {
+		field, ok := stmt.Schema.FieldsByDBName[strings.TrimSpace(col)]
+		if !ok {
+			return 0, fmt.Errorf("dataset %s has no column %s, available: %s", ds.name, col, strings.Join(stmt.Schema.DBNames, ", "))
+		}
+		fields = append(fields, field)
+	}
+
+	// 过滤条件
+	query := db.Model(ds.new())
+	filters := []struct {
+		cond, name string
+		value      any
+		set        bool
+	}{
+		{ds.country, "country", w.getCountryID(opt.Country), opt.Country != ""},
+		{ds.event, "event", opt.EventID, opt.EventID != ""},
+		{ds.year, "year", strconv.Itoa(opt.Year), opt.Year != 0}, // 字符串参数，同时兼容年份列与日期前缀
+	}
+	for _, f := range filters {
+		if !f.set {
+			continue
+		}
+		if f.cond == "" {
+			return 0, fmt.Errorf("dataset %s does not support %s filter", ds.name, f.name)
+		}
+		query = query.Where(f.cond, f.value)
+	}
+
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.DBName
+	}
+	var writer exportWriter
+	switch opt.Format {
+	case types.ExportFormatCSV, "":
+		c := csv.NewWriter(out)
+		if err := c.Write(names); err != nil {
+			return 0, err
+		}
+		writer = &csvExportWriter{w: c}
+	case types.ExportFormatParquet:
+		typs := make([]int32, len(fields))
+		for i, f := range fields {
+			typs[i] = exportParquetType(f.FieldType)
+		}
+		p, err := newParquetWriter(out, names, typs)
+		if err != nil {
+			return 0, err
+		}
+		writer = p
+	default:
+		return 0, fmt.Errorf("unknown export format %s", opt.Format)
+	}
+
+	// 有主键时按主键排序，保证多次导出顺序一致
+	if pk := stmt.Schema.PrioritizedPrimaryField; pk != nil {
+		query = query.Order(pk.DBName)
+	}
+	rows, err := query.Select(names).Rows()
+	if err != nil {
+		return 0, fmt.Errorf("dataset %s: %w", ds.name, err)
+	}
+	defer rows.Close()
+
+	var count int64
+	ctx := context.Background()
+	values := make([]any, len(fields))
+	for rows.Next() {
+		item := ds.new()
+		if err = db.ScanRows(rows, item); err != nil {
+			return count, err
+		}
+		rv := reflect.ValueOf(item).Elem()
+		for i, f := range fields {
+			values[i] = exportValue(f.ReflectValueOf(ctx, rv))
+		}
+		if err = writer.WriteRow(values); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
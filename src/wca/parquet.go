@@ -0,0 +1,312 @@
+package wca
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// 最小化的 Parquet 写入实现：所有列为 OPTIONAL，PLAIN 编码、不压缩，每个行组每列一个数据页。
+// 只支持导出需要的 INT64 / DOUBLE / UTF8 三种类型，格式参考 https://parquet.apache.org/docs/file-format/
+
+const parquetMagic = "PAR1"
+
+// parquetRowGroupSize 每个行组的行数，写满后落盘
+const parquetRowGroupSize = 1 << 16
+
+// parquet 物理类型
+const (
+	parquetInt64     int32 = 2
+	parquetDouble    int32 = 5
+	parquetByteArray int32 = 6
+)
+
+// parquet 枚举值
+const (
+	parquetRepetitionOptional int32 = 1
+	parquetConvertedUTF8      int32 = 0
+	parquetEncodingPlain      int32 = 0
+	parquetEncodingRLE        int32 = 3
+	parquetCodecUncompressed  int32 = 0
+	parquetPageData           int32 = 0
+)
+
+// thrift compact 协议类型
+const (
+	thriftI32    byte = 5
+	thriftI64    byte = 6
+	thriftBinary byte = 8
+	thriftList   byte = 9
+	thriftStruct byte = 12
+)
+
+// thriftWriter thrift compact 协议编码，Parquet 的页头与文件元数据均使用该协议
+type thriftWriter struct {
+	buf     bytes.Buffer
+	lastIDs []int16 // 嵌套结构体的上一个字段 ID
+	lastID  int16
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	t.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (t *thriftWriter) zigzag(v int64) { t.varint(uint64((v << 1) ^ (v >> 63))) }
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.zigzag(int64(id))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.zigzag(v)
+}
+
+func (t *thriftWriter) binary(v string) {
+	t.varint(uint64(len(v)))
+	t.buf.WriteString(v)
+}
+
+func (t *thriftWriter) string(id int16, v string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary(v)
+}
+
+func (t *thriftWriter) listHeader(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elemType)
+	t.varint(uint64(size))
+}
+
+// beginStruct 开始一个结构体，id 为 0 表示列表元素或顶层结构体，不写字段头
+func (t *thriftWriter) beginStruct(id int16) {
+	if id != 0 {
+		t.fieldHeader(id, thriftStruct)
+	}
+	t.lastIDs = append(t.lastIDs, t.lastID)
+	t.lastID = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0) // STOP
+	t.lastID = t.lastIDs[len(t.lastIDs)-1]
+	t.lastIDs = t.lastIDs[:len(t.lastIDs)-1]
+}
+
+// parquetColumn 列定义与当前行组的缓冲
+type parquetColumn struct {
+	name string
+	typ  int32
+
+	defLevels []byte // 1 为有值，0 为 null
+	values    bytes.Buffer
+}
+
+type parquetChunk struct {
+	typ        int32
+	name       string
+	numValues  int64
+	offset     int64
+	totalBytes int64
+}
+
+type parquetRowGroup struct {
+	numRows int64
+	chunks  []parquetChunk
+}
+
+// parquetWriter 按行写入，每 parquetRowGroupSize 行输出一个行组，Close 时写入文件元数据
+type parquetWriter struct {
+	w       io.Writer
+	offset  int64
+	columns []*parquetColumn
+	rows    int64 // 当前行组行数
+	groups  []parquetRowGroup
+}
+
+func newParquetWriter(w io.Writer, names []string, typs []int32) (*parquetWriter, error) {
+	p := &parquetWriter{w: w}
+	for i := range names {
+		p.columns = append(p.columns, &parquetColumn{name: names[i], typ: typs[i]})
+	}
+	return p, p.write([]byte(parquetMagic))
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	return err
+}
+
+// WriteRow 写入一行，值为 nil 表示 null，其余按列类型分别为 int64 / float64 / string
+func (p *parquetWriter) WriteRow(values []any) error {
+	if len(values) != len(p.columns) {
+		return fmt.Errorf("parquet: want %d values, got %d", len(p.columns), len(values))
+	}
+	for i, col := range p.columns {
+		if values[i] == nil {
+			col.defLevels = append(col.defLevels, 0)
+			continue
+		}
+		col.defLevels = append(col.defLevels, 1)
+		switch v := values[i].(type) {
+		case int64:
+			_ = binary.Write(&col.values, binary.LittleEndian, v)
+		case float64:
+			_ = binary.Write(&col.values, binary.LittleEndian, math.Float64bits(v))
+		case string:
+			_ = binary.Write(&col.values, binary.LittleEndian, uint32(len(v)))
+			col.values.WriteString(v)
+		default:
+			return fmt.Errorf("parquet: unsupported value %T in column %s", v, col.name)
+		}
+	}
+	p.rows++
+	if p.rows >= parquetRowGroupSize {
+		return p.flush()
+	}
+	return nil
+}
+
+// encodeDefLevels 位宽为 1 的 RLE 编码，数据页 v1 中需要 4 字节长度前缀
+func encodeDefLevels(levels []byte) []byte {
+	var t thriftWriter
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		t.varint(uint64(j-i) << 1)
+		t.buf.WriteByte(levels[i])
+		i = j
+	}
+	out := binary.LittleEndian.AppendUint32(nil, uint32(t.buf.Len()))
+	return append(out, t.buf.Bytes()...)
+}
+
+func (p *parquetWriter) flush() error {
+	if p.rows == 0 {
+		return nil
+	}
+	group := parquetRowGroup{numRows: p.rows}
+	for _, col := range p.columns {
+		body := append(encodeDefLevels(col.defLevels), col.values.Bytes()...)
+
+		var header thriftWriter
+		header.beginStruct(0)
+		header.i32(1, parquetPageData)
+		header.i32(2, int32(len(body)))
+		header.i32(3, int32(len(body)))
+		header.beginStruct(5)
+		header.i32(1, int32(len(col.defLevels)))
+		header.i32(2, parquetEncodingPlain)
+		header.i32(3, parquetEncodingRLE)
+		header.i32(4, parquetEncodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		chunk := parquetChunk{
+			typ:        col.typ,
+			name:       col.name,
+			numValues:  int64(len(col.defLevels)),
+			offset:     p.offset,
+			totalBytes: int64(header.buf.Len() + len(body)),
+		}
+		if err := p.write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := p.write(body); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		col.defLevels = col.defLevels[:0]
+		col.values.Reset()
+	}
+	p.groups = append(p.groups, group)
+	p.rows = 0
+	return nil
+}
+
+// Close 写入剩余行与文件元数据，不关闭底层 io.Writer
+func (p *parquetWriter) Close() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+
+	var numRows int64
+	for _, g := range p.groups {
+		numRows += g.numRows
+	}
+
+	var t thriftWriter
+	t.beginStruct(0)
+	t.i32(1, 1) // version
+	t.listHeader(2, thriftStruct, len(p.columns)+1)
+	t.beginStruct(0)
+	t.string(4, "schema")
+	t.i32(5, int32(len(p.columns)))
+	t.endStruct()
+	for _, col := range p.columns {
+		t.beginStruct(0)
+		t.i32(1, col.typ)
+		t.i32(3, parquetRepetitionOptional)
+		t.string(4, col.name)
+		if col.typ == parquetByteArray {
+			t.i32(6, parquetConvertedUTF8)
+		}
+		t.endStruct()
+	}
+	t.i64(3, numRows)
+	t.listHeader(4, thriftStruct, len(p.groups))
+	for _, g := range p.groups {
+		var total int64
+		t.beginStruct(0)
+		t.listHeader(1, thriftStruct, len(g.chunks))
+		for _, c := range g.chunks {
+			total += c.totalBytes
+			t.beginStruct(0)
+			t.i64(2, c.offset)
+			t.beginStruct(3)
+			t.i32(1, c.typ)
+			t.listHeader(2, thriftI32, 2)
+			t.zigzag(int64(parquetEncodingPlain))
+			t.zigzag(int64(parquetEncodingRLE))
+			t.listHeader(3, thriftBinary, 1)
+			t.binary(c.name)
+			t.i32(4, parquetCodecUncompressed)
+			t.i64(5, c.numValues)
+			t.i64(6, c.totalBytes)
+			t.i64(7, c.totalBytes)
+			t.i64(9, c.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64(2, total)
+		t.i64(3, g.numRows)
+		t.endStruct()
+	}
+	t.string(6, "cubing-pro")
+	t.endStruct()
+
+	meta := t.buf.Bytes()
+	meta = binary.LittleEndian.AppendUint32(meta, uint32(len(meta)))
+	return p.write(append(meta, parquetMagic...))
+}
@@ -0,0 +1,175 @@
+package wca
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+)
+
+// thriftReader 测试用的 thrift compact 解码，结构体解码为 字段 ID -> 值
+type thriftReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.varint())
+		r.pos += n
+		return string(r.b[r.pos-n : r.pos])
+	case thriftList:
+		h := r.b[r.pos]
+		r.pos++
+		size := int(h >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(h & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		out := make(map[int16]any)
+		var id int16
+		for {
+			h := r.b[r.pos]
+			r.pos++
+			if h == 0 {
+				return out
+			}
+			if delta := int16(h >> 4); delta != 0 {
+				id += delta
+			} else {
+				id = int16(r.zigzag())
+			}
+			out[id] = r.value(h & 0x0f)
+		}
+	}
+	panic("unsupported thrift type")
+}
+
+func Test_thriftWriter(t *testing.T) {
+	var w thriftWriter
+	w.beginStruct(0)
+	w.i32(1, 1)
+	w.i64(20, -2)
+	w.beginStruct(21)
+	w.string(1, "ab")
+	w.endStruct()
+	w.i32(22, 3)
+	w.endStruct()
+
+	want := []byte{0x15, 0x02, 0x06, 0x28, 0x03, 0x1c, 0x18, 0x02, 'a', 'b', 0x00, 0x15, 0x06, 0x00}
+	if !bytes.Equal(w.buf.Bytes(), want) {
+		t.Fatalf("want % x, got % x", want, w.buf.Bytes())
+	}
+}
+
+func Test_parquetWriter(t *testing.T) {
+	var buf bytes.Buffer
+	p, err := newParquetWriter(&buf, []string{"id", "name", "rate"}, []int32{parquetInt64, parquetByteArray, parquetDouble})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]any{
+		{int64(1), "a", 0.5},
+		{int64(2), nil, nil},
+		{nil, "ccc", 1.0},
+	}
+	for _, row := range rows {
+		if err = p.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = p.WriteRow([]any{int64(1)}); err == nil {
+		t.Fatal("want error for wrong column count")
+	}
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	if string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatal("missing parquet magic")
+	}
+	metaLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	r := &thriftReader{b: data[len(data)-8-metaLen : len(data)-8]}
+	meta := r.value(thriftStruct).(map[int16]any)
+	if r.pos != metaLen {
+		t.Fatalf("footer decoded %d of %d bytes", r.pos, metaLen)
+	}
+	if meta[3].(int64) != 3 {
+		t.Fatalf("want 3 rows, got %v", meta[3])
+	}
+	schemaList := meta[2].([]any)
+	if len(schemaList) != 4 || schemaList[2].(map[int16]any)[4] != "name" || schemaList[2].(map[int16]any)[6].(int64) != int64(parquetConvertedUTF8) {
+		t.Fatalf("unexpected schema %v", schemaList)
+	}
+
+	// name 列的数据页：2 个值 1 个 null
+	group := meta[4].([]any)[0].(map[int16]any)
+	chunk := group[1].([]any)[1].(map[int16]any)[3].(map[int16]any)
+	offset := int(chunk[9].(int64))
+	r = &thriftReader{b: data, pos: offset}
+	header := r.value(thriftStruct).(map[int16]any)
+	if header[5].(map[int16]any)[1].(int64) != 3 {
+		t.Fatalf("unexpected page header %v", header)
+	}
+	if int64(r.pos-offset)+header[3].(int64) != chunk[7].(int64) {
+		t.Fatalf("chunk size mismatch %v", chunk)
+	}
+	page := data[r.pos : r.pos+int(header[3].(int64))]
+	// 定义级别：长度 6，RLE 1 个 1、1 个 0、1 个 1
+	wantPage := []byte{6, 0, 0, 0, 0x02, 1, 0x02, 0, 0x02, 1, 1, 0, 0, 0, 'a', 3, 0, 0, 0, 'c', 'c', 'c'}
+	if !bytes.Equal(page, wantPage) {
+		t.Fatalf("want page % x, got % x", wantPage, page)
+	}
+}
+
+// parquetGoldenRows testdata/export.parquet 的内容
+var parquetGoldenRows = [][]any{
+	{int64(1), "2018GUOZ01", 5.8},
+	{int64(2), nil, nil},
+	{nil, "郭志", 0.0},
+	{int64(-3), "", 100.25},
+}
+
+// Test_parquetWriterGolden 输出需与 testdata/export.parquet 一致，该文件可用 pyarrow.parquet.read_table 读取
+func Test_parquetWriterGolden(t *testing.T) {
+	var buf bytes.Buffer
+	p, err := newParquetWriter(&buf, []string{"id", "wca_id", "best"}, []int32{parquetInt64, parquetByteArray, parquetDouble})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range parquetGoldenRows {
+		if err = p.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("testdata/export.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("parquet output differs from testdata/export.parquet\nwant % x\ngot  % x", want, buf.Bytes())
+	}
+}
@@ -0,0 +1,452 @@
+package wca
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/wca/utils"
+	"github.com/guojia99/cubing-pro/src/wca/types"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// chinaProvinces 中国大陆省级行政区，键与 WCA 比赛城市名中的省份拼音一致（去掉空格）
+var chinaProvinces = map[string]string{
+	"Beijing": "北京", "Tianjin": "天津", "Hebei": "河北", "Shanxi": "山西", "InnerMongolia": "内蒙古",
+	"Liaoning": "辽宁", "Jilin": "吉林", "Heilongjiang": "黑龙江", "Shanghai": "上海", "Jiangsu": "江苏",
+	"Zhejiang": "浙江", "Anhui": "安徽", "Fujian": "福建", "Jiangxi": "江西", "Shandong": "山东",
+	"Henan": "河南", "Hubei": "湖北", "Hunan": "湖南", "Guangdong": "广东", "Guangxi": "广西",
+	"Hainan": "海南", "Chongqing": "重庆", "Sichuan": "四川", "Guizhou": "贵州", "Yunnan": "云南",
+	"Tibet": "西藏", "Shaanxi": "陕西", "Gansu": "甘肃", "Qinghai": "青海", "Ningxia": "宁夏",
+	"Xinjiang": "新疆",
+}
+
+var provinceAliases = map[string]string{
+	"xizang":    "Tibet",
+	"neimenggu": "InnerMongolia",
+}
+
+// normalizeProvince 将比赛城市中的省份拼音或粗饼的中文地区（如 "广东 深圳"）统一为 chinaProvinces 的键，无法识别时返回空
+func normalizeProvince(in string) string {
+	in = strings.TrimSpace(in)
+	if in == "" {
+		return ""
+	}
+
+	for _, r := range in {
+		if unicode.Is(unicode.Han, r) {
+			for key, name := range chinaProvinces {
+				if strings.HasPrefix(in, name) {
+					return key
+				}
+			}
+			return ""
+		}
+	}
+
+	l := strings.ToLower(strings.ReplaceAll(in, " ", ""))
+	out := ""
+	for key := range chinaProvinces {
+		if strings.HasPrefix(l, strings.ToLower(key)) && len(key) > len(out) {
+			out = key
+		}
+	}
+	for alias, key := range provinceAliases {
+		if out == "" && strings.HasPrefix(l, alias) {
+			out = key
+		}
+	}
+	return out
+}
+
+// 粗饼地区覆盖保存在 dbPath 下，不随 WCA 数据库快照替换
+const provinceOverrideFile = "person_provinces.json"
+
+func loadProvinceOverrides(dbPath string) map[string]string {
+	out := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(dbPath, provinceOverrideFile))
+	if err != nil {
+		return out
+	}
+	_ = jsoniter.Unmarshal(data, &out)
+	return out
+}
+
+func saveProvinceOverrides(dbPath string, overrides map[string]string) error {
+	data, err := jsoniter.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dbPath, provinceOverrideFile), data, 0644)
+}
+
+func (w *wca) SetPersonProvince(wcaId, region string) error {
+	wcaId = normalizeWcaID(wcaId)
+	province := normalizeProvince(region)
+	if province == "" {
+		return fmt.Errorf("unknown province %s", region)
+	}
+
+	var person types.Person
+	if err := w.db().Where("wca_id = ? AND sub_id = 1", wcaId).First(&person).Error; err != nil {
+		return fmt.Errorf("not found wca id %s", wcaId)
+	}
+	if person.CountryID != chinaCountryID {
+		return fmt.Errorf("%s is not a chinese competitor", wcaId)
+	}
+
+	w.provinceMu.Lock()
+	defer w.provinceMu.Unlock()
+	overrides := loadProvinceOverrides(w.dbPath)
+	if overrides[wcaId] == province {
+		return nil
+	}
+	overrides[wcaId] = province
+	if err := saveProvinceOverrides(w.dbPath, overrides); err != nil {
+		return err
+	}
+
+	// 当前快照立即生效，下次同步时重新计算
+	if w.db().Migrator().HasTable(&types.StaticPersonProvince{}) {
+		p := types.StaticPersonProvince{WcaID: wcaId}
+		w.db().Where("wca_id = ?", wcaId).Limit(1).Find(&p)
+		if p.Province != province {
+			p.CompCount = 0
+		}
+		p.Province, p.Source = province, types.ProvinceSourceCubing
+		if err := w.db().Save(&p).Error; err != nil {
+			return err
+		}
+	}
+	w.cache.Delete(provinceRankDataKey)
+	return nil
+}
+
+// provinceEntry 省内排名使用的个人最佳成绩
+type provinceEntry struct {
+	WcaID       string
+	Best        int
+	CountryRank int
+	WorldRank   int
+}
+
+type provinceRankData struct {
+	persons map[string]types.StaticPersonProvince
+	names   map[string]string
+	single  map[string][]provinceEntry // 按成绩排序
+	avg     map[string][]provinceEntry
+}
+
+const provinceRankDataKey = "provinceRankData"
+
+func (w *wca) getProvinceRankData() (*provinceRankData, error) {
+	if data, ok := w.cache.Get(provinceRankDataKey); ok {
+		return data.(*provinceRankData), nil
+	}
+
+	var persons []types.StaticPersonProvince
+	if err := w.db().Find(&persons).Error; err != nil {
+		return nil, err
+	}
+	out := &provinceRankData{
+		persons: make(map[string]types.StaticPersonProvince, len(persons)),
+		names:   make(map[string]string, len(persons)),
+		single:  make(map[string][]provinceEntry),
+		avg:     make(map[string][]provinceEntry),
+	}
+	for _, p := range persons {
+		out.persons[p.WcaID] = p
+	}
+
+	sub := w.db().Model(&types.StaticPersonProvince{}).Select("wca_id")
+	var names []types.Person
+	w.db().Select("wca_id", "name").Where("sub_id = 1 AND wca_id IN (?)", sub).Find(&names)
+	for _, p := range names {
+		out.names[p.WcaID] = p.Name
+	}
+
+	var singles []types.RanksSingle
+	if err := w.db().Where("person_id IN (?)", sub).Find(&singles).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range singles {
+		out.single[r.EventID] = append(out.single[r.EventID], provinceEntry{r.PersonID, r.Best, r.CountryRank, r.WorldRank})
+	}
+	var avgs []types.RanksAverage
+	if err := w.db().Where("person_id IN (?)", sub).Find(&avgs).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range avgs {
+		out.avg[r.EventID] = append(out.avg[r.EventID], provinceEntry{r.PersonID, r.Best, r.CountryRank, r.WorldRank})
+	}
+	for _, m := range []map[string][]provinceEntry{out.single, out.avg} {
+		for _, list := range m {
+			sort.Slice(list, func(i, j int) bool {
+				if list[i].Best != list[j].Best {
+					return list[i].Best < list[j].Best
+				}
+				return list[i].WcaID < list[j].WcaID
+			})
+		}
+	}
+
+	w.cache.Set(provinceRankDataKey, out, time.Minute*30)
+	return out, nil
+}
+
+// entries 某个项目在省内的成绩，province 为空时为全部中国选手
+func (d *provinceRankData) entries(eventId, province string, isAvg bool) []provinceEntry {
+	list := d.single[eventId]
+	if isAvg {
+		list = d.avg[eventId]
+	}
+	if province == "" {
+		return list
+	}
+	var out []provinceEntry
+	for _, e := range list {
+		if d.persons[e.WcaID].Province == province {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// ranks 成绩相同的名次相同
+func provinceRanks(list []provinceEntry) map[string]int {
+	out := make(map[string]int, len(list))
+	for idx, e := range list {
+		if idx > 0 && e.Best == list[idx-1].Best {
+			out[e.WcaID] = out[list[idx-1].WcaID]
+			continue
+		}
+		out[e.WcaID] = idx + 1
+	}
+	return out
+}
+
+func checkProvince(province string) (string, error) {
+	if province == "" {
+		return "", nil
+	}
+	if p := normalizeProvince(province); p != "" {
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown province %s", province)
+}
+
+func (w *wca) GetProvinceRank(eventId, province string, isAvg bool, page, size int) ([]types.ProvinceRank, int64, error) {
+	province, err := checkProvince(province)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := w.getProvinceRankData()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := data.entries(eventId, province, isAvg)
+	ranks := provinceRanks(entries)
+	out := make([]types.ProvinceRank, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, types.ProvinceRank{
+			Rank:        ranks[e.WcaID],
+			WcaID:       e.WcaID,
+			Name:        data.names[e.WcaID],
+			Province:    data.persons[e.WcaID].Province,
+			EventID:     eventId,
+			Best:        e.Best,
+			BestStr:     utils.ResultsTimeFormat(e.Best, eventId),
+			CountryRank: e.CountryRank,
+			WorldRank:   e.WorldRank,
+		})
+	}
+	list, count := paginate(out, page, size)
+	return list, count, nil
+}
+
+func (w *wca) GetProvinceRecords(province string) ([]types.StaticRecordHistory, error) {
+	province, err := checkProvince(province)
+	if err != nil {
+		return nil, err
+	}
+	list, err := w.GetRecordHistory("", "", types.RecordScopeProvince, province)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.StaticRecordHistory, 0)
+	for _, r := range list {
+		if r.IsCurrent {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// mbfScore 多盲得分 = 分数 + (60 分钟 - 用时) / 60 分钟
+func mbfScore(value int) float64 {
+	solved, attempted, seconds, _ := utils.Get333MBFResult(value)
+	return float64(solved-(attempted-solved)) + float64(3600-seconds)/3600
+}
+
+// kinchBestOfEvents 盲拧与最少步取单次与平均中得分高的
+var kinchBestOfEvents = map[string]bool{"333bf": true, "444bf": true, "555bf": true, "333fm": true}
+
+// GetProvinceKinch 省内 Kinch：各项目得分 = 省内最佳 / 个人成绩 × 100，普通项目使用平均，取所有项目的平均分
+func (w *wca) GetProvinceKinch(province string, page, size int) ([]types.ProvinceKinch, int64, error) {
+	province, err := checkProvince(province)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := w.getProvinceRankData()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	persons := make(map[string]*types.ProvinceKinch)
+	var events []string
+	for _, ev := range wcaEventsList {
+		singles := data.entries(ev, province, false)
+		avgs := data.entries(ev, province, true)
+
+		scores := make(map[string]float64)
+		switch {
+		case ev == "333mbf":
+			if len(singles) == 0 {
+				continue
+			}
+			best := 0.0
+			for _, e := range singles {
+				best = max(best, mbfScore(e.Best))
+			}
+			for _, e := range singles {
+				if best > 0 {
+					scores[e.WcaID] = mbfScore(e.Best) / best * 100
+				}
+			}
+		case kinchBestOfEvents[ev]:
+			if len(singles) == 0 {
+				continue
+			}
+			for _, e := range singles {
+				scores[e.WcaID] = float64(singles[0].Best) / float64(e.Best) * 100
+			}
+			for _, e := range avgs {
+				scores[e.WcaID] = max(scores[e.WcaID], float64(avgs[0].Best)/float64(e.Best)*100)
+			}
+		default:
+			if len(avgs) == 0 {
+				continue
+			}
+			for _, e := range avgs {
+				scores[e.WcaID] = float64(avgs[0].Best) / float64(e.Best) * 100
+			}
+		}
+
+		events = append(events, ev)
+		for _, e := range singles {
+			if _, ok := persons[e.WcaID]; !ok {
+				persons[e.WcaID] = &types.ProvinceKinch{
+					WcaID:    e.WcaID,
+					Name:     data.names[e.WcaID],
+					Province: data.persons[e.WcaID].Province,
+					Events:   make(map[string]float64),
+				}
+			}
+		}
+		for wcaID, score := range scores {
+			persons[wcaID].Events[ev] = score
+		}
+	}
+
+	out := make([]types.ProvinceKinch, 0, len(persons))
+	for _, p := range persons {
+		for _, score := range p.Events {
+			p.Score += score / float64(len(events))
+		}
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].WcaID < out[j].WcaID
+	})
+	for idx := range out {
+		out[idx].Rank = idx + 1
+		if idx > 0 && out[idx].Score == out[idx-1].Score {
+			out[idx].Rank = out[idx-1].Rank
+		}
+	}
+	list, count := paginate(out, page, size)
+	return list, count, nil
+}
+
+// GetProvinceSor 省内排名总和，越小越好
+func (w *wca) GetProvinceSor(province string, isAvg bool, page, size int) ([]types.ProvinceSor, int64, error) {
+	province, err := checkProvince(province)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := w.getProvinceRankData()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	type eventRanks struct {
+		ranks   map[string]int
+		missing int
+	}
+	var events []string
+	rankMap := make(map[string]eventRanks)
+	persons := make(map[string]*types.ProvinceSor)
+	for _, ev := range wcaEventsList {
+		list := data.entries(ev, province, isAvg)
+		if len(list) == 0 {
+			continue
+		}
+		events = append(events, ev)
+		rankMap[ev] = eventRanks{ranks: provinceRanks(list), missing: len(list) + 1}
+		for _, e := range list {
+			if _, ok := persons[e.WcaID]; !ok {
+				persons[e.WcaID] = &types.ProvinceSor{
+					WcaID:    e.WcaID,
+					Name:     data.names[e.WcaID],
+					Province: data.persons[e.WcaID].Province,
+					Events:   make(map[string]int),
+				}
+			}
+		}
+	}
+
+	out := make([]types.ProvinceSor, 0, len(persons))
+	for _, p := range persons {
+		for _, ev := range events {
+			rank, ok := rankMap[ev].ranks[p.WcaID]
+			if !ok {
+				rank = rankMap[ev].missing
+			}
+			p.Events[ev] = rank
+			p.Sum += rank
+		}
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Sum != out[j].Sum {
+			return out[i].Sum < out[j].Sum
+		}
+		return out[i].WcaID < out[j].WcaID
+	})
+	for idx := range out {
+		out[idx].Rank = idx + 1
+		if idx > 0 && out[idx].Sum == out[idx-1].Sum {
+			out[idx].Rank = out[idx-1].Rank
+		}
+	}
+	list, count := paginate(out, page, size)
+	return list, count, nil
+}
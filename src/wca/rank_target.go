@@ -0,0 +1,214 @@
+package wca
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/wca/utils"
+	"github.com/guojia99/cubing-pro/src/wca/types"
+	"gorm.io/gorm"
+)
+
+// rankTargetMaxPassed 最多列出的会超过的选手
+const rankTargetMaxPassed = 50
+
+var rankTargetScopes = map[string]string{
+	"world": "world", "wr": "world",
+	"continent": "continent", "cr": "continent",
+	"country": "country", "nr": "country",
+}
+
+type rankTargetRow struct {
+	PersonID  string
+	Best      int
+	ScopeRank int
+}
+
+func (w *wca) GetRankTarget(wcaId, eventId, scope string, rank int, isAvg bool) (types.RankTarget, error) {
+	wcaId = normalizeWcaID(wcaId)
+	sc, ok := rankTargetScopes[strings.ToLower(scope)]
+	if !ok {
+		return types.RankTarget{}, fmt.Errorf("unknown scope %s", scope)
+	}
+	scope = sc
+	if rank <= 0 {
+		return types.RankTarget{}, fmt.Errorf("invalid rank %d", rank)
+	}
+	// 与 GetEventRankWithFullNow 一致，多盲只有单次
+	if eventId == "333mbf" {
+		isAvg = false
+	}
+
+	var person types.Person
+	if err := w.db().Where("wca_id = ? AND sub_id = 1", wcaId).First(&person).Error; err != nil {
+		return types.RankTarget{}, fmt.Errorf("not found wca id %s", wcaId)
+	}
+
+	out := types.RankTarget{
+		WcaID:      wcaId,
+		Name:       person.Name,
+		EventID:    eventId,
+		IsAvg:      isAvg,
+		Scope:      scope,
+		TargetRank: rank,
+		Passed:     make([]types.RankTargetPerson, 0),
+	}
+	switch scope {
+	case "continent":
+		out.Region = w.GetAllCountry()[person.CountryID].ContinentID
+	case "country":
+		out.Region = person.CountryID
+	}
+
+	rankCol := scope + "_rank"
+	query := func() *gorm.DB {
+		var q *gorm.DB
+		if isAvg {
+			q = w.db().Model(&types.RanksAverage{})
+		} else {
+			q = w.db().Model(&types.RanksSingle{})
+		}
+		q = q.Select("person_id, best, "+rankCol+" AS scope_rank").Where("event_id = ?", eventId)
+
+		persons := w.db().Model(&types.Person{}).Select("wca_id").Where("sub_id = 1")
+		switch scope {
+		case "continent":
+			countries := w.db().Model(&types.Country{}).Select("id").Where("continent_id = ?", out.Region)
+			q = q.Where("person_id IN (?)", persons.Where("country_id IN (?)", countries))
+		case "country":
+			q = q.Where("person_id IN (?)", persons.Where("country_id = ?", out.Region))
+		}
+		return q
+	}
+
+	// 1. 当前成绩
+	var current []rankTargetRow
+	if err := query().Where("person_id = ?", wcaId).Scan(&current).Error; err != nil {
+		return out, err
+	}
+	if len(current) > 0 {
+		out.Current = current[0].Best
+		out.CurrentStr = utils.ResultsTimeFormat(current[0].Best, eventId)
+		out.CurrentRank = current[0].ScopeRank
+		out.Reached = current[0].ScopeRank <= rank
+	}
+
+	// 2. 目标排名的成绩，与其持平即可并列该排名；不足 N 人时任意有效成绩即可
+	var target []rankTargetRow
+	if err := query().Where(rankCol+" <= ?", rank).Order(rankCol + " DESC, best DESC").Limit(1).Scan(&target).Error; err != nil {
+		return out, err
+	}
+	if len(target) == 0 {
+		return out, nil
+	}
+	out.Required = target[0].Best
+	out.RequiredStr = utils.ResultsTimeFormat(out.Required, eventId)
+	if out.Reached {
+		return out, nil
+	}
+
+	// 3. 会超过的选手：成绩比目标差，且不比当前成绩好
+	passed := func() *gorm.DB {
+		q := query().Where("best > ? AND person_id <> ?", out.Required, wcaId)
+		if out.Current > 0 {
+			q = q.Where("best <= ?", out.Current)
+		}
+		return q
+	}
+	var rows []rankTargetRow
+	if err := passed().Count(&out.PassedCount).Error; err != nil {
+		return out, err
+	}
+	if err := passed().Order("best, person_id").Limit(rankTargetMaxPassed).Scan(&rows).Error; err != nil {
+		return out, err
+	}
+	rows = append(rows, target[0])
+
+	ids := make([]string, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.PersonID)
+	}
+	var persons []types.Person
+	w.db().Where("sub_id = 1 AND wca_id IN ?", ids).Find(&persons)
+	personMap := make(map[string]types.Person, len(persons))
+	for _, p := range persons {
+		personMap[p.WcaID] = p
+	}
+	newPerson := func(r rankTargetRow) types.RankTargetPerson {
+		return types.RankTargetPerson{
+			Rank:      r.ScopeRank,
+			WcaID:     r.PersonID,
+			Name:      personMap[r.PersonID].Name,
+			CountryID: personMap[r.PersonID].CountryID,
+			Best:      r.Best,
+			BestStr:   utils.ResultsTimeFormat(r.Best, eventId),
+		}
+	}
+	for _, r := range rows[:len(rows)-1] {
+		out.Passed = append(out.Passed, newPerson(r))
+	}
+	tp := newPerson(target[0])
+	out.TargetPerson = &tp
+
+	// 4. 平均需要的计数成绩，最少步的平均按步数 x100 记录，不做换算
+	if isAvg && eventId != "333fm" {
+		var results []types.Result
+		w.db().Where("person_id = ? AND event_id = ? AND average > 0", wcaId, eventId).Find(&results)
+		if len(results) > 0 {
+			results = w.setResultAttempts(results)
+			results = w.setCompetitionNameAndSort(results)
+			out.Counting = rankTargetCounting(results[len(results)-1], out.Required)
+		}
+	}
+	return out, nil
+}
+
+// rankTargetCounting 将最近一次平均的计数成绩等比例缩放到目标平均，得出最快与最慢的计数成绩
+func rankTargetCounting(r types.Result, required int) *types.RankTargetCounting {
+	var values []int
+	for _, a := range r.Attempts {
+		switch {
+		case a > 0:
+			values = append(values, int(a))
+		case a < 0: // DNF / DNS
+			values = append(values, math.MaxInt)
+		}
+	}
+	sort.Ints(values)
+
+	var counting []int
+	switch len(values) {
+	case 5:
+		counting = values[1:4]
+	case 3:
+		counting = values
+	default:
+		return nil
+	}
+	sum := 0
+	for _, c := range counting {
+		if c == math.MaxInt {
+			return nil
+		}
+		sum += c
+	}
+
+	// 平均四舍五入到 0.01 秒，计数成绩总和不超过 3 * 目标 + 1 即可
+	ratio := float64(3*required+1) / float64(sum)
+	best := int(float64(counting[0]) * ratio)
+	worst := int(float64(counting[len(counting)-1]) * ratio)
+	return &types.RankTargetCounting{
+		CompetitionID:    r.CompetitionID,
+		CompetitionName:  r.CompetitionName,
+		RoundTypeID:      r.RoundTypeID,
+		Attempts:         r.Attempts,
+		Average:          r.Average,
+		AverageStr:       utils.ResultsTimeFormat(r.Average, r.EventID),
+		BestCounting:     best,
+		BestCountingStr:  utils.ResultsTimeFormat(best, r.EventID),
+		WorstCounting:    worst,
+		WorstCountingStr: utils.ResultsTimeFormat(worst, r.EventID),
+	}
+}
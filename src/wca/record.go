@@ -0,0 +1,121 @@
+package wca
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/internel/database/model/wca/utils"
+	"github.com/guojia99/cubing-pro/src/wca/types"
+)
+
+var recordScopeOrder = map[string]int{
+	types.RecordScopeWorld:     0,
+	types.RecordScopeContinent: 1,
+	types.RecordScopeNational:  2,
+	types.RecordScopeProvince:  3,
+}
+
+// fillRecordHistory 填充成绩字符串，现有纪录的保持天数计算到今天
+func fillRecordHistory(list []types.StaticRecordHistory) {
+	today := time.Now().Format("2006-01-02")
+	for idx := range list {
+		list[idx].ValueStr = utils.ResultsTimeFormat(list[idx].Value, list[idx].EventID)
+		if list[idx].IsCurrent {
+			list[idx].Days = recordDays(list[idx].Date, today)
+		}
+	}
+}
+
+func sortRecordHistory(list []types.StaticRecordHistory) {
+	eventOrder := buildEventOrderMap()
+	order := func(eventID string) int {
+		if o, ok := eventOrder[eventID]; ok {
+			return o
+		}
+		return len(wcaEventsList)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.EventID != b.EventID {
+			if order(a.EventID) != order(b.EventID) {
+				return order(a.EventID) < order(b.EventID)
+			}
+			return a.EventID < b.EventID
+		}
+		if a.IsAvg != b.IsAvg {
+			return !a.IsAvg
+		}
+		if a.Scope != b.Scope {
+			return recordScopeOrder[a.Scope] < recordScopeOrder[b.Scope]
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.Seq < b.Seq
+	})
+}
+
+func (w *wca) GetRecordHistory(eventId, country, scope, region string) ([]types.StaticRecordHistory, error) {
+	scope = strings.ToUpper(strings.TrimSpace(scope))
+	if _, ok := recordScopeOrder[scope]; scope != "" && !ok {
+		return nil, fmt.Errorf("unknown record scope %s", scope)
+	}
+
+	query := w.db().Model(&types.StaticRecordHistory{})
+	if eventId != "" {
+		query = query.Where("event_id = ?", eventId)
+	}
+	if country != "" {
+		query = query.Where("country = ?", w.getCountryID(country))
+	}
+	if scope != "" {
+		query = query.Where("scope = ?", scope)
+	}
+	if region != "" {
+		if scope == types.RecordScopeNational {
+			region = w.getCountryID(region)
+		}
+		query = query.Where("region = ?", region)
+	}
+
+	var out []types.StaticRecordHistory
+	if err := query.Find(&out).Error; err != nil {
+		return nil, err
+	}
+	sortRecordHistory(out)
+	fillRecordHistory(out)
+	return out, nil
+}
+
+func (w *wca) GetCurrentRecords(country string) (types.CurrentRecords, error) {
+	countryID := w.getCountryID(country)
+	if countryID == "" {
+		return types.CurrentRecords{}, fmt.Errorf("country is empty")
+	}
+
+	var list []types.StaticRecordHistory
+	if err := w.db().Where("is_current = ?", true).
+		Where("(scope = ? AND region = ?) OR (scope = ? AND country = ?)",
+			types.RecordScopeNational, countryID, types.RecordScopeProvince, countryID).
+		Find(&list).Error; err != nil {
+		return types.CurrentRecords{}, err
+	}
+	sortRecordHistory(list)
+	fillRecordHistory(list)
+
+	out := types.CurrentRecords{
+		Country:   countryID,
+		National:  make([]types.StaticRecordHistory, 0),
+		Provinces: make(map[string][]types.StaticRecordHistory),
+	}
+	for _, r := range list {
+		if r.Scope == types.RecordScopeNational {
+			out.National = append(out.National, r)
+			continue
+		}
+		out.Provinces[r.Region] = append(out.Provinces[r.Region], r)
+	}
+	return out, nil
+}
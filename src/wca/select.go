@@ -15,7 +15,7 @@ func (w *wca) getRoundTypeMap() map[string]types.RoundType {
 	var out = make(map[string]types.RoundType)
 
 	var rounds []types.RoundType
-	w.db.Find(&rounds)
+	w.db().Find(&rounds)
 
 	for _, round := range rounds {
 		out[round.ID] = round
@@ -29,7 +29,7 @@ func (w *wca) getCountryPersons(country string) []types.Person {
 	}
 
 	country = w.getCountryID(country)
-	query := w.db.Model(&types.Person{})
+	query := w.db().Model(&types.Person{})
 	if country != "" {
 		query.Where("country_id = ?", country)
 	}
@@ -43,7 +43,7 @@ func (w *wca) getCountryPersons(country string) []types.Person {
 
 func (w *wca) CountryList() []types.Country {
 	var out []types.Country
-	w.db.Find(&out)
+	w.db().Find(&out)
 	return out
 }
 
@@ -57,7 +57,7 @@ func (w *wca) GetAllCountry() map[string]types.Country {
 	var out = make(map[string]types.Country)
 
 	var list []types.Country
-	w.db.Find(&list)
+	w.db().Find(&list)
 
 	for _, country := range list {
 		out[country.ID] = country
@@ -68,23 +68,24 @@ func (w *wca) GetAllCountry() map[string]types.Country {
 }
 
 func (w *wca) GetPersonInfo(wcaId string) (types.PersonInfo, error) {
+	wcaId = normalizeWcaID(wcaId)
 	var person types.Person
-	if err := w.db.Where("wca_id = ?", wcaId).First(&person).Error; err != nil {
+	if err := w.db().Where("wca_id = ?", wcaId).First(&person).Error; err != nil {
 		return types.PersonInfo{}, fmt.Errorf("not found wca id %s", wcaId)
 	}
 
 	var results []types.Result
-	if err := w.db.Where("person_id = ?", wcaId).Find(&results).Error; err != nil {
+	if err := w.db().Where("person_id = ?", wcaId).Find(&results).Error; err != nil {
 		return types.PersonInfo{}, err
 	}
 
 	var bestRanks []types.RanksSingle
 	var avgRanks []types.RanksAverage
 
-	if err := w.db.Where("person_id = ?", wcaId).Find(&bestRanks).Error; err != nil {
+	if err := w.db().Where("person_id = ?", wcaId).Find(&bestRanks).Error; err != nil {
 		return types.PersonInfo{}, err
 	}
-	if err := w.db.Where("person_id = ?", wcaId).Find(&avgRanks).Error; err != nil {
+	if err := w.db().Where("person_id = ?", wcaId).Find(&avgRanks).Error; err != nil {
 		return types.PersonInfo{}, err
 	}
 
@@ -210,7 +211,7 @@ func (w *wca) GetPersonInfo(wcaId string) (types.PersonInfo, error) {
 
 	// 比赛地址
 	var comps []types.Competition
-	w.db.Where("id in ?", compIds).Find(&comps)
+	w.db().Where("id in ?", compIds).Find(&comps)
 
 	var geoMap = make(map[string]*types.PersonInfoGeo)
 	for _, comp := range comps {
@@ -225,16 +226,7 @@ func (w *wca) GetPersonInfo(wcaId string) (types.PersonInfo, error) {
 		}
 
 		if slices.Contains([]string{"China"}, comp.CountryID) {
-			geo.Province = comp.CityName
-			if strings.Contains(comp.CityName, ",") {
-				cty := strings.ReplaceAll(comp.CityName, "Province", "")
-				cty = strings.ReplaceAll(cty, " ", "")
-				sl := strings.Split(cty, ",")
-				if len(sl) == 2 {
-					geo.City = sl[0]
-					geo.Province = sl[1]
-				}
-			}
+			geo.City, geo.Province = chinaCityProvince(comp.CityName)
 		}
 
 		if slices.Contains([]string{"Taiwan", "Hong Kong", "Macau"}, comp.CountryID) {
@@ -265,15 +257,40 @@ func (w *wca) ExportToTable(filePath string) error {
 	panic("implement me")
 }
 
+// setCompetitionInfo 填充比赛的国家代码、项目列表与起止日期
+func (w *wca) setCompetitionInfo(comps []types.Competition) {
+	cts := w.GetAllCountry()
+	for idx := 0; idx < len(comps); idx++ {
+		cp := comps[idx]
+		comps[idx].CountryIso2 = cts[cp.CountryID].ISO2
+		comps[idx].EventIds = strings.Split(cp.EventSpecs, " ")
+		comps[idx].StartDate = time.Date(int(cp.Year), time.Month(cp.Month), int(cp.Day), 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+		comps[idx].EndDate = time.Date(int(cp.EndYear), time.Month(cp.EndMonth), int(cp.EndDay), 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	}
+}
+
 func (w *wca) GetCompetition(compId string) (types.Competition, error) {
-	//TODO implement me
-	panic("implement me")
+	compId = strings.TrimSpace(compId)
+	var comps []types.Competition
+	// 比赛 ID 区分大小写（如 ChinaOpen2024），机器人消息会被转为小写
+	if err := w.db().Where("id = ?", compId).Limit(1).Find(&comps).Error; err != nil {
+		return types.Competition{}, err
+	}
+	if len(comps) == 0 {
+		w.db().Where("LOWER(id) = ?", strings.ToLower(compId)).Limit(1).Find(&comps)
+	}
+	if len(comps) == 0 {
+		return types.Competition{}, fmt.Errorf("not found competition %s", compId)
+	}
+	w.setCompetitionInfo(comps)
+	return comps[0], nil
 }
 
 func (w *wca) GetPersonCompetition(wcaId string) ([]types.Competition, error) {
+	wcaId = normalizeWcaID(wcaId)
 	var out []types.Result
 
-	if err := w.db.Where("person_id = ?", wcaId).Find(&out).Error; err != nil {
+	if err := w.db().Where("person_id = ?", wcaId).Find(&out).Error; err != nil {
 		return nil, err
 	}
 
@@ -289,24 +306,11 @@ func (w *wca) GetPersonCompetition(wcaId string) ([]types.Competition, error) {
 	}
 
 	var comps []types.Competition
-	if err := w.db.Where("id IN (?)", compIds).Find(&comps).Error; err != nil {
+	if err := w.db().Where("id IN (?)", compIds).Find(&comps).Error; err != nil {
 		return nil, err
 	}
 
-	var country []types.Country
-	w.db.Find(&country)
-	var countryMap = make(map[string]types.Country)
-	for _, r := range country {
-		countryMap[r.Name] = r
-	}
-
-	for idx := 0; idx < len(comps); idx++ {
-		cp := comps[idx]
-		comps[idx].CountryIso2 = countryMap[comps[idx].CountryID].ISO2
-		comps[idx].EventIds = strings.Split(comps[idx].EventSpecs, " ")
-		comps[idx].StartDate = time.Date(int(cp.Year), time.Month(cp.Month), int(cp.Day), 0, 0, 0, 0, time.UTC).Format("2006-01-02")
-		comps[idx].EndDate = time.Date(int(cp.EndYear), time.Month(cp.EndMonth), int(cp.EndDay), 0, 0, 0, 0, time.UTC).Format("2006-01-02")
-	}
+	w.setCompetitionInfo(comps)
 
 	sort.Slice(comps, func(i, j int) bool {
 		if comps[i].EndYear != comps[j].EndYear {
@@ -336,7 +340,7 @@ func (w *wca) getResultAttemptMap(results []types.Result) map[int64][]int64 {
 		batchIDs := resultIDs[i:end]
 
 		var batchAtt []types.ResultAttempt
-		if err := w.db.Where("result_id IN ?", batchIDs).Find(&batchAtt).Error; err != nil {
+		if err := w.db().Where("result_id IN ?", batchIDs).Find(&batchAtt).Error; err != nil {
 			continue
 		}
 		sort.Slice(batchAtt, func(i, j int) bool {
@@ -418,7 +422,7 @@ func (w *wca) setCompetitionNameAndSort(results []types.Result) []types.Result {
 	}
 
 	var comps []types.Competition
-	w.db.Where("id in ?", compID).Find(&comps)
+	w.db().Where("id in ?", compID).Find(&comps)
 	for _, comp := range comps {
 		compMap[comp.ID] = comp
 	}
@@ -486,9 +490,10 @@ func (w *wca) setCompetitionNameAndSort(results []types.Result) []types.Result {
 }
 
 func (w *wca) GetPersonResult(wcaId string) ([]types.Result, error) {
+	wcaId = normalizeWcaID(wcaId)
 	var out []types.Result
 
-	if err := w.db.Where("person_id = ?", wcaId).Find(&out).Error; err != nil {
+	if err := w.db().Where("person_id = ?", wcaId).Find(&out).Error; err != nil {
 		return nil, err
 	}
 
@@ -507,7 +512,7 @@ func (w *wca) GetAllPersons() []types.Person {
 	}
 
 	var out []types.Person
-	if err := w.db.Where("sub_id = 1").Find(&out).Error; err != nil {
+	if err := w.db().Where("sub_id = 1").Find(&out).Error; err != nil {
 		return nil
 	}
 
@@ -545,11 +550,16 @@ func (w *wca) SearchPlayers(query string) []types.Person {
 
 func (w *wca) GetGrandSlam() []types.AllEventChampionshipsPodium {
 	var out []types.AllEventChampionshipsPodium
-	w.db.Find(&out)
+	w.db().Find(&out)
 	return out
 }
 
 func rankWithEventsPaginate(fullList []types.RankWithEventsStatic, page, size int) ([]types.RankWithEventsStatic, int64) {
+	return paginate(fullList, page, size)
+}
+
+// paginate 内存分页，page 从 1 开始
+func paginate[T any](fullList []T, page, size int) ([]T, int64) {
 	count := int64(len(fullList))
 	start := (page - 1) * size
 	if start < 0 {
@@ -609,13 +619,13 @@ func (w *wca) rankWithEventsLoadRanks(wcaIDs, events []string, avg bool, useWorl
 		batch := wcaIDs[i:end]
 		if avg {
 			var rows []types.RanksAverage
-			if err := w.db.Where("person_id IN ? AND event_id IN ?", batch, events).Find(&rows).Error; err != nil {
+			if err := w.db().Where("person_id IN ? AND event_id IN ?", batch, events).Find(&rows).Error; err != nil {
 				return nil, err
 			}
 			addFromRows(rows)
 		} else {
 			var rows []types.RanksSingle
-			if err := w.db.Where("person_id IN ? AND event_id IN ?", batch, events).Find(&rows).Error; err != nil {
+			if err := w.db().Where("person_id IN ? AND event_id IN ?", batch, events).Find(&rows).Error; err != nil {
 				return nil, err
 			}
 			addFromRows(rows)
@@ -758,8 +768,8 @@ func (w *wca) cacheSingleAndAverageRank() map[string]*personRank {
 
 	var singles []types.RanksSingle
 	var avgs []types.RanksAverage
-	w.db.Find(&singles)
-	w.db.Find(&avgs)
+	w.db().Find(&singles)
+	w.db().Find(&avgs)
 
 	var out = make(map[string]*personRank)
 
@@ -813,9 +823,10 @@ func (w *wca) getSingleAndAverageRanks(country string) ([]types.RanksSingle, []t
 
 // // getCountryBestWithEventGroupRankOnlyCountry 国家现场算
 func (w *wca) getCountryBestWithEventGroupRankOnlyCountry(wcaId string, avg bool) (out []types.RankWithEventsGrouptatic, err error) {
+	wcaId = normalizeWcaID(wcaId)
 
 	var person types.Person
-	if err = w.db.Where("wca_id = ?", wcaId).Where("sub_id = 1").First(&person).Error; err != nil {
+	if err = w.db().Where("wca_id = ?", wcaId).Where("sub_id = 1").First(&person).Error; err != nil {
 		return nil, err
 	}
 
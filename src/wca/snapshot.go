@@ -0,0 +1,71 @@
+package wca
+
+import (
+	"strings"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/robot/qq_bot/Better-Bot-Go/log"
+	"github.com/guojia99/cubing-pro/src/wca/types"
+	"gorm.io/gorm"
+)
+
+// oldDbCloseDelay 切换快照后旧连接延迟关闭，保证切换前开始的查询可以执行完
+const oldDbCloseDelay = 10 * time.Minute
+
+// dbSnapshot 正在使用的 WCA 数据库快照，切换时整体替换
+type dbSnapshot struct {
+	db         *gorm.DB
+	name       string // wca_YYYYMMDD
+	switchedAt time.Time
+}
+
+// db 当前快照的连接，尚未加载时为 nil
+func (w *wca) db() *gorm.DB {
+	if sp := w.snapshot.Load(); sp != nil {
+		return sp.db
+	}
+	return nil
+}
+
+// switchDB 原子替换当前快照并清空查询缓存，旧连接延迟关闭
+func (w *wca) switchDB(db *gorm.DB, name string) {
+	old := w.snapshot.Swap(&dbSnapshot{db: db, name: name, switchedAt: time.Now()})
+	w.cache.Flush()
+	if old == nil || old.db == db {
+		log.Infof("use wca db %s", name)
+		return
+	}
+
+	log.Infof("switch wca db %s -> %s", old.name, name)
+	w.setStatus(func(st *types.WcaDBStatus) { st.PreviousDB = old.name })
+	time.AfterFunc(oldDbCloseDelay, func() { closeDB(old.db) })
+}
+
+func (w *wca) setStatus(fn func(st *types.WcaDBStatus)) {
+	w.statusMu.Lock()
+	defer w.statusMu.Unlock()
+	fn(&w.status)
+}
+
+func (w *wca) Status() types.WcaDBStatus {
+	w.statusMu.Lock()
+	out := w.status
+	w.statusMu.Unlock()
+
+	out.Driver = w.driver
+	if sp := w.snapshot.Load(); sp != nil {
+		switchedAt := sp.switchedAt
+		out.CurrentDB = sp.name
+		out.SwitchedAt = &switchedAt
+		if t, err := time.Parse("20060102", strings.TrimPrefix(sp.name, "wca_")); err == nil {
+			out.SnapshotDate = t.Format("2006-01-02")
+		}
+	}
+	return out
+}
+
+func closeDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+}
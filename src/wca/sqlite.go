@@ -13,6 +13,31 @@ import (
 
 const batchSize = 1024 // 每批处理行数，可调
 
+// wcaTable WCA 导出中的一张数据表
+type wcaTable struct {
+	name string
+	new  func() interface{} // 用于获取空实例
+}
+
+// wcaTables WCA 导出包含的所有数据表，按导入顺序排列
+var wcaTables = []wcaTable{
+	{"championships", func() interface{} { return &types.Championship{} }},
+	{"competitions", func() interface{} { return &types.Competition{} }},
+	{"continents", func() interface{} { return &types.Continent{} }},
+	{"countries", func() interface{} { return &types.Country{} }},
+	{"eligible_country_iso2s_for_championship", func() interface{} { return &types.EligibleCountryISO2ForChampionship{} }},
+	{"events", func() interface{} { return &types.Event{} }},
+	{"formats", func() interface{} { return &types.Format{} }},
+	{"persons", func() interface{} { return &types.Person{} }},
+	{"ranks_average", func() interface{} { return &types.RanksAverage{} }},
+	{"ranks_single", func() interface{} { return &types.RanksSingle{} }},
+	{"result_attempts", func() interface{} { return &types.ResultAttempt{} }},
+	{"results", func() interface{} { return &types.Result{} }},
+	{"round_types", func() interface{} { return &types.RoundType{} }},
+	{"schema_migrations", func() interface{} { return &types.SchemaMigration{} }},
+	{"scrambles", func() interface{} { return &types.Scramble{} }},
+}
+
 // ExportToSqlite 将 MySQL 中的 WCA 数据分页导出到 SQLite 文件，并实时显示进度
 func (w *wca) ExportToSqlite(sqlitePath string) error {
 	_ = os.Remove(sqlitePath)
@@ -23,52 +48,14 @@ func (w *wca) ExportToSqlite(sqlitePath string) error {
 	}
 
 	// 先创建所有表结构
-	models := []interface{}{
-		&types.Championship{},
-		&types.Competition{},
-		&types.Continent{},
-		&types.Country{},
-		&types.EligibleCountryISO2ForChampionship{},
-		&types.Event{},
-		&types.Format{},
-		&types.Person{},
-		&types.RanksAverage{},
-		&types.RanksSingle{},
-		&types.ResultAttempt{},
-		&types.Result{},
-		&types.RoundType{},
-		&types.SchemaMigration{},
-		&types.Scramble{},
-	}
-	for _, m := range models {
-		if err := sqliteDB.AutoMigrate(m); err != nil {
+	for _, tbl := range wcaTables {
+		if err := sqliteDB.AutoMigrate(tbl.new()); err != nil {
 			return fmt.Errorf("auto migrate: %w", err)
 		}
 	}
 
 	// 按顺序迁移每张表（带进度）
-	tables := []struct {
-		name string
-		new  func() interface{} // 用于获取空实例
-	}{
-		{"championships", func() interface{} { return &types.Championship{} }},
-		{"competitions", func() interface{} { return &types.Competition{} }},
-		{"continents", func() interface{} { return &types.Continent{} }},
-		{"countries", func() interface{} { return &types.Country{} }},
-		{"eligible_country_iso2s_for_championship", func() interface{} { return &types.EligibleCountryISO2ForChampionship{} }},
-		{"events", func() interface{} { return &types.Event{} }},
-		{"formats", func() interface{} { return &types.Format{} }},
-		{"persons", func() interface{} { return &types.Person{} }},
-		{"ranks_average", func() interface{} { return &types.RanksAverage{} }},
-		{"ranks_single", func() interface{} { return &types.RanksSingle{} }},
-		{"result_attempts", func() interface{} { return &types.ResultAttempt{} }},
-		{"results", func() interface{} { return &types.Result{} }},
-		{"round_types", func() interface{} { return &types.RoundType{} }},
-		{"schema_migrations", func() interface{} { return &types.SchemaMigration{} }},
-		{"scrambles", func() interface{} { return &types.Scramble{} }},
-	}
-
-	for _, tbl := range tables {
+	for _, tbl := range wcaTables {
 		fmt.Printf("📦 Migrating %s...\n", tbl.name)
 		if err = w.migrateTable(sqliteDB, tbl.name, tbl.new()); err != nil {
 			return fmt.Errorf("migrate table %s: %w", tbl.name, err)
@@ -95,7 +82,7 @@ func (w *wca) migrateTable(db *gorm.DB, tableName string, emptyModel interface{}
 
 	// 3. 全量查询 MySQL
 	fmt.Printf("  ➤ Reading all records from MySQL table '%s'...\n", tableName)
-	if err := w.db.Find(slicePtr.Interface()).Error; err != nil {
+	if err := w.db().Find(slicePtr.Interface()).Error; err != nil {
 		return fmt.Errorf("failed to read %s: %w", tableName, err)
 	}
 
@@ -1,3 +1,430 @@
 package wca
 
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/wca/types"
+	"github.com/patrickmn/go-cache"
+)
+
 const p = "root@tcp(127.0.0.1:33036)/wcav2?charset=utf8&parseTime=True&loc=Local"
+
+const fixtureDbName = "wca_20250101"
+
+// newFixtureWCA 将 testdata/wca_export 下的 TSV 导入 SQLite，返回使用该库的 wca 与 syncer
+func newFixtureWCA(t *testing.T) (*wca, *syncer) {
+	entries, err := os.ReadDir("testdata/wca_export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join("testdata/wca_export", entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[entry.Name()] = string(data)
+	}
+
+	dbPath := t.TempDir()
+	db, err := openWcaDB(wcaDriverSqlite, "", dbPath, fixtureDbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { closeDB(db) })
+	if _, err = ImportTSVExport(db, writeTSVExport(t, files), TSVImportOptions{Progress: func(TSVImportProgress) {}}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &syncer{Driver: wcaDriverSqlite, DbPath: dbPath, currentDB: fixtureDbName, db: db}
+	if err = s.syncAddIndex(fixtureDbName, syncWcaDbIndex); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &wca{
+		driver: wcaDriverSqlite,
+		dbPath: dbPath,
+		cache:  cache.New(5*time.Minute, 10*time.Minute),
+	}
+	w.switchDB(db, fixtureDbName)
+	return w, s
+}
+
+func Test_sqliteIndexStatements(t *testing.T) {
+	statements, err := sqliteIndexStatements(syncWcaDbIndex + setStaticPersonRankWithTimerIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statements[0] != "CREATE INDEX IF NOT EXISTS persons_idx_wca_id ON persons (wca_id)" {
+		t.Fatalf("unexpected first statement %s", statements[0])
+	}
+	seen := make(map[string]bool)
+	for _, stmt := range statements {
+		if seen[stmt] {
+			t.Fatalf("duplicate statement %s", stmt)
+		}
+		seen[stmt] = true
+	}
+
+	if _, err = sqliteIndexStatements("DROP TABLE persons;"); err == nil {
+		t.Fatal("want error for unsupported statement")
+	}
+}
+
+func Test_sqliteWCA_Person(t *testing.T) {
+	w, _ := newFixtureWCA(t)
+
+	info, err := w.GetPersonInfo("2019wany36")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.CountryIso2 != "CN" || info.CompetitionCount != 2 || info.RecordCount.World != 2 || info.RecordCount.Continental != 4 {
+		t.Fatalf("unexpected person info %+v", info)
+	}
+	if pr := info.PersonalRecords["333"]; pr.Best == nil || pr.Best.Best != 390 || pr.Avg == nil || pr.Avg.Best != 460 {
+		t.Fatalf("unexpected personal records %+v", info.PersonalRecords)
+	}
+
+	results, err := w.GetPersonResult("2019WANY36")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[0].CompetitionID != "WC2023" || results[1].RoundTypeID != "1" || results[2].RoundTypeID != "f" {
+		t.Fatalf("unexpected results order %+v", results)
+	}
+	if len(results[2].Attempts) != 5 || results[2].BestIndex != 0 || results[2].WorstIndex != 4 {
+		t.Fatalf("unexpected attempts %+v", results[2])
+	}
+
+	comps, err := w.GetPersonCompetition("2018GUOZ01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comps) != 2 || comps[0].ID != "WC2023" || comps[1].StartDate != "2024-12-06" || comps[1].CountryIso2 != "CN" {
+		t.Fatalf("unexpected competitions %+v", comps)
+	}
+
+	if got := w.SearchPlayers("郭"); len(got) != 1 || got[0].WcaID != "2018GUOZ01" {
+		t.Fatalf("unexpected search result %+v", got)
+	}
+}
+
+func Test_sqliteWCA_EventRank(t *testing.T) {
+	w, _ := newFixtureWCA(t)
+
+	if got := w.getCountryID("cn"); got != "China" {
+		t.Fatalf("want China, got %s", got)
+	}
+	if got := len(w.CountryList()); got != 4 {
+		t.Fatalf("want 4 countries, got %d", got)
+	}
+
+	singles, total, err := w.GetEventRankWithFullNow("333", "cn", false, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 分页结果会按比赛时间与轮次重新排序
+	if total != 6 || len(singles) != 2 || singles[0].Best+singles[1].Best != 790 {
+		t.Fatalf("unexpected single ranks %d %+v", total, singles)
+	}
+
+	avgs, total, err := w.GetEventRankWithFullNow("333", "", true, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 7 || len(avgs) != 7 || avgs[6].CompetitionName != "China Open 2024" {
+		t.Fatalf("unexpected average ranks %d %+v", total, avgs)
+	}
+
+	yearAvgs, total, err := w.GetEventRankWithOnlyYear("333", "", 2024, true, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || yearAvgs[0].Average+yearAvgs[1].Average != 1110 {
+		t.Fatalf("unexpected year ranks %d %+v", total, yearAvgs)
+	}
+
+	ranks, count, err := w.GetRankWithEvents([]string{"333"}, "", false, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 || ranks[0].WcaID != "2019WANY36" || ranks[3].WcaID != "2018GUOZ01" {
+		t.Fatalf("unexpected event ranks %+v", ranks)
+	}
+}
+
+func Test_sqliteWCA_Statics(t *testing.T) {
+	w, s := newFixtureWCA(t)
+
+	if err := s.setStaticSuccessRateResult(); err != nil {
+		t.Fatal(err)
+	}
+	rates, count, err := w.GetEventSuccessRateResult("333bf", "", 1, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || rates[0].WcaID != "2012PARK03" || rates[0].Solved != 2 || rates[0].Attempted != 3 {
+		t.Fatalf("unexpected success rate %+v", rates)
+	}
+
+	if err = s.setStaticPersonRankWithTimers(); err != nil {
+		t.Fatal(err)
+	}
+	timerRanks, total, err := w.GetEventRankWithTimer("333", "", 2024, false, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 4 || timerRanks[0].WcaID != "2019WANY36" || timerRanks[0].Single != 390 || timerRanks[0].WcaName == "" {
+		t.Fatalf("unexpected timer ranks %d %+v", total, timerRanks)
+	}
+	if _, total, _ = w.GetEventRankWithTimer("333", "CN", 2024, true, 1, 10); total != 2 {
+		t.Fatalf("want 2 China average ranks, got %d", total)
+	}
+
+	best, err := w.GetPersonBestRanks("2018guoz01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best.WithNR.Best["333"].CountryRank != 2 || best.WithWR.Avg["333"].WorldRank != 3 {
+		t.Fatalf("unexpected best ranks %+v", best)
+	}
+}
+
+func Test_sqliteWCA_CompetitionResults(t *testing.T) {
+	w, _ := newFixtureWCA(t)
+
+	if _, err := w.GetCompetitionResults("NotExists2024"); err == nil {
+		t.Fatal("want error for unknown competition")
+	}
+
+	out, err := w.GetCompetitionResults("chinaopen2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Competition.ID != "ChinaOpen2024" || out.Competition.CountryIso2 != "CN" || out.Competition.EndDate != "2024-12-08" {
+		t.Fatalf("unexpected competition %+v", out.Competition)
+	}
+	if len(out.Rounds) != 2 || out.Rounds[0].RoundTypeID != "1" || out.Rounds[1].RoundName != "Final" || !out.Rounds[1].Final {
+		t.Fatalf("unexpected rounds %+v", out.Rounds)
+	}
+	final := out.Rounds[1].Results
+	if len(final) != 2 || final[0].PersonID != "2019WANY36" || len(final[0].Attempts) != 5 {
+		t.Fatalf("unexpected final %+v", final)
+	}
+	if len(out.Records) != 4 || out.Records[2].Record != "WR" || out.Records[3].ResultStr != "4.60" || !out.Records[3].IsAvg {
+		t.Fatalf("unexpected records %+v", out.Records)
+	}
+
+	out, err = w.GetCompetitionResults("WC2023")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 三盲决赛第二名没有有效成绩，不上领奖台
+	if len(out.Podiums) != 2 || out.Podiums[0].EventID != "333" || len(out.Podiums[0].Results) != 3 ||
+		out.Podiums[0].Results[0].PersonID != "2019WANY36" || len(out.Podiums[1].Results) != 1 {
+		t.Fatalf("unexpected podiums %+v", out.Podiums)
+	}
+}
+
+func Test_sqliteWCA_RecordHistory(t *testing.T) {
+	w, s := newFixtureWCA(t)
+	if err := s.setStaticRecordHistory(); err != nil {
+		t.Fatal(err)
+	}
+
+	asr, err := w.GetRecordHistory("333", "", "cr", "_Asia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 单次 4.50 -> 4.00 -> 3.90，平均 4.80 -> 4.70 -> 4.60
+	if len(asr) != 6 || asr[0].IsAvg || asr[0].Value != 450 || asr[0].EndDate != "2024-12-06" || asr[0].Days != 482 ||
+		asr[1].RoundTypeID != "1" || asr[2].Record != "WR" || !asr[2].IsCurrent || asr[2].ValueStr != "3.90" || asr[5].Value != 460 {
+		t.Fatalf("unexpected continental history %+v", asr)
+	}
+
+	if wr, _ := w.GetRecordHistory("", "", "WR", ""); len(wr) != 2 || wr[0].CompetitionName != "China Open 2024" {
+		t.Fatalf("unexpected world records %+v", wr)
+	}
+	if pl, _ := w.GetRecordHistory("", "PL", "", ""); len(pl) != 4 || pl[0].Scope != "CR" || pl[0].Region != "_Europe" || pl[1].Region != "Poland" {
+		t.Fatalf("unexpected Poland records %+v", pl)
+	}
+	if _, err = w.GetRecordHistory("", "", "XR", ""); err == nil {
+		t.Fatal("want error for unknown scope")
+	}
+
+	cur, err := w.GetCurrentRecords("cn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur.Country != "China" || len(cur.National) != 2 || cur.National[0].Value != 390 || cur.National[1].Value != 460 {
+		t.Fatalf("unexpected national records %+v", cur.National)
+	}
+	// 郭志与王艺衡都只在北京参赛过；省纪录从世锦赛开始计算，三盲没有有效成绩
+	bj := cur.Provinces["Beijing"]
+	if len(cur.Provinces) != 1 || len(bj) != 2 || bj[0].WcaID != "2019WANY36" || bj[0].Value != 390 {
+		t.Fatalf("unexpected province records %+v", cur.Provinces)
+	}
+	if pr, _ := w.GetRecordHistory("333", "", "PR", "Beijing"); len(pr) != 6 || pr[0].CompetitionID != "WC2023" || pr[0].Value != 450 {
+		t.Fatalf("unexpected province history %+v", pr)
+	}
+}
+
+func Test_sqliteWCA_Province(t *testing.T) {
+	w, s := newFixtureWCA(t)
+	if err := s.setStaticPersonProvinces(); err != nil {
+		t.Fatal(err)
+	}
+
+	ranks, total, err := w.GetProvinceRank("333", "beijing", true, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || ranks[0].WcaID != "2019WANY36" || ranks[0].BestStr != "4.60" || ranks[1].Rank != 2 || ranks[1].Province != "Beijing" {
+		t.Fatalf("unexpected province ranks %+v", ranks)
+	}
+	if _, _, err = w.GetProvinceRank("333", "Atlantis", false, 1, 10); err == nil {
+		t.Fatal("want error for unknown province")
+	}
+
+	kinch, _, err := w.GetProvinceKinch("北京", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 只有三阶有成绩，郭志 4.60 / 6.50
+	if len(kinch) != 2 || kinch[0].Score != 100 || int(kinch[1].Score*100) != 7076 {
+		t.Fatalf("unexpected province kinch %+v", kinch)
+	}
+	if sor, _, _ := w.GetProvinceSor("", false, 1, 10); len(sor) != 2 || sor[0].Sum != 1 || sor[1].Events["333"] != 2 {
+		t.Fatalf("unexpected province sor %+v", sor)
+	}
+	if records, _ := w.GetProvinceRecords("Beijing"); len(records) != 0 {
+		t.Fatalf("record history not built, want no records, got %+v", records)
+	}
+
+	// 粗饼地区覆盖比赛城市推断，并在下次同步时保留
+	if err = w.SetPersonProvince("2018guoz01", "广东 深圳"); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.SetPersonProvince("2012PARK03", "广东"); err == nil {
+		t.Fatal("want error for non-chinese competitor")
+	}
+	if ranks, total, _ = w.GetProvinceRank("333", "Guangdong", false, 1, 10); total != 1 || ranks[0].WcaID != "2018GUOZ01" || ranks[0].Rank != 1 {
+		t.Fatalf("unexpected ranks after override %+v", ranks)
+	}
+	provinces := s.getChinaPersonProvinces()
+	if p := provinces["2018GUOZ01"]; p.Province != "Guangdong" || p.Source != types.ProvinceSourceCubing {
+		t.Fatalf("override not kept %+v", p)
+	}
+	if p := provinces["2019WANY36"]; p.Province != "Beijing" || p.Source != types.ProvinceSourceCompetition || p.CompCount != 1 {
+		t.Fatalf("unexpected competition province %+v", p)
+	}
+}
+
+func Test_sqliteWCA_RankTarget(t *testing.T) {
+	w, _ := newFixtureWCA(t)
+
+	// 郭志三阶平均 6.50 世界第 3，达到第 1 需要 4.60 并超过 Max Park
+	got, err := w.GetRankTarget("2018GUOZ01", "333", "world", 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Reached || got.CurrentRank != 3 || got.Required != 460 || got.TargetPerson == nil || got.TargetPerson.WcaID != "2019WANY36" ||
+		got.PassedCount != 1 || len(got.Passed) != 1 || got.Passed[0].Name != "Max Park" {
+		t.Fatalf("unexpected rank target %+v", got)
+	}
+	// 最近一次有效平均为中国公开赛初赛 6.40 / 6.50 / 6.60
+	if c := got.Counting; c == nil || c.CompetitionID != "ChinaOpen2024" || c.BestCounting != 453 || c.WorstCounting != 467 {
+		t.Fatalf("unexpected counting %+v", got.Counting)
+	}
+
+	if got, _ = w.GetRankTarget("2018GUOZ01", "333", "NR", 1, false); got.Region != "China" || got.RequiredStr != "3.90" || got.PassedCount != 0 || got.Counting != nil {
+		t.Fatalf("unexpected national target %+v", got)
+	}
+	if got, _ = w.GetRankTarget("2019WANY36", "333", "continent", 1, true); !got.Reached || got.Region != "_Asia" || got.TargetPerson != nil {
+		t.Fatalf("unexpected reached target %+v", got)
+	}
+	// 亚洲没有三盲成绩，任意有效成绩即可
+	if got, _ = w.GetRankTarget("2018GUOZ01", "333bf", "continent", 1, false); got.Required != 0 || got.Reached {
+		t.Fatalf("unexpected empty target %+v", got)
+	}
+	if _, err = w.GetRankTarget("2018GUOZ01", "333", "galaxy", 1, false); err == nil {
+		t.Fatal("want error for unknown scope")
+	}
+}
+
+func Test_sqliteWCA_ComparePersons(t *testing.T) {
+	w, _ := newFixtureWCA(t)
+
+	cmp, err := w.ComparePersons("2019wany36", "2018GUOZ01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmp.Persons[0].Iso2 != "CN" || len(cmp.Events) != 1 || cmp.Score != [2]int{2, 0} {
+		t.Fatalf("unexpected compare %+v", cmp)
+	}
+	if s := cmp.Events[0].Single; s.Winner != 1 || s.DiffStr != "1.90" || s.CountryRanks != [2]int{1, 2} {
+		t.Fatalf("unexpected single compare %+v", s)
+	}
+	// 世锦赛决赛 + 中国公开赛初赛、决赛，最近的比赛在前
+	if cmp.HeadToHead != [2]int{3, 0} || len(cmp.Competitions) != 2 || cmp.Competitions[0].CompetitionID != "ChinaOpen2024" ||
+		len(cmp.Competitions[0].Rounds) != 2 || cmp.Competitions[0].Rounds[0].RoundTypeID != "1" || cmp.Competitions[1].Rounds[0].Results[1].Pos != 4 {
+		t.Fatalf("unexpected head to head %+v", cmp.Competitions)
+	}
+
+	// 郭志三盲没有有效成绩，只计入全部项目得分；世锦赛三阶与三盲决赛都是名次对决
+	cmp, err = w.ComparePersons("2012PARK03", "2018GUOZ01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmp.Events) != 2 || cmp.Events[1].Single.Winner != 1 || cmp.Events[1].Average != nil ||
+		cmp.Score != [2]int{3, 0} || cmp.SharedScore != [2]int{2, 0} || cmp.HeadToHead != [2]int{2, 0} {
+		t.Fatalf("unexpected compare %+v", cmp)
+	}
+
+	if _, err = w.ComparePersons("2012PARK03", "2000NONE01"); err == nil {
+		t.Fatal("want error for unknown person")
+	}
+}
+
+func Test_sqliteWCA_Export(t *testing.T) {
+	w, s := newFixtureWCA(t)
+
+	var buf bytes.Buffer
+	count, err := w.Export(&buf, types.ExportOptions{
+		Dataset: "results", Columns: []string{"person_id", "best", "average"}, Country: "CN", EventID: "333", Year: 2024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "person_id,best,average\n2019WANY36,400,470\n2018GUOZ01,580,650\n2019WANY36,390,460\n2018GUOZ01,600,-1\n"
+	if count != 4 || buf.String() != want {
+		t.Fatalf("unexpected csv %d\n%s", count, buf.String())
+	}
+
+	// 统计表
+	if err = s.setStaticSuccessRateResult(); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if count, err = w.Export(&buf, types.ExportOptions{Dataset: "success_rates", Format: types.ExportFormatParquet, EventID: "333bf"}); err != nil {
+		t.Fatal(err)
+	}
+	if data := buf.Bytes(); count != 2 || string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatalf("unexpected parquet export %d", count)
+	}
+
+	for _, opt := range []types.ExportOptions{
+		{Dataset: "unknown"},
+		{Dataset: "persons", EventID: "333"},
+		{Dataset: "persons", Columns: []string{"password"}},
+		{Dataset: "persons", Format: "xlsx"},
+	} {
+		if _, err = w.Export(&buf, opt); err == nil {
+			t.Fatalf("want error for %+v", opt)
+		}
+	}
+}
@@ -11,8 +11,9 @@ import (
 )
 
 func (w *wca) GetPersonRankTimer(wcaId string) ([]types.StaticWithTimerRank, error) {
+	wcaId = normalizeWcaID(wcaId)
 	var out []types.StaticWithTimerRank
-	if err := w.db.Where("wca_id = ?", wcaId).Find(&out).Error; err != nil {
+	if err := w.db().Where("wca_id = ?", wcaId).Find(&out).Error; err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -40,7 +41,8 @@ func (w *wca) getCountryID(country string) string {
 		return ""
 	}
 	var dbCountry types.Country
-	if err := w.db.Or("iso2 = ?", country).Or("name = ?", country).Or("id = ?", country).First(&dbCountry).Error; err != nil {
+	// 使用 LOWER 保证 MySQL 与 SQLite 下都不区分大小写
+	if err := w.db().Where("LOWER(iso2) = LOWER(?) OR LOWER(name) = LOWER(?) OR LOWER(id) = LOWER(?)", country, country, country).First(&dbCountry).Error; err != nil {
 		return country
 	}
 	country = dbCountry.ID
@@ -53,7 +55,7 @@ func (w *wca) GetEventRankWithTimer(eventId, country string, year int, isAvg boo
 	// 然后查询该年该月的所有记录
 	var results []types.StaticWithTimerRank
 
-	query := w.db.Model(&types.StaticWithTimerRank{}).
+	query := w.db().Model(&types.StaticWithTimerRank{}).
 		Where("event_id = ? AND year = ? AND month = ?", eventId, year, maxMonth)
 
 	if country != "" {
@@ -80,7 +82,7 @@ func (w *wca) GetEventRankWithTimer(eventId, country string, year int, isAvg boo
 
 	// 计算总数用于分页
 	var total int64
-	//countQuery := w.db.Model(&types.StaticWithTimerRank{}).
+	//countQuery := w.db().Model(&types.StaticWithTimerRank{}).
 	//	Where("event_id = ? AND year = ? AND month = ?", eventId, year, maxMonth)
 	//
 	//if country != "" {
@@ -111,7 +113,7 @@ func (w *wca) GetEventRankWithTimer(eventId, country string, year int, isAvg boo
 		wcaIDs = append(wcaIDs, result.WcaID)
 	}
 	var ps []types.Person
-	w.db.Where("wca_id in (?)", wcaIDs).Where("sub_id = 1").Find(&ps)
+	w.db().Where("wca_id in (?)", wcaIDs).Where("sub_id = 1").Find(&ps)
 	var personMap = make(map[string]types.Person)
 	for _, person := range ps {
 		personMap[person.WcaID] = person
@@ -160,7 +162,7 @@ func (w *wca) findResultWithQuery(eventId string, page, size int, query *gorm.DB
 }
 
 func (w *wca) GetEventRankWithFullNow(eventId, country string, isAvg bool, page, size int) ([]types.Result, int64, error) {
-	query := w.db.Model(&types.Result{}).Where("event_id = ?", eventId)
+	query := w.db().Model(&types.Result{}).Where("event_id = ?", eventId)
 	if country != "" {
 		country = w.getCountryID(country)
 		query = query.Where("person_country_id = ?", country)
@@ -186,7 +188,7 @@ func (w *wca) GetEventRankWithFullNow(eventId, country string, isAvg bool, page,
 
 func (w *wca) getYearCompIDs(year int) []string {
 	var comps []types.Competition
-	w.db.Where("year = ?", year).Find(&comps)
+	w.db().Where("year = ?", year).Find(&comps)
 
 	var out []string
 	for _, comp := range comps {
@@ -196,7 +198,7 @@ func (w *wca) getYearCompIDs(year int) []string {
 }
 
 func (w *wca) GetEventRankWithOnlyYear(eventId, countryID string, year int, isAvg bool, page, size int) ([]types.Result, int64, error) {
-	query := w.db.Model(&types.Result{}).Where("event_id = ?", eventId)
+	query := w.db().Model(&types.Result{}).Where("event_id = ?", eventId)
 	comps := w.getYearCompIDs(year)
 	if len(comps) == 0 {
 		return nil, 0, nil
@@ -268,7 +270,7 @@ func (w *wca) GetEventRankWithOnlyYear(eventId, countryID string, year int, isAv
 }
 
 func (w *wca) GetEventSuccessRateResult(eventId, countryID string, minAttempted, page, size int) ([]types.StaticSuccessRateResult, int64, error) {
-	query := w.db.Model(&types.StaticSuccessRateResult{}).Where("event_id = ?", eventId)
+	query := w.db().Model(&types.StaticSuccessRateResult{}).Where("event_id = ?", eventId)
 	if countryID != "" {
 		query = query.Where("country = ?", w.getCountryID(countryID))
 	}
@@ -307,13 +309,14 @@ func (w *wca) GetEventSuccessRateResult(eventId, countryID string, minAttempted,
 }
 
 func (w *wca) GetPersonBestRanks(wcaID string) (types.PersonBestRanks, error) {
+	wcaID = normalizeWcaID(wcaID)
 	key := fmt.Sprintf("GetPersonBestRanks_%s", wcaID)
 	if out, ok := w.cache.Get(key); ok {
 		return out.(types.PersonBestRanks), nil
 	}
 
 	var results []types.StaticWithTimerRank
-	if err := w.db.Model(&types.StaticWithTimerRank{}).Where("wca_id = ?", wcaID).Find(&results).Error; err != nil {
+	if err := w.db().Model(&types.StaticWithTimerRank{}).Where("wca_id = ?", wcaID).Find(&results).Error; err != nil {
 		return types.PersonBestRanks{}, err
 	}
 
@@ -389,7 +392,7 @@ func (w *wca) GetPersonBestRanks(wcaID string) (types.PersonBestRanks, error) {
 func (w *wca) GetAllEventsAchievement(lackNum int, country string, page int, size int) ([]types.AllEventAvgPersonResults, int64, error) {
 	var out []types.AllEventAvgPersonResults
 
-	query := w.db.Model(&types.AllEventAvgPersonResults{}).Where("lack_num = ?", lackNum)
+	query := w.db().Model(&types.AllEventAvgPersonResults{}).Where("lack_num = ?", lackNum)
 
 	if country != "" {
 		query = query.Where("country = ?", w.getCountryID(country))
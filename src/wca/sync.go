@@ -10,11 +10,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/guojia99/cubing-pro/src/wca/types"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
-const syncUrl = "https://www.worldcubeassociation.org/export/results/v2/sql"
+const syncUrl = "https://www.worldcubeassociation.org/export/results/v2/tsv" // 使用 TSV 导出，无需 mysql 客户端
 const mysqlOtherSet = "?charset=utf8mb4&parseTime=True&loc=Local"
 const keepDays = 1 // 只保留最近 1 天的数据（可调整）
 
@@ -23,11 +24,16 @@ const keepDays = 1 // 只保留最近 1 天的数据（可调整）
 // ==================================================================
 
 type syncer struct {
+	Driver    string // mysql, sqlite
 	DbPath    string
 	SyncPath  string
 	DbURL     string
 	currentDB string // e.g., "wca_20251225"
 
+	previousDB string                 // 上一个快照，清理时保留用于回滚
+	prevDB     *gorm.DB               // 正在使用的库，校验新库行数时对比
+	validation *types.WcaDBValidation // 新快照的校验结果
+
 	db *gorm.DB
 }
 
@@ -52,7 +58,7 @@ func (s *syncer) init() error {
 
 func (s *syncer) syncFileAndSyncToDb() error {
 	// Step 1: 获取远程最新数据时间
-	ts, url, err := checkRemoteFileDate()
+	ts, url, err := checkRemoteFileDate(syncUrl)
 	if err != nil {
 		return fmt.Errorf("check remote date failed: %w", err)
 	}
@@ -65,84 +71,64 @@ func (s *syncer) syncFileAndSyncToDb() error {
 		log.Printf("Already using %s, skipping sync.", targetDBName)
 		return nil
 	}
+	return s.syncTSVExport(remoteDay, targetDBName, url)
+}
 
-	// Step 3: 下载 ZIP（如果不存在）
+// syncTSVExport 下载 TSV 导出并导入到目标库, MySQL 与 SQLite 使用同一个导入流程
+// 导入中断时保留已导入的数据，下次同步时从中断处继续
+func (s *syncer) syncTSVExport(remoteDay, targetDBName, url string) error {
 	zipPath := filepath.Join(s.SyncPath, remoteDay+".zip")
-	if _, err = os.Stat(zipPath); os.IsNotExist(err) {
-		log.Printf("Downloading WCA export for %s...", remoteDay)
-		var err error
-		zipPath, err = downloadIfNeeded(s.SyncPath, url)
-		if err != nil {
+	if _, err := os.Stat(zipPath); os.IsNotExist(err) {
+		log.Printf("Downloading WCA TSV export for %s...", remoteDay)
+		if zipPath, err = downloadIfNeeded(s.SyncPath, url); err != nil {
 			return fmt.Errorf("download failed: %w", err)
 		}
 	}
 
-	// Step 4: 解压
-	extractDir := filepath.Join(s.DbPath, remoteDay)
-	if _, err = os.Stat(extractDir); os.IsNotExist(err) {
-		log.Printf("Extracting %s to %s...", zipPath, extractDir)
-		_, err = extractZipToDb(zipPath, s.DbPath)
-		if err != nil {
-			return fmt.Errorf("extract failed: %w", err)
+	if s.Driver != wcaDriverSqlite {
+		if err := s.createMysqlDatabase(targetDBName); err != nil {
+			return err
 		}
 	}
-
-	sqlFile := filepath.Join(extractDir, "WCA_export.sql")
-	if _, err = os.Stat(sqlFile); os.IsNotExist(err) {
-		return fmt.Errorf("WCA_export.sql not found in %s", extractDir)
-	}
-	//  清理 SQL 文件中的 MySQL 特有注释
-	log.Printf("start remove mysql version comments db file: %s", sqlFile)
-	if err = cleanSQLWithSed(sqlFile); err != nil {
-		return fmt.Errorf("failed to clean SQL comments: %w", err)
-	}
-	log.Printf("success remove mysql version comments db file: %s", sqlFile)
-
-	// Step 5: 连接 MySQL
-	dsn := s.DbURL + mysqlOtherSet
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := openWcaDB(s.Driver, s.DbURL, s.DbPath, targetDBName)
 	if err != nil {
-		return fmt.Errorf("failed to connect to MySQL: %w", err)
+		return fmt.Errorf("failed to open %s: %w", targetDBName, err)
 	}
-	sqlDB, err := db.DB()
+	checksum, err := ImportTSVExport(db, zipPath, TSVImportOptions{})
+	closeDB(db)
 	if err != nil {
-		return fmt.Errorf("failed to get raw DB: %w", err)
-	}
-	defer sqlDB.Close()
-
-	// Step 6: 确保目标数据库存在（先删后建，确保干净）
-	if _, err = sqlDB.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", targetDBName)); err != nil {
-		return fmt.Errorf("failed to drop existing DB %s: %w", targetDBName, err)
-	}
-	if _, err = sqlDB.Exec(fmt.Sprintf("CREATE DATABASE `%s` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci", targetDBName)); err != nil {
-		return fmt.Errorf("failed to create DB %s: %w", targetDBName, err)
-	}
-
-	// Step 7: 导入 SQL（使用 mysql 命令行）
-	if err = importSQLFileViaShell(targetDBName, sqlFile, s.DbURL); err != nil {
-		// 导入失败，清理残留
-		_, _ = sqlDB.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", targetDBName))
-		return fmt.Errorf("import SQL failed: %w", err)
+		return fmt.Errorf("import TSV failed: %w", err)
 	}
+	log.Printf("Imported WCA TSV export %s (sha256 %s) to %s", zipPath, checksum, targetDBName)
 
-	// Step 8: 更新索引
-	log.Printf("Starting to add indexes to %s...", s.currentDB)
+	log.Printf("Starting to add indexes to %s...", targetDBName)
 	if err = s.syncAddIndex(targetDBName, syncWcaDbIndex); err != nil {
 		return fmt.Errorf("index creation failed: %w", err)
 	}
 
 	s.currentDB = targetDBName
-
 	log.Printf("Successfully synced to database: %s", targetDBName)
 	return nil
 }
 
+// createMysqlDatabase 目标库不存在时创建，已存在时保留以便断点续传
+func (s *syncer) createMysqlDatabase(dbName string) error {
+	db, err := gorm.Open(mysql.Open(s.DbURL+mysqlOtherSet), &gorm.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+	defer closeDB(db)
+	if err = db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci", dbName)).Error; err != nil {
+		return fmt.Errorf("failed to create DB %s: %w", dbName, err)
+	}
+	return nil
+}
+
 func (s *syncer) getCurrentDatabase() (*gorm.DB, string, error) {
 	if s.currentDB == "" {
 		return nil, "", fmt.Errorf("no current database set")
 	}
-	dsn := s.DbURL + s.currentDB + mysqlOtherSet
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := openWcaDB(s.Driver, s.DbURL, s.DbPath, s.currentDB)
 	s.db = db
 	return db, s.currentDB, err
 }
@@ -151,29 +137,40 @@ func (s *syncer) sync() error {
 	if err := s.init(); err != nil {
 		return fmt.Errorf("init failed: %w", err)
 	}
+	previousDB := s.currentDB
 	//
 	if err := s.syncFileAndSyncToDb(); err != nil {
 		return fmt.Errorf("sync failed: %w", err)
 	}
 
 	if _, _, err := s.getCurrentDatabase(); err != nil {
+		if s.currentDB != previousDB {
+			s.rollback(previousDB)
+		}
 		return err
 	}
 
-	// Step 9: 更新 wca.txt
-	txtPath := filepath.Join(s.DbPath, "wca.txt")
-	if err := os.WriteFile(txtPath, []byte(s.currentDB), 0644); err != nil {
-		return fmt.Errorf("failed to update wca.txt: %w", err)
+	// Step 9: 新快照切换前校验，失败时删除新库，继续使用上一个快照
+	if s.currentDB != previousDB {
+		v := validateWcaDB(s.db, s.Driver, s.currentDB, s.prevDB)
+		s.validation = &v
+		if !v.Passed {
+			failedDB := s.currentDB
+			s.rollback(previousDB)
+			return fmt.Errorf("validation of %s failed: %s", failedDB, strings.Join(v.Errors, "; "))
+		}
+		s.previousDB = previousDB
 	}
 
 	if err := s.syncStatics(); err != nil {
 		log.Printf("failed to sync statics: %v", err)
 	}
 
-	if err := s.clean(); err != nil {
-		log.Printf("Warning: pre-sync clean failed: %v", err)
+	// Step 10: 统计完成后再更新 wca.txt，其他进程读取到后才切换
+	txtPath := filepath.Join(s.DbPath, "wca.txt")
+	if err := os.WriteFile(txtPath, []byte(s.currentDB), 0644); err != nil {
+		return fmt.Errorf("failed to update wca.txt: %w", err)
 	}
-
 	return nil
 }
 
@@ -225,6 +222,10 @@ CREATE INDEX idx_event_country_avg ON results (event_id, person_country_id, aver
 `
 
 func (s *syncer) syncAddIndex(dbName string, indexData string) error {
+	if s.Driver == wcaDriverSqlite {
+		return s.syncAddSqliteIndex(dbName, indexData)
+	}
+
 	dsn := s.DbURL + mysqlOtherSet + "&multiStatements=true"
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	if err != nil {
@@ -258,6 +259,34 @@ func (s *syncer) syncAddIndex(dbName string, indexData string) error {
 	return nil
 }
 
+// rollback 新快照不可用时删除新库，恢复使用上一个快照
+func (s *syncer) rollback(previousDB string) {
+	failedDB := s.currentDB
+	if s.db != nil {
+		closeDB(s.db)
+		s.db = nil
+	}
+	s.currentDB = previousDB
+
+	log.Printf("Rolling back to %q, dropping %s", previousDB, failedDB)
+	if err := s.dropDatabase(failedDB); err != nil {
+		log.Printf("Warning: drop %s failed: %v", failedDB, err)
+	}
+}
+
+func (s *syncer) dropDatabase(dbName string) error {
+	if s.Driver == wcaDriverSqlite {
+		return os.Remove(sqliteDbFile(s.DbPath, dbName))
+	}
+
+	db, err := gorm.Open(mysql.Open(s.DbURL+mysqlOtherSet), &gorm.Config{})
+	if err != nil {
+		return err
+	}
+	defer closeDB(db)
+	return db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", dbName)).Error
+}
+
 // clean 清理旧文件和旧数据库
 func (s *syncer) clean() error {
 	now := time.Now().UTC()
@@ -302,6 +331,10 @@ func (s *syncer) clean() error {
 	}
 
 	// 3. 清理旧数据库
+	if s.Driver == wcaDriverSqlite {
+		s.cleanSqliteDbs(cutoff)
+		return nil
+	}
 	dsn := s.DbURL + mysqlOtherSet
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	if err != nil {
@@ -327,7 +360,7 @@ func (s *syncer) clean() error {
 	}
 
 	for _, dbName := range dbs {
-		if strings.HasPrefix(dbName, "wca_") && dbName != s.currentDB {
+		if strings.HasPrefix(dbName, "wca_") && dbName != s.currentDB && dbName != s.previousDB {
 			datePart := strings.TrimPrefix(dbName, "wca_")
 			if len(datePart) == 8 && isDigitsOnly(datePart) {
 				if datePart < cutoff {
@@ -354,24 +387,43 @@ func (w *wca) sync() error {
 		return nil
 	}
 
+	cur := w.snapshot.Load()
 	s := &syncer{
-		DbPath:   w.dbPath,
-		SyncPath: w.syncPath,
-		DbURL:    w.dbURL,
-	}
-
-	if err := s.sync(); err != nil {
+		Driver:     w.driver,
+		DbPath:     w.dbPath,
+		SyncPath:   w.syncPath,
+		DbURL:      w.dbURL,
+		previousDB: w.Status().PreviousDB,
+	}
+	if cur != nil {
+		s.prevDB = cur.db
+	}
+
+	w.setStatus(func(st *types.WcaDBStatus) { st.Syncing = true })
+	err := s.sync()
+	w.setStatus(func(st *types.WcaDBStatus) {
+		now := time.Now()
+		st.Syncing = false
+		st.LastSyncAt = &now
+		st.LastSyncError = ""
+		if err != nil {
+			st.LastSyncError = err.Error()
+		}
+		if s.validation != nil {
+			st.LastValidation = s.validation
+		}
+	})
+	if err != nil {
 		return fmt.Errorf("sync failed: %w", err)
 	}
 
-	db, dbName, err := s.getCurrentDatabase()
-	if err != nil {
-		return fmt.Errorf("get current DB failed: %w", err)
+	if cur == nil || cur.name != s.currentDB {
+		w.switchDB(s.db, s.currentDB)
+	} else {
+		closeDB(s.db)
 	}
 
-	w.db = db
-	w.dbName = dbName
-
+	// 切换完成后再清理旧快照
 	if err = s.clean(); err != nil {
 		log.Printf("Warning: post-sync clean failed: %v", err)
 	}
@@ -407,13 +459,41 @@ func (w *wca) updateDb() {
 		return
 	}
 
-	dsn := w.dbURL + string(wcaDbStr) + mysqlOtherSet
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	dbName := strings.TrimSpace(string(wcaDbStr))
+	cur := w.snapshot.Load()
+	if cur != nil && cur.name == dbName {
+		return
+	}
+
+	// 打开或校验失败时继续使用当前快照
+	fail := func(err error) {
+		log.Printf("update wca db to %s failed, keep %s: %v", dbName, w.Status().CurrentDB, err)
+		w.setStatus(func(st *types.WcaDBStatus) { st.LastSyncError = err.Error() })
+	}
+	if w.driver == wcaDriverSqlite {
+		if _, err = os.Stat(sqliteDbFile(w.dbPath, dbName)); err != nil {
+			fail(err)
+			return
+		}
+	}
+	db, err := openWcaDB(w.driver, w.dbURL, w.dbPath, dbName)
 	if err != nil {
+		fail(err)
 		return
 	}
 
-	w.db = db
+	var prev *gorm.DB
+	if cur != nil {
+		prev = cur.db
+	}
+	v := validateWcaDB(db, w.driver, dbName, prev)
+	w.setStatus(func(st *types.WcaDBStatus) { st.LastValidation = &v })
+	if !v.Passed {
+		closeDB(db)
+		fail(fmt.Errorf("validation failed: %s", strings.Join(v.Errors, "; ")))
+		return
+	}
+	w.switchDB(db, dbName)
 }
 
 func (w *wca) updateDbLoop() {
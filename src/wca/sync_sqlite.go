@@ -0,0 +1,63 @@
+package wca
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (s *syncer) syncAddSqliteIndex(dbName string, indexData string) error {
+	statements, err := sqliteIndexStatements(indexData)
+	if err != nil {
+		return err
+	}
+
+	db := s.db
+	if db == nil || dbName != s.currentDB {
+		if db, err = openWcaDB(wcaDriverSqlite, "", s.DbPath, dbName); err != nil {
+			return fmt.Errorf("failed to open %s for indexing: %w", dbName, err)
+		}
+		defer closeDB(db)
+	}
+
+	for _, stmt := range statements {
+		log.Printf("Syncing WCA index: %s", stmt)
+		if err = db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to execute index statement [%s]: %w", stmt, err)
+		}
+	}
+	log.Printf("Successfully added all indexes to database: %s", dbName)
+	return nil
+}
+
+// cleanSqliteDbs 删除早于 cutoff 的 SQLite 文件
+func (s *syncer) cleanSqliteDbs(cutoff string) {
+	entries, err := os.ReadDir(s.DbPath)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "wca_") || !strings.HasSuffix(name, ".db") {
+			continue
+		}
+		dbName := strings.TrimSuffix(name, ".db")
+		datePart := strings.TrimPrefix(dbName, "wca_")
+		if dbName == s.currentDB || dbName == s.previousDB || len(datePart) != 8 || !isDigitsOnly(datePart) || datePart >= cutoff {
+			continue
+		}
+		path := filepath.Join(s.DbPath, name)
+		log.Printf("Removing old sqlite db: %s", path)
+		_ = os.Remove(path)
+	}
+}
+
+// insertBatchSize 批量写入的行数，SQLite 需要限制单条语句的参数数量
+func (s *syncer) insertBatchSize(size int) int {
+	if s.Driver == wcaDriverSqlite && size > sqliteMaxBatch {
+		return sqliteMaxBatch
+	}
+	return size
+}
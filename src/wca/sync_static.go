@@ -19,6 +19,8 @@ func (s *syncer) syncStatics() error {
 		"setStaticSuccessRateResult":           s.setStaticSuccessRateResult,           // 达成比例
 		"setStaticAllEventAvg":                 s.setStaticAllEventAvg,                 // 达成大满贯统计
 		"setStaticAllEventChampionshipsPodium": s.setStaticAllEventChampionshipsPodium, // 达成某个项目大满贯
+		"setStaticRecordHistory":               s.setStaticRecordHistory,               // 纪录历史
+		"setStaticPersonProvinces":             s.setStaticPersonProvinces,             // 中国选手所属省份
 
 		//"setStaticDiyEventRanks": s.setStaticDiyEventRanks, // 各种组合的 event world 排名前 500
 	}
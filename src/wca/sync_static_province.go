@@ -0,0 +1,108 @@
+package wca
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/wca/types"
+)
+
+const chinaCountryID = "China"
+
+const setStaticPersonProvinceIndex = `
+CREATE INDEX idx_province ON static_person_provinces (province);
+`
+
+// getChinaPersonProvinces 中国选手所属省份：优先使用粗饼主页的地区，否则取参赛次数最多的省份，次数相同时取省份名靠前的
+func (s *syncer) getChinaPersonProvinces() map[string]types.StaticPersonProvince {
+	var comps []types.Competition
+	s.db.Select("id", "city_name").Where("country_id = ?", chinaCountryID).Find(&comps)
+	compProvince := make(map[string]string)
+	for _, comp := range comps {
+		if strings.Contains(comp.CityName, "Multiple") {
+			continue
+		}
+		_, province := chinaCityProvince(comp.CityName)
+		if province = normalizeProvince(province); province != "" {
+			compProvince[comp.ID] = province
+		}
+	}
+
+	var results []types.Result
+	s.db.Distinct("person_id", "competition_id").Where("person_country_id = ?", chinaCountryID).Find(&results)
+
+	counts := make(map[string]map[string]int)
+	for _, r := range results {
+		province, ok := compProvince[r.CompetitionID]
+		if !ok {
+			continue
+		}
+		if _, ok = counts[r.PersonID]; !ok {
+			counts[r.PersonID] = make(map[string]int)
+		}
+		counts[r.PersonID][province] += 1
+	}
+
+	out := make(map[string]types.StaticPersonProvince, len(counts))
+	for personID, provinces := range counts {
+		p := types.StaticPersonProvince{WcaID: personID, Source: types.ProvinceSourceCompetition}
+		for province, count := range provinces {
+			if count > p.CompCount || (count == p.CompCount && province < p.Province) {
+				p.Province, p.CompCount = province, count
+			}
+		}
+		out[personID] = p
+	}
+
+	// 粗饼地区覆盖，只对中国选手生效
+	overrides := loadProvinceOverrides(s.DbPath)
+	if len(overrides) == 0 {
+		return out
+	}
+	var persons []types.Person
+	s.db.Select("wca_id").Where("country_id = ? AND sub_id = 1", chinaCountryID).Find(&persons)
+	for _, person := range persons {
+		province, ok := overrides[person.WcaID]
+		if !ok {
+			continue
+		}
+		p := out[person.WcaID]
+		p.WcaID, p.Source = person.WcaID, types.ProvinceSourceCubing
+		if p.Province != province {
+			p.Province, p.CompCount = province, counts[person.WcaID][province]
+		}
+		out[person.WcaID] = p
+	}
+	return out
+}
+
+func (s *syncer) setStaticPersonProvinces() (err error) {
+	startTime := time.Now()
+	if err = s.db.AutoMigrate(&types.StaticPersonProvince{}); err != nil {
+		return
+	}
+	s.db.Delete(&types.StaticPersonProvince{}, "1 = 1")
+	defer func() {
+		if err != nil {
+			s.db.Delete(&types.StaticPersonProvince{}, "1 = 1")
+		}
+	}()
+
+	var data []types.StaticPersonProvince
+	for _, p := range s.getChinaPersonProvinces() {
+		data = append(data, p)
+	}
+	log.Printf("save person province (%d) count", len(data))
+	if len(data) > 0 {
+		if err = s.db.CreateInBatches(data, s.insertBatchSize(5000)).Error; err != nil {
+			return err
+		}
+	}
+
+	if err = s.syncAddIndex(s.currentDB, setStaticPersonProvinceIndex); err != nil {
+		return err
+	}
+	log.Printf("Person province generation completed in %v", time.Since(startTime))
+	return nil
+}
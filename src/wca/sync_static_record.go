@@ -0,0 +1,236 @@
+package wca
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/wca/types"
+)
+
+const setStaticRecordHistoryIndex = `
+-- 按项目与地区查询纪录历史
+CREATE INDEX idx_event_scope_region ON static_record_histories (event_id, scope, region, seq);
+
+-- 按保持者国家筛选
+CREATE INDEX idx_country ON static_record_histories (country);
+
+-- 现有纪录
+CREATE INDEX idx_current ON static_record_histories (is_current, scope, region);
+`
+
+const recordWorldRegion = "World"
+
+type recordHistoryKey struct {
+	scope   string
+	region  string
+	eventID string
+	isAvg   bool
+}
+
+// recordHistoryBuilder 按项目与地区收集纪录，最后统一计算保持时间
+type recordHistoryBuilder struct {
+	compMap    map[string]types.Competition
+	roundRanks map[string]int32
+	groups     map[recordHistoryKey][]types.StaticRecordHistory
+}
+
+func (b *recordHistoryBuilder) compDate(compID string) string {
+	cp := b.compMap[compID]
+	return time.Date(int(cp.Year), time.Month(cp.Month), int(cp.Day), 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+}
+
+func (b *recordHistoryBuilder) add(scope, region, record string, isAvg bool, r types.Result) {
+	value := r.Best
+	if isAvg {
+		value = r.Average
+	}
+	key := recordHistoryKey{scope: scope, region: region, eventID: r.EventID, isAvg: isAvg}
+	b.groups[key] = append(b.groups[key], types.StaticRecordHistory{
+		EventID:         r.EventID,
+		IsAvg:           isAvg,
+		Scope:           scope,
+		Region:          region,
+		Record:          record,
+		Value:           value,
+		WcaID:           r.PersonID,
+		WcaName:         r.PersonName,
+		Country:         r.PersonCountryID,
+		CompetitionID:   r.CompetitionID,
+		CompetitionName: b.compMap[r.CompetitionID].Name,
+		RoundTypeID:     r.RoundTypeID,
+		Date:            b.compDate(r.CompetitionID),
+	})
+}
+
+// less 纪录按比赛日期、轮次先后排序，同一轮中成绩好的排在后面
+func (b *recordHistoryBuilder) less(aDate, aRound string, aValue int, bDate, bRound string, bValue int) bool {
+	if aDate != bDate {
+		return aDate < bDate
+	}
+	if aRound != bRound {
+		return b.roundRanks[aRound] < b.roundRanks[bRound]
+	}
+	return aValue > bValue
+}
+
+// build 计算每条纪录被打破的日期与保持天数，与当前纪录持平的都算作现有纪录
+func (b *recordHistoryBuilder) build() []types.StaticRecordHistory {
+	var out []types.StaticRecordHistory
+	for _, list := range b.groups {
+		sort.SliceStable(list, func(i, j int) bool {
+			return b.less(list[i].Date, list[i].RoundTypeID, list[i].Value, list[j].Date, list[j].RoundTypeID, list[j].Value)
+		})
+		for i := range list {
+			list[i].Seq = i + 1
+			list[i].IsCurrent = true
+			for j := i + 1; j < len(list); j++ {
+				if list[j].Value < list[i].Value {
+					list[i].IsCurrent = false
+					list[i].EndDate = list[j].Date
+					list[i].Days = recordDays(list[i].Date, list[j].Date)
+					break
+				}
+			}
+		}
+		out = append(out, list...)
+	}
+	return out
+}
+
+func recordDays(from, to string) int {
+	f, err1 := time.Parse("2006-01-02", from)
+	t, err2 := time.Parse("2006-01-02", to)
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	return int(t.Sub(f).Hours() / 24)
+}
+
+// addProvinceRecords 按时间顺序遍历中国选手的成绩，成绩不差于所在省份当时纪录的即为省纪录
+func (s *syncer) addProvinceRecords(b *recordHistoryBuilder, eventID string, provinces map[string]string) {
+	var results []types.Result
+	s.db.Select("competition_id", "event_id", "round_type_id", "best", "average", "person_id", "person_name", "person_country_id").
+		Where("event_id = ? AND person_country_id = ?", eventID, chinaCountryID).
+		Find(&results)
+	if len(results) == 0 {
+		return
+	}
+
+	dates := make(map[string]string)
+	for _, r := range results {
+		if _, ok := dates[r.CompetitionID]; !ok {
+			dates[r.CompetitionID] = b.compDate(r.CompetitionID)
+		}
+	}
+
+	for _, isAvg := range []bool{false, true} {
+		value := func(r types.Result) int {
+			if isAvg {
+				return r.Average
+			}
+			return r.Best
+		}
+		sort.SliceStable(results, func(i, j int) bool {
+			a, c := results[i], results[j]
+			// 同一轮中成绩好的先处理，持平的同样算作纪录
+			if dates[a.CompetitionID] == dates[c.CompetitionID] && a.RoundTypeID == c.RoundTypeID {
+				return value(a) < value(c)
+			}
+			return b.less(dates[a.CompetitionID], a.RoundTypeID, 0, dates[c.CompetitionID], c.RoundTypeID, 0)
+		})
+
+		bestMap := make(map[string]int)
+		for _, r := range results {
+			province, ok := provinces[r.PersonID]
+			v := value(r)
+			if !ok || v <= 0 {
+				continue
+			}
+			if cur, has := bestMap[province]; has && v > cur {
+				continue
+			}
+			bestMap[province] = v
+			b.add(types.RecordScopeProvince, province, "", isAvg, r)
+		}
+	}
+}
+
+func (s *syncer) setStaticRecordHistory() (err error) {
+	startTime := time.Now()
+	if err = s.db.AutoMigrate(&types.StaticRecordHistory{}); err != nil {
+		return
+	}
+	s.db.Delete(&types.StaticRecordHistory{}, "1 = 1")
+	defer func() {
+		if err != nil {
+			s.db.Delete(&types.StaticRecordHistory{}, "1 = 1")
+		}
+	}()
+
+	var roundTypes []types.RoundType
+	s.db.Find(&roundTypes)
+	b := &recordHistoryBuilder{
+		compMap:    s.getCompMap(),
+		roundRanks: make(map[string]int32),
+		groups:     make(map[recordHistoryKey][]types.StaticRecordHistory),
+	}
+	for _, rt := range roundTypes {
+		b.roundRanks[rt.ID] = rt.Rank
+	}
+	continents := s.getCountryContinentMap()
+
+	// 1. WR / CR / NR，WR 同时是洲纪录与国家纪录，洲纪录同时是国家纪录
+	var results []types.Result
+	if err = s.db.Select("competition_id", "event_id", "round_type_id", "best", "average", "person_id", "person_name", "person_country_id", "regional_single_record", "regional_average_record").
+		Where("regional_single_record <> '' OR regional_average_record <> ''").
+		Find(&results).Error; err != nil {
+		return fmt.Errorf("select record results: %w", err)
+	}
+	for _, r := range results {
+		for _, isAvg := range []bool{false, true} {
+			record := strings.TrimSpace(r.RegionalSingleRecord)
+			if isAvg {
+				record = strings.TrimSpace(r.RegionalAverageRecord)
+			}
+			if record == "" {
+				continue
+			}
+			b.add(types.RecordScopeNational, r.PersonCountryID, record, isAvg, r)
+			if record != "NR" {
+				b.add(types.RecordScopeContinent, continents[r.PersonCountryID], record, isAvg, r)
+			}
+			if record == "WR" {
+				b.add(types.RecordScopeWorld, recordWorldRegion, record, isAvg, r)
+			}
+		}
+	}
+	log.Printf("found %d record results", len(results))
+	results = nil // GC
+
+	// 2. 中国各省纪录
+	provinces := make(map[string]string)
+	for wcaID, p := range s.getChinaPersonProvinces() {
+		provinces[wcaID] = p.Province
+	}
+	for _, ev := range s.getEvents() {
+		s.addProvinceRecords(b, ev.ID, provinces)
+	}
+
+	data := b.build()
+	log.Printf("save record history (%d) count", len(data))
+	if len(data) > 0 {
+		if err = s.db.CreateInBatches(data, s.insertBatchSize(5000)).Error; err != nil {
+			return err
+		}
+	}
+
+	log.Println("Adding index for StaticRecordHistory...")
+	if err = s.syncAddIndex(s.currentDB, setStaticRecordHistoryIndex); err != nil {
+		return err
+	}
+	log.Printf("Record history generation completed in %v", time.Since(startTime))
+	return nil
+}
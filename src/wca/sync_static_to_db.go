@@ -387,7 +387,7 @@ func (s *syncer) setStaticPersonRankWithTimersWithEvent(eventID string, comps []
 			// 获取当前单次排名
 			snapshots := s.getCurPersonsRankTimerSnapshots(eventID, monthEnd, curAllPersonValue)
 			// 写入数据库
-			if err := s.db.CreateInBatches(snapshots, 4500).Error; err != nil {
+			if err := s.db.CreateInBatches(snapshots, s.insertBatchSize(4500)).Error; err != nil {
 				return err
 			}
 			snapshots = nil
@@ -533,7 +533,7 @@ func (s *syncer) setStaticSuccessRateResultWithEvent(eventID string) error {
 	}
 
 	log.Printf("save event %s (%d) count", eventID, len(saveData))
-	if err := s.db.CreateInBatches(saveData, 5000).Error; err != nil {
+	if err := s.db.CreateInBatches(saveData, s.insertBatchSize(5000)).Error; err != nil {
 		return err
 	}
 	return nil
@@ -732,7 +732,7 @@ func (s *syncer) setStaticAllEventAvg() (err error) {
 		}
 	}
 
-	if err = s.db.CreateInBatches(cutNumEventResults, 5000).Error; err != nil {
+	if err = s.db.CreateInBatches(cutNumEventResults, s.insertBatchSize(5000)).Error; err != nil {
 		log.Printf("create in batch failed, err:%v", err)
 	}
 	return nil
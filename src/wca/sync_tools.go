@@ -4,17 +4,12 @@ import (
 	"archive/zip"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
-	"runtime"
 	"strings"
 	"time"
-
-	"github.com/guojia99/cubing-pro/src/robot/qq_bot/Better-Bot-Go/log"
 )
 
 // copyFile 复制文件（用于跨文件系统的情况）
@@ -36,9 +31,9 @@ func copyFile(src, dst string) error {
 }
 
 // checkRemoteFileDate 从重定向后的 URL 中提取日期（YYYYMMDD）
-func checkRemoteFileDate() (ts time.Time, url string, err error) {
+func checkRemoteFileDate(exportUrl string) (ts time.Time, url string, err error) {
 	client := &http.Client{}
-	resp, err := client.Get(syncUrl)
+	resp, err := client.Get(exportUrl)
 	if err != nil {
 		return time.Time{}, "", err
 	}
@@ -198,99 +193,6 @@ func isSameUTCDay(t1, t2 time.Time) bool {
 	return t1.UTC().Truncate(24 * time.Hour).Equal(t2.UTC().Truncate(24 * time.Hour))
 }
 
-// parseMySQLDSN 解析 DSN 并返回 user, pass, host, port
-func parseMySQLDSN(dsn string) (user, pass, host, port string, err error) {
-	// 移除可选的数据库名和查询参数
-	dsn = strings.Split(dsn, "?")[0]   // 去掉 ?charset=...
-	dsn = strings.TrimSuffix(dsn, "/") // 去掉末尾 /
-
-	if !strings.Contains(dsn, "@") {
-		return "", "", "", "", fmt.Errorf("invalid DSN: missing '@'")
-	}
-
-	parts := strings.SplitN(dsn, "@", 2)
-	authPart := parts[0]
-	addrPart := parts[1]
-
-	// 解析 user[:pass]
-	user = authPart
-	pass = ""
-	if idx := strings.Index(authPart, ":"); idx != -1 {
-		user = authPart[:idx]
-		pass = authPart[idx+1:]
-	}
-
-	// 解析地址部分
-	host = "localhost"
-	port = "3306" // 默认
-
-	if strings.HasPrefix(addrPart, "tcp(") && strings.HasSuffix(addrPart, ")") {
-		addr := addrPart[4 : len(addrPart)-1] // 去掉 tcp(...)
-		if h, p, err := net.SplitHostPort(addr); err == nil {
-			host = h
-			port = p
-		} else {
-			// 可能只有 host（无端口）
-			host = addr
-		}
-	} else if strings.HasPrefix(addrPart, "unix(") {
-		return "", "", "", "", fmt.Errorf("unix socket not supported in importSQLFile")
-	} else {
-		// 裸地址（不推荐）
-		if h, p, err := net.SplitHostPort(addrPart); err == nil {
-			host = h
-			port = p
-		} else {
-			host = addrPart
-		}
-	}
-
-	return user, pass, host, port, nil
-}
-
-// importSQLFileViaShell 使用 shell 执行 mysql ... < file.sql 命令
-func importSQLFileViaShell(dbName, sqlFile, dsn string) error {
-	user, pass, host, port, err := parseMySQLDSN(dsn)
-	if err != nil {
-		return fmt.Errorf("解析 DSN 失败: %w", err)
-	}
-
-	// 检查 SQL 文件是否存在
-	if _, err = os.Stat(sqlFile); os.IsNotExist(err) {
-		return fmt.Errorf("SQL 文件不存在: %s", sqlFile)
-	}
-
-	// 构造完整的 shell 命令字符串
-	// 注意：密码中若含特殊字符（如 $、!、空格等）需转义，此处为简化未处理
-	var ps = ""
-	if pass != "" {
-		ps = fmt.Sprintf("-p%s", pass)
-	}
-
-	cmdStr := fmt.Sprintf(
-		"mysql -u %s %s -h %s -P %s %s < %s",
-		user, ps, host, port, dbName, sqlFile,
-	)
-
-	fmt.Printf("执行命令: %s\n", cmdStr) // 可选：用于调试（注意不要泄露密码）
-
-	// 通过 /bin/sh 执行命令（Linux/macOS）
-	cmd := exec.Command("/bin/sh", "-c", cmdStr)
-
-	// 可选：捕获输出以便查看错误
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("shell 命令执行失败: %w\n输出: %s", err, string(output))
-	}
-
-	// 成功时 output 通常为空（除非 mysql 打印了警告）
-	if len(output) > 0 {
-		fmt.Printf("命令输出: %s\n", string(output))
-	}
-
-	return nil
-}
-
 // 辅助函数
 func isDigitsOnly(s string) bool {
 	for _, r := range s {
@@ -300,24 +202,3 @@ func isDigitsOnly(s string) bool {
 	}
 	return true
 }
-
-// cleanSQLWithSed 执行: sed -i 's|/\*M![^*]*\*/||g' <sqlFile>
-// 兼容 macOS (-i 后需跟空字符串) 和 Linux
-func cleanSQLWithSed(sqlFile string) error {
-	var args []string
-	if runtime.GOOS == "darwin" {
-		// macOS 需要空字符串
-		args = []string{"-i", "", `s|/\*M![^*]*\*/||g`, sqlFile}
-	} else {
-		// Linux/Windows 等不需要
-		args = []string{"-i", `s|/\*M![^*]*\*/||g`, sqlFile}
-	}
-
-	cmd := exec.Command("sed", args...)
-	log.Infof("running sed: %s", cmd.String())
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("sed command failed: %w, output: %s", err, string(out))
-	}
-	return nil
-}
@@ -7,7 +7,7 @@ import (
 
 func Test_checkRemoteFileDate(t *testing.T) {
 
-	ts, url, err := checkRemoteFileDate()
+	ts, url, err := checkRemoteFileDate(syncUrl)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2,6 +2,7 @@ package wca
 
 import (
 	"fmt"
+	"strings"
 
 	jsoniter "github.com/json-iterator/go"
 )
@@ -14,6 +15,11 @@ func buildEventOrderMap() map[string]int {
 	return orderMap
 }
 
+// normalizeWcaID WCA ID 统一为大写，SQLite 的比较区分大小写
+func normalizeWcaID(wcaId string) string {
+	return strings.ToUpper(strings.TrimSpace(wcaId))
+}
+
 func buildIndexMap(events []string) map[string]uint64 {
 	m := make(map[string]uint64)
 	for i, e := range events {
@@ -89,3 +95,18 @@ func checkHasEvent(allEventCode uint64, personCode uint64) bool {
 	}
 	return allEventCode&personCode != 0
 }
+
+// chinaCityProvince 解析中国比赛的城市名，如 "Shenzhen, Guangdong Province" => Shenzhen, Guangdong；
+// 直辖市只有城市名，省份与城市相同
+func chinaCityProvince(cityName string) (city, province string) {
+	city, province = cityName, cityName
+	if strings.Contains(cityName, ",") {
+		cty := strings.ReplaceAll(cityName, "Province", "")
+		cty = strings.ReplaceAll(cty, " ", "")
+		sl := strings.Split(cty, ",")
+		if len(sl) == 2 {
+			city, province = sl[0], sl[1]
+		}
+	}
+	return
+}
@@ -23,3 +23,40 @@ func Test_decodeEvents(t *testing.T) {
 		})
 	}
 }
+
+func Test_chinaCityProvince(t *testing.T) {
+	tests := []struct {
+		in, city, province string
+	}{
+		{"Beijing", "Beijing", "Beijing"},
+		{"Shenzhen, Guangdong", "Shenzhen", "Guangdong"},
+		{"Baotou, Inner Mongolia", "Baotou", "InnerMongolia"},
+		{"Hangzhou, Zhejiang Province", "Hangzhou", "Zhejiang"},
+	}
+	for _, tt := range tests {
+		if city, province := chinaCityProvince(tt.in); city != tt.city || province != tt.province {
+			t.Errorf("chinaCityProvince(%q) = %s, %s", tt.in, city, province)
+		}
+	}
+}
+
+func Test_normalizeProvince(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Beijing", "Beijing"},
+		{"Inner Mongolia", "InnerMongolia"},
+		{"Zhejiang Province", "Zhejiang"},
+		{"shaanxi", "Shaanxi"},
+		{"Xizang", "Tibet"},
+		{"广东 深圳", "Guangdong"},
+		{"内蒙古自治区", "InnerMongolia"},
+		{"Multiple", ""},
+		{"香港", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeProvince(tt.in); got != tt.want {
+			t.Errorf("normalizeProvince(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package wca
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const tsvFilePrefix = "WCA_export_"
+
+// TSVImportProgress 导入进度
+type TSVImportProgress struct {
+	Table      string // 当前表名
+	Rows       int64  // 当前表已导入行数（含断点前已导入的行）
+	Bytes      int64  // 当前表已读取的字节数（解压后）
+	TotalBytes int64  // 当前表总字节数（解压后）
+	Skipped    bool   // 该表在此前已导入完成，本次跳过
+	Done       bool   // 该表导入完成
+}
+
+// TSVImportOptions 导入配置
+type TSVImportOptions struct {
+	BatchSize int                       // 每批写入的行数，默认 batchSize
+	Checksum  string                    // 期望的压缩包 sha256，为空时不校验
+	Progress  func(p TSVImportProgress) // 进度回调，为空时输出到日志
+}
+
+// tsvImportState 每张表的导入状态，用于断点续传
+type tsvImportState struct {
+	Table     string    `gorm:"column:table_name;primaryKey;size:64"`
+	Checksum  string    `gorm:"column:source_checksum;size:64"` // 来源压缩包的 sha256
+	Rows      int64     `gorm:"column:imported_rows"`           // 已导入的行数
+	Done      bool      `gorm:"column:done"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (tsvImportState) TableName() string { return "wca_tsv_import_states" }
+
+// ImportTSVExport 将 WCA 官方 TSV 导出压缩包逐表流式写入 db（MySQL 或 SQLite 均可），返回压缩包的 sha256。
+// 每批数据与导入状态在同一事务中提交，同一个压缩包再次导入时跳过已完成的表，并从中断的行继续；
+// 压缩包变化后对应的表会被重建。
+func ImportTSVExport(db *gorm.DB, zipPath string, opt TSVImportOptions) (string, error) {
+	checksum, err := fileSHA256(zipPath)
+	if err != nil {
+		return "", err
+	}
+	if opt.Checksum != "" && !strings.EqualFold(opt.Checksum, checksum) {
+		return checksum, fmt.Errorf("checksum mismatch for %s: want %s, got %s", zipPath, opt.Checksum, checksum)
+	}
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = batchSize
+	}
+	if opt.Progress == nil {
+		opt.Progress = logTSVProgress()
+	}
+
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return checksum, fmt.Errorf("open zip %s: %w", zipPath, err)
+	}
+	defer zr.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		name := path.Base(f.Name)
+		if !strings.HasPrefix(name, tsvFilePrefix) || !strings.HasSuffix(name, ".tsv") {
+			continue
+		}
+		files[strings.TrimSuffix(strings.TrimPrefix(name, tsvFilePrefix), ".tsv")] = f
+	}
+	if len(files) == 0 {
+		return checksum, fmt.Errorf("no %s*.tsv file found in %s", tsvFilePrefix, zipPath)
+	}
+
+	if err = db.AutoMigrate(&tsvImportState{}); err != nil {
+		return checksum, fmt.Errorf("auto migrate import state: %w", err)
+	}
+
+	for _, tbl := range wcaTables {
+		f, ok := files[tbl.name]
+		if !ok {
+			// 例如 schema_migrations 不在 TSV 导出中
+			continue
+		}
+		if err = importTSVTable(db, f, tbl, checksum, opt); err != nil {
+			return checksum, fmt.Errorf("import table %s: %w", tbl.name, err)
+		}
+	}
+	return checksum, nil
+}
+
+func importTSVTable(db *gorm.DB, f *zip.File, tbl wcaTable, checksum string, opt TSVImportOptions) error {
+	var state tsvImportState
+	if err := db.Where("table_name = ?", tbl.name).Limit(1).Find(&state).Error; err != nil {
+		return fmt.Errorf("load import state: %w", err)
+	}
+	total := int64(f.UncompressedSize64)
+
+	if state.Checksum == checksum && state.Done {
+		opt.Progress(TSVImportProgress{Table: tbl.name, Rows: state.Rows, Bytes: total, TotalBytes: total, Skipped: true})
+		return nil
+	}
+	if state.Checksum != checksum {
+		// 新的压缩包，重建整张表
+		if err := db.Migrator().DropTable(tbl.new()); err != nil {
+			return fmt.Errorf("drop table: %w", err)
+		}
+		state = tsvImportState{Table: tbl.name, Checksum: checksum}
+		if err := db.Save(&state).Error; err != nil {
+			return fmt.Errorf("save import state: %w", err)
+		}
+	}
+	if err := db.AutoMigrate(tbl.new()); err != nil {
+		return fmt.Errorf("auto migrate: %w", err)
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(tbl.new()); err != nil {
+		return fmt.Errorf("parse model: %w", err)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	counter := &countingReader{r: rc}
+
+	// WCA 的 TSV 中包含多行文本时使用双引号包裹，按 csv 规则解析
+	r := csv.NewReader(bufio.NewReaderSize(counter, 1<<20))
+	r.Comma = '\t'
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("read header: %w", err)
+	}
+	// 模型中不存在的列直接忽略
+	fields := make([]*schema.Field, len(header))
+	for i, h := range header {
+		fields[i] = stmt.Schema.LookUpField(strings.TrimSpace(h))
+	}
+
+	elemType := reflect.TypeOf(tbl.new()).Elem()
+	batch := reflect.New(reflect.SliceOf(elemType))
+	var line int64
+
+	flush := func() error {
+		if batch.Elem().Len() == 0 {
+			return nil
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.CreateInBatches(batch.Interface(), opt.BatchSize).Error; err != nil {
+				return err
+			}
+			state.Rows = line
+			return tx.Save(&state).Error
+		})
+		if err != nil {
+			return fmt.Errorf("insert rows up to line %d: %w", line+1, err)
+		}
+		batch.Elem().SetLen(0)
+		opt.Progress(TSVImportProgress{Table: tbl.name, Rows: line, Bytes: counter.n, TotalBytes: total})
+		return nil
+	}
+
+	ctx := context.Background()
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		line++
+		if line <= state.Rows {
+			// 断点之前的行已经导入
+			continue
+		}
+
+		item := reflect.New(elemType)
+		for i, value := range record {
+			if i >= len(fields) || fields[i] == nil || value == "" || value == "NULL" {
+				continue
+			}
+			if err = fields[i].Set(ctx, item, value); err != nil {
+				return fmt.Errorf("line %d column %s: %w", line+1, header[i], err)
+			}
+		}
+		batch.Elem().Set(reflect.Append(batch.Elem(), item.Elem()))
+
+		if batch.Elem().Len() >= opt.BatchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err = flush(); err != nil {
+		return err
+	}
+
+	state.Rows = line
+	state.Done = true
+	if err = db.Save(&state).Error; err != nil {
+		return fmt.Errorf("save import state: %w", err)
+	}
+	opt.Progress(TSVImportProgress{Table: tbl.name, Rows: line, Bytes: total, TotalBytes: total, Done: true})
+	return nil
+}
+
+// logTSVProgress 默认的进度输出，每张表每 10% 输出一次
+func logTSVProgress() func(p TSVImportProgress) {
+	last := -1
+	return func(p TSVImportProgress) {
+		switch {
+		case p.Skipped:
+			log.Printf("[%s] already imported (%d rows), skipping", p.Table, p.Rows)
+		case p.Done:
+			log.Printf("[%s] imported %d rows", p.Table, p.Rows)
+			last = -1
+		case p.TotalBytes > 0:
+			if step := int(p.Bytes * 10 / p.TotalBytes); step != last {
+				last = step
+				log.Printf("[%s] %d rows (%d%%)", p.Table, p.Rows, step*10)
+			}
+		}
+	}
+}
+
+func fileSHA256(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("checksum %s: %w", filePath, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,178 @@
+package wca
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/guojia99/cubing-pro/src/wca/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func writeTSVExport(t *testing.T, files map[string]string) string {
+	zipPath := filepath.Join(t.TempDir(), "WCA_export_v2_test.tsv.zip")
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return zipPath
+}
+
+func openTSVTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "wca.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+var tsvTestFiles = map[string]string{
+	"metadata.json": `{"export_format_version":"2.0.0"}`,
+	"WCA_export_countries.tsv": "id\tname\tcontinent_id\tiso2\n" +
+		"China\tChina\t_Asia\tCN\n" +
+		"USA\tUnited States\t_North America\tUS\n",
+	"WCA_export_results.tsv": "id\tcompetition_id\tevent_id\tround_type_id\tpos\tbest\taverage\tperson_name\tperson_id\tperson_country_id\tformat_id\tregional_single_record\tregional_average_record\n" +
+		"1\tWC2023\t333\tf\t1\t450\t580\tMax Park\t2012PARK03\tUSA\ta\tNULL\tNULL\n" +
+		"2\tWC2023\t333\tf\t2\t520\t600\tYiheng Wang (王艺衡)\t2019WANY36\tChina\ta\tAsR\t\n" +
+		"3\tWC2023\t333\tf\t3\t-1\t650\tTymon Kolasiński\t2015KOLA02\tPoland\ta\t\t\n",
+	"WCA_export_competitions.tsv": "id\tname\tcity_name\tcountry_id\tinformation\tyear\tmonth\tday\tend_year\tend_month\tend_day\tcancelled\n" +
+		"WC2023\tWorld Championship 2023\tIncheon\tKorea\t\"line one\nline\ttwo\"\t2023\t8\t12\t2023\t8\t15\t0\n",
+}
+
+func TestImportTSVExport(t *testing.T) {
+	zipPath := writeTSVExport(t, tsvTestFiles)
+	db := openTSVTestDB(t)
+
+	var skipped []string
+	opt := TSVImportOptions{
+		BatchSize: 2,
+		Progress: func(p TSVImportProgress) {
+			if p.Skipped {
+				skipped = append(skipped, p.Table)
+			}
+		},
+	}
+	checksum, err := ImportTSVExport(db, zipPath, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checksum) != 64 {
+		t.Fatalf("unexpected checksum %q", checksum)
+	}
+
+	var results []types.Result
+	db.Order("id").Find(&results)
+	if len(results) != 3 {
+		t.Fatalf("want 3 results, got %d", len(results))
+	}
+	if results[0].Best != 450 || results[0].RegionalSingleRecord != "" || results[1].RegionalSingleRecord != "AsR" || results[2].Best != -1 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[1].PersonName != "Yiheng Wang (王艺衡)" {
+		t.Fatalf("unexpected person name %q", results[1].PersonName)
+	}
+
+	var comp types.Competition
+	db.First(&comp, "id = ?", "WC2023")
+	if comp.Information != "line one\nline\ttwo" || comp.EndDay != 15 {
+		t.Fatalf("unexpected competition %+v", comp)
+	}
+
+	var countries int64
+	db.Model(&types.Country{}).Count(&countries)
+	if countries != 2 {
+		t.Fatalf("want 2 countries, got %d", countries)
+	}
+
+	// 同一个压缩包再次导入时全部跳过
+	if _, err = ImportTSVExport(db, zipPath, opt); err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 3 {
+		t.Fatalf("want 3 skipped tables, got %v", skipped)
+	}
+}
+
+func TestImportTSVExport_Resume(t *testing.T) {
+	zipPath := writeTSVExport(t, tsvTestFiles)
+	db := openTSVTestDB(t)
+
+	if _, err := ImportTSVExport(db, zipPath, TSVImportOptions{BatchSize: 2, Progress: func(TSVImportProgress) {}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟第一批写入后中断
+	db.Where("id > ?", 2).Delete(&types.Result{})
+	db.Model(&tsvImportState{}).Where("table_name = ?", "results").Updates(map[string]interface{}{"imported_rows": 2, "done": false})
+
+	var rows []int64
+	_, err := ImportTSVExport(db, zipPath, TSVImportOptions{BatchSize: 2, Progress: func(p TSVImportProgress) {
+		if p.Table == "results" {
+			rows = append(rows, p.Rows)
+		}
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int64
+	db.Model(&types.Result{}).Count(&count)
+	if count != 3 {
+		t.Fatalf("want 3 results after resume, got %d", count)
+	}
+	if len(rows) != 2 || rows[0] != 3 || rows[1] != 3 {
+		t.Fatalf("unexpected progress %v", rows)
+	}
+}
+
+func TestImportTSVExport_Checksum(t *testing.T) {
+	zipPath := writeTSVExport(t, tsvTestFiles)
+	db := openTSVTestDB(t)
+
+	checksum, err := fileSHA256(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ImportTSVExport(db, zipPath, TSVImportOptions{Checksum: "deadbeef"}); err == nil {
+		t.Fatal("want checksum mismatch error")
+	}
+	if db.Migrator().HasTable(&types.Result{}) {
+		t.Fatal("nothing should be imported on checksum mismatch")
+	}
+	if _, err = ImportTSVExport(db, zipPath, TSVImportOptions{Checksum: checksum, Progress: func(TSVImportProgress) {}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 压缩包变化后重新导入对应的表
+	files := map[string]string{
+		"WCA_export_results.tsv": "id\tcompetition_id\tevent_id\tround_type_id\tpos\tbest\taverage\tperson_id\tformat_id\n" +
+			"9\tWC2025\t222\tf\t1\t100\t150\t2016XUZI01\ta\n",
+	}
+	if _, err = ImportTSVExport(db, writeTSVExport(t, files), TSVImportOptions{Progress: func(TSVImportProgress) {}}); err != nil {
+		t.Fatal(err)
+	}
+	var results []types.Result
+	db.Find(&results)
+	if len(results) != 1 || results[0].ID != 9 {
+		t.Fatalf("unexpected results after new export %+v", results)
+	}
+}
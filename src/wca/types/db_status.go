@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+// WcaDBStatus WCA 数据库快照与同步状态
+type WcaDBStatus struct {
+	Driver       string     `json:"driver"`
+	CurrentDB    string     `json:"currentDb"`    // 当前使用的快照，如 wca_20251225
+	SnapshotDate string     `json:"snapshotDate"` // 快照对应的导出日期，如 2025-12-25
+	PreviousDB   string     `json:"previousDb"`   // 上一个快照，新快照校验失败时继续使用
+	SwitchedAt   *time.Time `json:"switchedAt"`
+
+	Syncing        bool             `json:"syncing"`
+	LastSyncAt     *time.Time       `json:"lastSyncAt"`
+	LastSyncError  string           `json:"lastSyncError"`
+	LastValidation *WcaDBValidation `json:"lastValidation"`
+}
+
+// WcaDBValidation 新快照切换前的校验结果
+type WcaDBValidation struct {
+	DB        string           `json:"db"`
+	CheckedAt time.Time        `json:"checkedAt"`
+	Passed    bool             `json:"passed"`
+	RowCounts map[string]int64 `json:"rowCounts"`
+	Errors    []string         `json:"errors"`
+}
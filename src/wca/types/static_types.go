@@ -137,3 +137,91 @@ type DiyEventRanksEventIndex struct {
 }
 
 func (DiyEventRanksEventIndex) TableName() string { return "diy_event_ranks_event_index" }
+
+// 纪录范围
+const (
+	RecordScopeWorld     = "WR"
+	RecordScopeContinent = "CR"
+	RecordScopeNational  = "NR"
+	RecordScopeProvince  = "PR" // 省纪录，仅中国选手，按选手所属省份计算
+)
+
+// StaticRecordHistory 纪录历史，WR/CR/NR 来自成绩上的纪录标记，PR 按选手省份逐场计算
+type StaticRecordHistory struct {
+	EventID string `gorm:"type:varchar(10)" json:"eventId"`
+	IsAvg   bool   `gorm:"type:bool" json:"isAvg"`
+	Scope   string `gorm:"type:varchar(4)" json:"scope"`   // WR / CR / NR / PR
+	Region  string `gorm:"type:varchar(64)" json:"region"` // WR: World, CR: 大洲 ID, NR: 国家 ID, PR: 省份
+	Seq     int    `gorm:"type:int" json:"seq"`            // 在该项目该地区纪录中的序号，从 1 开始
+	Record  string `gorm:"type:varchar(8)" json:"record"`  // 成绩上的纪录标记（如 WR, AsR, NR），PR 为空
+
+	Value    int    `gorm:"type:int" json:"value"`
+	ValueStr string `gorm:"-" json:"valueStr"`
+
+	WcaID   string `gorm:"type:varchar(10)" json:"wcaId"`
+	WcaName string `gorm:"type:varchar(255)" json:"wcaName"`
+	Country string `gorm:"type:varchar(255)" json:"country"`
+
+	CompetitionID   string `gorm:"type:varchar(64)" json:"competitionId"`
+	CompetitionName string `gorm:"type:varchar(255)" json:"competitionName"`
+	RoundTypeID     string `gorm:"type:varchar(4)" json:"roundTypeId"`
+
+	Date      string `gorm:"column:record_date;type:varchar(10)" json:"date"` // 比赛开始日期
+	EndDate   string `gorm:"type:varchar(10)" json:"endDate"`                 // 被打破的日期，当前纪录为空
+	Days      int    `gorm:"type:int" json:"days"`                            // 保持天数，当前纪录在查询时计算到当天
+	IsCurrent bool   `gorm:"type:bool" json:"isCurrent"`
+}
+
+// CurrentRecords 某个国家的现有纪录，Provinces 为各省现有纪录（仅中国）
+type CurrentRecords struct {
+	Country   string                           `json:"country"`
+	National  []StaticRecordHistory            `json:"national"`
+	Provinces map[string][]StaticRecordHistory `json:"provinces"`
+}
+
+// 选手省份来源
+const (
+	ProvinceSourceCubing      = "cubing"      // 粗饼选手主页的地区
+	ProvinceSourceCompetition = "competition" // 参赛次数最多的省份
+)
+
+// StaticPersonProvince 中国选手所属省份
+type StaticPersonProvince struct {
+	WcaID     string `gorm:"column:wca_id;type:varchar(10);primaryKey" json:"wcaId"`
+	Province  string `gorm:"type:varchar(32)" json:"province"`
+	Source    string `gorm:"type:varchar(16)" json:"source"`
+	CompCount int    `gorm:"type:int" json:"compCount"` // 在该省的参赛次数
+}
+
+// ProvinceRank 省内排名
+type ProvinceRank struct {
+	Rank        int    `json:"rank"`
+	WcaID       string `json:"wcaId"`
+	Name        string `json:"name"`
+	Province    string `json:"province"`
+	EventID     string `json:"eventId"`
+	Best        int    `json:"best"`
+	BestStr     string `json:"bestStr"`
+	CountryRank int    `json:"countryRank"`
+	WorldRank   int    `json:"worldRank"`
+}
+
+// ProvinceKinch 省内 Kinch 分，各项目与省内最佳成绩对比
+type ProvinceKinch struct {
+	Rank     int                `json:"rank"`
+	WcaID    string             `json:"wcaId"`
+	Name     string             `json:"name"`
+	Province string             `json:"province"`
+	Score    float64            `json:"score"`
+	Events   map[string]float64 `json:"events"`
+}
+
+// ProvinceSor 省内排名总和，没有成绩的项目计为该项目省内人数 + 1
+type ProvinceSor struct {
+	Rank     int            `json:"rank"`
+	WcaID    string         `json:"wcaId"`
+	Name     string         `json:"name"`
+	Province string         `json:"province"`
+	Sum      int            `json:"sum"`
+	Events   map[string]int `json:"events"`
+}
@@ -84,3 +84,151 @@ type RankWithEventsGrouptatic struct {
 	Rank  int
 	Count int
 }
+
+type (
+	// CompetitionRound 比赛中某个项目的一轮，Results 按名次排序
+	CompetitionRound struct {
+		EventID     string   `json:"eventId"`
+		RoundTypeID string   `json:"roundTypeId"`
+		RoundName   string   `json:"roundName"`
+		Final       bool     `json:"final"`
+		FormatID    string   `json:"formatId"`
+		Results     []Result `json:"results"`
+	}
+
+	// CompetitionPodium 项目决赛前三名，无有效成绩的选手不计入
+	CompetitionPodium struct {
+		EventID string   `json:"eventId"`
+		Results []Result `json:"results"`
+	}
+
+	// CompetitionRecord 比赛中打破的纪录
+	CompetitionRecord struct {
+		EventID     string `json:"eventId"`
+		RoundTypeID string `json:"roundTypeId"`
+		WcaID       string `json:"wcaId"`
+		Name        string `json:"name"`
+		CountryID   string `json:"countryId"`
+		IsAvg       bool   `json:"isAvg"`
+		Record      string `json:"record"` // WR / NR / AsR 等
+		Result      int    `json:"result"`
+		ResultStr   string `json:"resultStr"`
+	}
+
+	CompetitionResults struct {
+		Competition Competition         `json:"competition"`
+		Rounds      []CompetitionRound  `json:"rounds"`
+		Podiums     []CompetitionPodium `json:"podiums"`
+		Records     []CompetitionRecord `json:"records"`
+	}
+
+	// RankTargetPerson 达到目标排名时会超过的选手
+	RankTargetPerson struct {
+		Rank      int    `json:"rank"`
+		WcaID     string `json:"wcaId"`
+		Name      string `json:"name"`
+		CountryID string `json:"countryId"`
+		Best      int    `json:"best"`
+		BestStr   string `json:"bestStr"`
+	}
+
+	// RankTargetCounting 按最近一次有效平均的计数成绩等比例换算，达到目标平均时最快与最慢的计数成绩
+	RankTargetCounting struct {
+		CompetitionID    string  `json:"competitionId"`
+		CompetitionName  string  `json:"competitionName"`
+		RoundTypeID      string  `json:"roundTypeId"`
+		Attempts         []int64 `json:"attempts"`
+		Average          int     `json:"average"`
+		AverageStr       string  `json:"averageStr"`
+		BestCounting     int     `json:"bestCounting"`
+		BestCountingStr  string  `json:"bestCountingStr"`
+		WorstCounting    int     `json:"worstCounting"`
+		WorstCountingStr string  `json:"worstCountingStr"`
+	}
+
+	// RankTarget 选手某项目达到目标排名（世界 / 洲 / 国家）需要的成绩
+	RankTarget struct {
+		WcaID      string `json:"wcaId"`
+		Name       string `json:"name"`
+		EventID    string `json:"eventId"`
+		IsAvg      bool   `json:"isAvg"`
+		Scope      string `json:"scope"`  // world / continent / country
+		Region     string `json:"region"` // 大洲 ID 或国家 ID
+		TargetRank int    `json:"targetRank"`
+
+		Current     int    `json:"current"` // 当前最好成绩，0 为暂无成绩
+		CurrentStr  string `json:"currentStr"`
+		CurrentRank int    `json:"currentRank"`
+		Reached     bool   `json:"reached"` // 已经达到目标排名
+
+		Required     int               `json:"required"` // 需要达到或优于的成绩，0 表示任意有效成绩即可
+		RequiredStr  string            `json:"requiredStr"`
+		TargetPerson *RankTargetPerson `json:"targetPerson,omitempty"` // 目前处于目标排名的选手
+
+		Passed      []RankTargetPerson  `json:"passed"`      // 会超过的选手（按成绩排序，最多 rankTargetMaxPassed 人）
+		PassedCount int64               `json:"passedCount"` // 会超过的选手总数
+		Counting    *RankTargetCounting `json:"counting,omitempty"`
+	}
+
+	// PersonCompareResult 两位选手某项目单次或平均的对比，下标 0 / 1 对应两位选手，成绩为 0 表示没有成绩
+	PersonCompareResult struct {
+		Values         [2]int    `json:"values"`
+		ValueStrs      [2]string `json:"valueStrs"`
+		WorldRanks     [2]int    `json:"worldRanks"`
+		ContinentRanks [2]int    `json:"continentRanks"`
+		CountryRanks   [2]int    `json:"countryRanks"`
+		Winner         int       `json:"winner"` // 1 / 2 为胜出的一方，0 为平手
+		Diff           int       `json:"diff"`   // 双方都有成绩时的差距，多盲不计算
+		DiffStr        string    `json:"diffStr"`
+	}
+
+	PersonCompareEvent struct {
+		EventID string               `json:"eventId"`
+		Single  *PersonCompareResult `json:"single,omitempty"`
+		Average *PersonCompareResult `json:"average,omitempty"`
+	}
+
+	// PersonCompareRound 同一轮次的名次对比
+	PersonCompareRound struct {
+		EventID     string    `json:"eventId"`
+		RoundTypeID string    `json:"roundTypeId"`
+		RoundName   string    `json:"roundName"`
+		Results     [2]Result `json:"results"`
+		Winner      int       `json:"winner"` // 1 / 2 为名次靠前的一方，0 为并列
+	}
+
+	// PersonCompareCompetition 两位选手共同参加的比赛
+	PersonCompareCompetition struct {
+		CompetitionID   string               `json:"competitionId"`
+		CompetitionName string               `json:"competitionName"`
+		Date            string               `json:"date"`
+		Rounds          []PersonCompareRound `json:"rounds"`
+		Wins            [2]int               `json:"wins"`
+	}
+
+	// PersonCompare 两位选手的个人最佳、排名与同场对决对比
+	PersonCompare struct {
+		Persons      [2]Person                  `json:"persons"`
+		Events       []PersonCompareEvent       `json:"events"`
+		Score        [2]int                     `json:"score"`        // 全部项目的 PB 对比得分，一方没有成绩时另一方得分，平手双方各得一分
+		SharedScore  [2]int                     `json:"sharedScore"`  // 只计算双方都有成绩的项目
+		Competitions []PersonCompareCompetition `json:"competitions"` // 最近的比赛在前
+		HeadToHead   [2]int                     `json:"headToHead"`   // 同场轮次名次胜出次数
+	}
+)
+
+// 数据导出格式
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatParquet = "parquet"
+)
+
+// ExportOptions 导出某个数据集，Columns 为空时导出全部列，过滤条件为空 / 0 表示不过滤
+type ExportOptions struct {
+	Dataset string   `json:"dataset"`
+	Format  string   `json:"format"` // csv / parquet
+	Columns []string `json:"columns"`
+	Country string   `json:"country"` // 国家 ID、名称或 ISO2
+	EventID string   `json:"eventId"`
+	Year    int      `json:"year"`
+}
@@ -0,0 +1,81 @@
+package wca
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/guojia99/cubing-pro/src/wca/types"
+	"gorm.io/gorm"
+)
+
+// validateTables 新快照中不能为空的表
+var validateTables = []string{
+	"competitions", "countries", "events", "persons",
+	"results", "result_attempts", "ranks_single", "ranks_average",
+}
+
+// minRowRatio 新快照各表行数不得低于上一快照的比例，用于发现导入不完整
+const minRowRatio = 0.95
+
+// validateWcaDB 切换快照前校验新库：关键表行数、抽样查询与索引，prev 为当前使用的库，可为空
+func validateWcaDB(db *gorm.DB, driver, name string, prev *gorm.DB) types.WcaDBValidation {
+	v := types.WcaDBValidation{
+		DB:        name,
+		CheckedAt: time.Now(),
+		RowCounts: make(map[string]int64),
+	}
+	fail := func(format string, args ...interface{}) {
+		v.Errors = append(v.Errors, fmt.Sprintf(format, args...))
+	}
+
+	// 1. 行数
+	for _, table := range validateTables {
+		var count int64
+		if err := db.Table(table).Count(&count).Error; err != nil {
+			fail("count %s: %v", table, err)
+			continue
+		}
+		v.RowCounts[table] = count
+		if count == 0 {
+			fail("table %s is empty", table)
+			continue
+		}
+		if prev == nil {
+			continue
+		}
+		var prevCount int64
+		if err := prev.Table(table).Count(&prevCount).Error; err == nil && float64(count) < float64(prevCount)*minRowRatio {
+			fail("table %s has %d rows, previous snapshot has %d", table, count, prevCount)
+		}
+	}
+
+	// 2. 抽样查询：333 单次第一名的选手与成绩
+	var rank types.RanksSingle
+	if err := db.Where("event_id = ?", "333").Order("world_rank").First(&rank).Error; err != nil {
+		fail("sample ranks_single: %v", err)
+	} else {
+		var person types.Person
+		if err = db.Where("wca_id = ?", rank.PersonID).First(&person).Error; err != nil {
+			fail("sample person %s: %v", rank.PersonID, err)
+		}
+		var results int64
+		db.Model(&types.Result{}).Where("person_id = ? AND event_id = ? AND best = ?", rank.PersonID, "333", rank.Best).Count(&results)
+		if results == 0 {
+			fail("sample result of %s with best %d not found", rank.PersonID, rank.Best)
+		}
+	}
+
+	// 3. 索引
+	defs, err := parseIndexStatements(syncWcaDbIndex)
+	if err != nil {
+		fail("parse indexes: %v", err)
+	}
+	for _, d := range defs {
+		if !db.Migrator().HasIndex(d.table, d.nameFor(driver)) {
+			fail("missing index %s on %s", d.nameFor(driver), d.table)
+		}
+	}
+
+	v.Passed = len(v.Errors) == 0
+	return v
+}
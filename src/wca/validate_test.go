@@ -0,0 +1,84 @@
+package wca
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_validateWcaDB(t *testing.T) {
+	w, s := newFixtureWCA(t)
+
+	v := validateWcaDB(w.db(), wcaDriverSqlite, fixtureDbName, nil)
+	if !v.Passed || v.RowCounts["persons"] != 4 {
+		t.Fatalf("fixture should pass validation %+v", v)
+	}
+
+	// 与自身对比行数不变
+	if v = validateWcaDB(w.db(), wcaDriverSqlite, fixtureDbName, w.db()); !v.Passed {
+		t.Fatalf("same snapshot should pass validation %+v", v)
+	}
+
+	// 新库行数明显少于上一快照
+	prev, err := openWcaDB(wcaDriverSqlite, "", s.DbPath, "wca_20241201")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { closeDB(prev) })
+	if err = prev.Exec("ATTACH DATABASE ? AS cur", sqliteDbFile(s.DbPath, fixtureDbName)).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range validateTables {
+		if err = prev.Exec("CREATE TABLE " + table + " AS SELECT * FROM cur." + table).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	prev.Exec("INSERT INTO results SELECT * FROM cur.results")
+	v = validateWcaDB(w.db(), wcaDriverSqlite, fixtureDbName, prev)
+	if v.Passed || !strings.Contains(strings.Join(v.Errors, ";"), "table results has 10 rows, previous snapshot has 20") {
+		t.Fatalf("want row count error %+v", v)
+	}
+
+	// 缺少索引
+	w.db().Exec("DROP INDEX persons_idx_wca_id")
+	v = validateWcaDB(w.db(), wcaDriverSqlite, fixtureDbName, nil)
+	if v.Passed || len(v.Errors) != 1 || v.Errors[0] != "missing index persons_idx_wca_id on persons" {
+		t.Fatalf("want missing index error %+v", v)
+	}
+
+	// 空表
+	w.db().Exec("DELETE FROM result_attempts")
+	v = validateWcaDB(w.db(), wcaDriverSqlite, fixtureDbName, nil)
+	if v.Passed || v.Errors[0] != "table result_attempts is empty" {
+		t.Fatalf("want empty table error %+v", v)
+	}
+}
+
+func Test_wca_switchDB(t *testing.T) {
+	w, s := newFixtureWCA(t)
+
+	st := w.Status()
+	if st.Driver != wcaDriverSqlite || st.CurrentDB != fixtureDbName || st.SnapshotDate != "2025-01-01" || st.PreviousDB != "" || st.SwitchedAt == nil {
+		t.Fatalf("unexpected status %+v", st)
+	}
+
+	// 切换前的查询结果已缓存，切换后缓存清空并查询新库
+	if got := len(w.CountryList()); got != 4 {
+		t.Fatalf("want 4 countries, got %d", got)
+	}
+	next, err := openWcaDB(wcaDriverSqlite, "", s.DbPath, "wca_20250201")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { closeDB(next) })
+	next.Exec("ATTACH DATABASE ? AS cur", sqliteDbFile(s.DbPath, fixtureDbName))
+	next.Exec("CREATE TABLE countries AS SELECT * FROM cur.countries WHERE iso2 = 'CN'")
+
+	w.switchDB(next, "wca_20250201")
+	if got := len(w.CountryList()); got != 1 {
+		t.Fatalf("want 1 country after switch, got %d", got)
+	}
+	st = w.Status()
+	if st.CurrentDB != "wca_20250201" || st.SnapshotDate != "2025-02-01" || st.PreviousDB != fixtureDbName {
+		t.Fatalf("unexpected status after switch %+v", st)
+	}
+}
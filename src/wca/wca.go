@@ -1,19 +1,25 @@
 package wca
 
 import (
+	"image"
+	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/guojia99/cubing-pro/src/configs"
 	"github.com/guojia99/cubing-pro/src/robot/qq_bot/Better-Bot-Go/log"
 	"github.com/guojia99/cubing-pro/src/wca/types"
 	"github.com/patrickmn/go-cache"
-	"gorm.io/gorm"
 )
 
 type WCA interface {
 	// ExportToSqlite 导出
 	ExportToSqlite(sqlitePath string) error // 耗时较长，需要较多内存来存放数据，仅实验使用
 	ExportToTable(filePath string) error
+	// Export 按数据集导出 CSV / Parquet，支持列选择与国家、项目、年份过滤，返回导出行数
+	Export(out io.Writer, opt types.ExportOptions) (int64, error)
+	ExportDatasets() []string
 
 	// wca查询类
 	SearchPlayers(name string) []types.Person
@@ -22,11 +28,16 @@ type WCA interface {
 	GetPersonInfo(wcaId string) (types.PersonInfo, error)
 	GetPersonResult(wcaId string) ([]types.Result, error)
 	GetCompetition(compId string) (types.Competition, error)
+	// GetCompetitionResults 比赛各轮次成绩、领奖台与纪录
+	GetCompetitionResults(compId string) (types.CompetitionResults, error)
 	GetPersonCompetition(wcaId string) ([]types.Competition, error)
 
 	// 大满贯列表
 	GetGrandSlam() []types.AllEventChampionshipsPodium
 
+	// Status 当前数据库快照与同步状态
+	Status() types.WcaDBStatus
+
 	// 统计
 	GetPersonRankTimer(wcaId string) ([]types.StaticWithTimerRank, error)
 	GetEventRankWithTimer(eventId, country string, year int, isAvg bool, page, size int) ([]types.StaticWithTimerRank, int64, error)
@@ -49,13 +60,42 @@ type WCA interface {
 
 	// GetCountryBestWithEventGroupRank 获取选手最佳项目排列
 	GetCountryBestWithEventGroupRank(wcaId string, avg bool, useWorld bool) (out []types.RankWithEventsGrouptatic, err error)
+
+	// GetRecordHistory 纪录历史，eventId / country(保持者国家) / scope(WR CR NR PR) / region 为空时不筛选
+	GetRecordHistory(eventId, country, scope, region string) ([]types.StaticRecordHistory, error)
+	// GetCurrentRecords 国家现有纪录，中国同时给出各省现有纪录
+	GetCurrentRecords(country string) (types.CurrentRecords, error)
+
+	// GetProvinceRank 中国选手省内排名，province 为空时为全部中国选手
+	GetProvinceRank(eventId, province string, isAvg bool, page, size int) ([]types.ProvinceRank, int64, error)
+	// GetProvinceRecords 省现有纪录，province 为空时为全部省份
+	GetProvinceRecords(province string) ([]types.StaticRecordHistory, error)
+	// GetProvinceKinch 省内 Kinch 排名
+	GetProvinceKinch(province string, page, size int) ([]types.ProvinceKinch, int64, error)
+	// GetProvinceSor 省内排名总和
+	GetProvinceSor(province string, isAvg bool, page, size int) ([]types.ProvinceSor, int64, error)
+	// SetPersonProvince 使用粗饼选手主页的地区覆盖由比赛城市推断的省份
+	SetPersonProvince(wcaId, region string) error
+
+	// GetRankTarget 选手某项目达到目标排名需要的成绩，scope 为 world / continent / country
+	GetRankTarget(wcaId, eventId, scope string, rank int, isAvg bool) (types.RankTarget, error)
+
+	// ComparePersons 两位选手的 PB、排名与同场对决对比
+	ComparePersons(wcaIdA, wcaIdB string) (types.PersonCompare, error)
+	// GetPersonCompareImage 两位选手对比的总结图
+	GetPersonCompareImage(wcaIdA, wcaIdB string) (image.Image, error)
 }
 
 type wca struct {
-	db     *gorm.DB
-	dbName string
+	snapshot atomic.Pointer[dbSnapshot] // 当前使用的数据库，通过 db() 读取
+	driver   string                     // mysql, sqlite
+
+	statusMu sync.Mutex
+	status   types.WcaDBStatus
 
 	syncMutex sync.Mutex
+
+	provinceMu sync.Mutex // 保护粗饼地区覆盖文件
 	// 目录结构
 	// ---->
 	//    /wca
@@ -74,14 +114,25 @@ func NewWCA(
 	syncPath string,
 	enableSync bool,
 ) WCA {
+	return NewWCAWithConfig(configs.WcaDB{
+		Driver:   wcaDriverMySQL,
+		MysqlUrl: mysqlUrl,
+		DbPath:   dbPath,
+		SyncPath: syncPath,
+	}, enableSync)
+}
+
+// NewWCAWithConfig 根据 WcaDB 配置选择 MySQL 或 SQLite
+func NewWCAWithConfig(cfg configs.WcaDB, enableSync bool) WCA {
 	w := &wca{
-		dbPath:   dbPath,
-		syncPath: syncPath,
-		dbURL:    mysqlUrl,
+		driver:   wcaDriver(cfg.Driver),
+		dbPath:   cfg.DbPath,
+		syncPath: cfg.SyncPath,
+		dbURL:    cfg.MysqlUrl,
 		cache:    cache.New(5*time.Minute, 10*time.Minute),
 	}
 	w.updateDb()
-	if w.db == nil {
+	if w.db() == nil {
 		log.Errorf("sync wca db is failed")
 	}
 
@@ -42,7 +42,7 @@ func Test_FMC_Length(t *testing.T) {
 	ww := w.(*wca)
 
 	var sc []types.Scramble
-	ww.db.Where("event_id = ?", "333fm").Find(&sc)
+	ww.db().Where("event_id = ?", "333fm").Find(&sc)
 	var out []string
 	var numMap = make(map[int]int)
 